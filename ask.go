@@ -0,0 +1,26 @@
+package openaiclient
+
+import "context"
+
+// Ask answers a single prompt with the default model and returns the text of
+// the answer.
+func (o *OpenAI) Ask(ctx context.Context, prompt string) (string, error) {
+	return o.ask(ctx, []Message{{Role: MessageRoleUser, Content: prompt}})
+}
+
+// AskSystem answers a single prompt under a system prompt with the default
+// model and returns the text of the answer.
+func (o *OpenAI) AskSystem(ctx context.Context, system, prompt string) (string, error) {
+	return o.ask(ctx, []Message{
+		{Role: MessageRoleSystem, Content: system},
+		{Role: MessageRoleUser, Content: prompt},
+	})
+}
+
+func (o *OpenAI) ask(ctx context.Context, messages []Message) (string, error) {
+	message, err := o.GetCompletionContext(ctx, &CompletionRequestPayload{Messages: messages})
+	if err != nil {
+		return "", err
+	}
+	return message.Content, nil
+}
@@ -0,0 +1,43 @@
+package openaiclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestAsk(t *testing.T) {
+	client := NewTestClient(ScriptedTurn{Content: "Paris"}, ScriptedTurn{Content: "Lisbon"})
+	client.DefaultModel = "gpt-4o-mini"
+
+	answer, err := client.Ask(context.Background(), "Capital of France?")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if answer != "Paris" {
+		t.Errorf("expected the answer text, got %q", answer)
+	}
+
+	answer, err = client.AskSystem(context.Background(), "Answer with a city.", "Capital of Portugal?")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if answer != "Lisbon" {
+		t.Errorf("expected the answer text, got %q", answer)
+	}
+
+	sent := client.Completions()
+	if sent[0].Model != "gpt-4o-mini" || len(sent[0].Messages) != 1 {
+		t.Errorf("expected a single user message to the default model, got %+v", sent[0])
+	}
+	if messages := sent[1].Messages; len(messages) != 2 || messages[0].Role != MessageRoleSystem || messages[0].Content != "Answer with a city." {
+		t.Errorf("expected the system prompt first, got %+v", messages)
+	}
+}
+
+func TestAsk_Error(t *testing.T) {
+	client := NewTestClient(ScriptedTurn{StatusCode: http.StatusBadRequest, Content: "bad request"})
+	if answer, err := client.Ask(context.Background(), "Hi"); err == nil || answer != "" {
+		t.Errorf("expected an error and no answer, got %q and %v", answer, err)
+	}
+}
@@ -0,0 +1,225 @@
+package openaiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// auditedFields are the JSON fields holding contents, which the client's
+// LogRedaction is applied to in audit records.
+var auditedFields = map[string]bool{
+	"content":           true,
+	"text":              true,
+	"input":             true,
+	"arguments":         true,
+	"reasoning_content": true,
+}
+
+type (
+	// AuditRecord is the record of a request to the API and its outcome.
+	// A request and its retries make a single record, which is not modified
+	// once handed to the sink.
+	AuditRecord struct {
+		Time          time.Time `json:"time"`
+		CorrelationId string    `json:"correlation_id,omitempty"`
+		Tenant        string    `json:"tenant,omitempty"`
+		Method        string    `json:"method"`
+		Endpoint      string    `json:"endpoint"`
+		Model         string    `json:"model,omitempty"`
+		// Request and Response are the JSON bodies, with the contents
+		// redacted by the client's LogRedaction. Bodies that are not JSON,
+		// such as file uploads, are left out.
+		Request    json.RawMessage `json:"request,omitempty"`
+		Response   json.RawMessage `json:"response,omitempty"`
+		StatusCode int             `json:"status_code,omitempty"`
+		Error      string          `json:"error,omitempty"`
+		// Duration is the time taken by the request and its retries.
+		Duration time.Duration `json:"duration"`
+	}
+
+	// AuditSink receives the audit records of a client, see OpenAI.Audit. It
+	// is called synchronously, so requests return once their record is
+	// written, and must be safe for concurrent use.
+	AuditSink interface {
+		WriteAudit(ctx context.Context, record AuditRecord) error
+	}
+
+	// FileAuditSink writes audit records to an io.Writer, such as an
+	// append-only file, as JSON lines. Writers with a Sync method, like
+	// *os.File, are synced after every record.
+	FileAuditSink struct {
+		mu      sync.Mutex
+		writer  io.Writer
+		encoder *json.Encoder
+	}
+
+	// HTTPAuditSink posts every audit record as JSON to URL, such as the
+	// collector of a SIEM.
+	HTTPAuditSink struct {
+		URL string
+		// Header is added to every request, e.g. for authentication.
+		Header http.Header
+		// Client sends the records. When nil, http.DefaultClient is used.
+		Client *http.Client
+	}
+)
+
+// NewFileAuditSink returns a sink writing to w.
+func NewFileAuditSink(w io.Writer) *FileAuditSink {
+	return &FileAuditSink{writer: w, encoder: json.NewEncoder(w)}
+}
+
+func (s *FileAuditSink) WriteAudit(ctx context.Context, record AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.encoder.Encode(record); err != nil {
+		return fmt.Errorf("error writing audit record: %w", err)
+	}
+	if syncer, ok := s.writer.(interface{ Sync() error }); ok {
+		if err := syncer.Sync(); err != nil {
+			return fmt.Errorf("error syncing audit record: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *HTTPAuditSink) WriteAudit(ctx context.Context, record AuditRecord) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error marshaling audit record: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating audit request: %w", err)
+	}
+	for name, values := range s.Header {
+		request.Header[name] = values
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("error sending audit record: %w", err)
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("error sending audit record: status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// auditRequest sends the request like sendRequest and writes its record to
+// the client's Audit sink. Failures of the sink are reported as warnings
+// rather than failing the request.
+func (o *OpenAI) auditRequest(request *http.Request, safe bool) ([]byte, error) {
+	var requestBody []byte
+	if request.GetBody != nil {
+		if body, err := request.GetBody(); err == nil {
+			requestBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	ctx := request.Context()
+	start := time.Now()
+	responseText, err := o.sendRequest(request, safe)
+
+	record := AuditRecord{
+		Time:          start,
+		CorrelationId: CorrelationId(ctx),
+		Tenant:        Tenant(ctx),
+		Method:        request.Method,
+		Endpoint:      request.URL.Path,
+		Model:         requestModel(ctx),
+		Request:       o.redactJSON(requestBody),
+		Response:      o.redactJSON(responseText),
+		StatusCode:    http.StatusOK,
+		Duration:      time.Since(start),
+	}
+	if err != nil {
+		record.StatusCode = 0
+		record.Error = err.Error()
+		var apiErr *OpenAIError
+		if errors.As(err, &apiErr) {
+			record.StatusCode = apiErr.StatusCode
+		}
+	}
+
+	if auditErr := o.Audit.WriteAudit(context.WithoutCancel(ctx), record); auditErr != nil {
+		o.warn(ctx, WarningAuditFailed, "error writing audit record", slog.Any("error", auditErr))
+	}
+	return responseText, err
+}
+
+// redactJSON returns a copy of the JSON body with the client's LogRedaction
+// applied to its contents, or nil when the body is not JSON.
+func (o *OpenAI) redactJSON(body []byte) json.RawMessage {
+	if len(body) == 0 || !json.Valid(body) {
+		return nil
+	}
+	if o.LogRedaction == nil {
+		return bytes.Clone(body)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+	redacted, err := json.Marshal(redactValue(value, o.LogRedaction, false))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+// redactValue redacts the strings of the audited fields of value, including
+// the strings of their arrays, such as batch inputs.
+func redactValue(value any, redact RedactionPolicy, audited bool) any {
+	switch value := value.(type) {
+	case string:
+		if audited {
+			return slogValueJSON(redact(value))
+		}
+	case map[string]any:
+		for key, field := range value {
+			value[key] = redactValue(field, redact, auditedFields[key])
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = redactValue(item, redact, audited)
+		}
+	}
+	return value
+}
+
+// slogValueJSON converts the value of a RedactionPolicy to JSON, with groups
+// as objects.
+func slogValueJSON(value slog.Value) any {
+	switch value.Kind() {
+	case slog.KindString:
+		return value.String()
+	case slog.KindGroup:
+		object := map[string]any{}
+		for _, attr := range value.Group() {
+			object[attr.Key] = slogValueJSON(attr.Value)
+		}
+		return object
+	default:
+		return value.Any()
+	}
+}
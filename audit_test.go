@@ -0,0 +1,143 @@
+package openaiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAudit_FileSink(t *testing.T) {
+	client := createClient(t)
+	client.client = &SequentialFakeClient{
+		Responses: []*http.Response{
+			fakeResponse(http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":"Hello"}}]}`),
+			fakeResponse(http.StatusBadRequest, `{"error":{"type":"invalid_request_error","message":"bad"}}`),
+		},
+	}
+	client.LogRedaction = TruncateLoggedContent(2)
+	var buf bytes.Buffer
+	client.Audit = NewFileAuditSink(&buf)
+
+	ctx := WithCorrelationId(context.Background(), "run-1")
+	payload := &CompletionRequestPayload{
+		Model:    "gpt-4o-mini",
+		Messages: []Message{{Role: MessageRoleUser, Content: "Secret"}},
+		Stop:     []string{"END"},
+	}
+	if _, err := client.GetCompletionContext(ctx, payload); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.GetCompletionContext(ctx, payload); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(lines))
+	}
+	var record AuditRecord
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if record.CorrelationId != "run-1" || record.Model != "gpt-4o-mini" || record.StatusCode != http.StatusOK {
+		t.Errorf("unexpected record %+v", record)
+	}
+	if request := string(record.Request); !strings.Contains(request, `"content":"Se…"`) || !strings.Contains(request, `"stop":["END"]`) {
+		t.Errorf("expected the redacted request, got %s", request)
+	}
+	if response := string(record.Response); !strings.Contains(response, `"content":"He…"`) {
+		t.Errorf("expected the redacted response, got %s", response)
+	}
+
+	var failed AuditRecord
+	if err := json.Unmarshal([]byte(lines[1]), &failed); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if failed.StatusCode != http.StatusBadRequest || failed.Error == "" || failed.Response != nil {
+		t.Errorf("expected the error to be recorded, got %+v", failed)
+	}
+}
+
+func TestAudit_HTTPSink(t *testing.T) {
+	var received []AuditRecord
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer audit" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var record AuditRecord
+		json.Unmarshal(body, &record)
+		received = append(received, record)
+	}))
+	defer collector.Close()
+
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return fakeResponse(http.StatusOK, `{"data":[{"index":0,"embedding":[0.1]},{"index":1,"embedding":[0.2]}]}`), nil
+		},
+	}
+	client.LogRedaction = LogContentHash
+	client.Audit = &HTTPAuditSink{URL: collector.URL, Header: http.Header{"Authorization": {"Bearer audit"}}}
+
+	if _, err := client.GetEmbeddings(context.Background(), GetEmbeddingPayload{Model: "text-embedding-3-small", Inputs: []string{"a", "b"}}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(received) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(received))
+	}
+	var request struct {
+		Input []struct {
+			Length int    `json:"length"`
+			Sha256 string `json:"sha256"`
+		} `json:"input"`
+	}
+	if err := json.Unmarshal(received[0].Request, &request); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(request.Input) != 2 || request.Input[0].Length != 1 || request.Input[0].Sha256 == "" {
+		t.Errorf("expected hashed inputs, got %s", received[0].Request)
+	}
+
+	var warnings []Warning
+	client.OnWarning = func(warning Warning) {
+		warnings = append(warnings, warning)
+	}
+	client.Audit = &HTTPAuditSink{URL: collector.URL}
+	if _, err := client.GetEmbeddings(context.Background(), GetEmbeddingPayload{Model: "text-embedding-3-small", Inputs: []string{"a", "b"}}); err != nil {
+		t.Fatalf("expected the request to succeed despite the sink, got %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].Kind != WarningAuditFailed {
+		t.Errorf("expected an audit warning, got %v", warnings)
+	}
+}
+
+func TestAudit_Gateway(t *testing.T) {
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return fakeResponse(http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":"Hi"}}]}`), nil
+		},
+	}
+	var buf bytes.Buffer
+	client.Audit = NewFileAuditSink(&buf)
+	client.VirtualKeys = NewVirtualKeys()
+	secret := client.VirtualKeys.Issue(VirtualKey{Name: "search"})
+
+	if recorder := proxyWithKey(client, secret, `{"model":"gpt-4o-mini","messages":[{"role":"user","content":"Hi"}]}`); recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body)
+	}
+	var record AuditRecord
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if record.Tenant != "search" {
+		t.Errorf("expected the key name as the tenant, got %q", record.Tenant)
+	}
+}
@@ -0,0 +1,122 @@
+package openaiclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+const (
+	BatchStatusValidating = "validating"
+	BatchStatusFailed     = "failed"
+	BatchStatusInProgress = "in_progress"
+	BatchStatusFinalizing = "finalizing"
+	BatchStatusCompleted  = "completed"
+	BatchStatusExpired    = "expired"
+	BatchStatusCancelling = "cancelling"
+	BatchStatusCancelled  = "cancelled"
+
+	BatchCompletionWindow24h = "24h"
+)
+
+type (
+	CreateBatchPayload struct {
+		InputFileId      string            `json:"input_file_id"`
+		Endpoint         string            `json:"endpoint"`
+		CompletionWindow string            `json:"completion_window"`
+		Metadata         map[string]string `json:"metadata,omitempty"`
+		// ExtraBody is merged into the serialized request, for parameters the
+		// library does not model yet.
+		ExtraBody map[string]any `json:"-"`
+	}
+
+	BatchError struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Param   string `json:"param,omitempty"`
+		Line    int    `json:"line,omitempty"`
+	}
+
+	BatchErrors struct {
+		Object string       `json:"object"`
+		Data   []BatchError `json:"data"`
+	}
+
+	BatchRequestCounts struct {
+		Total     int `json:"total"`
+		Completed int `json:"completed"`
+		Failed    int `json:"failed"`
+	}
+
+	Batch struct {
+		Id               string             `json:"id"`
+		Object           string             `json:"object"`
+		Endpoint         string             `json:"endpoint"`
+		Errors           *BatchErrors       `json:"errors,omitempty"`
+		InputFileId      string             `json:"input_file_id"`
+		CompletionWindow string             `json:"completion_window"`
+		Status           string             `json:"status"`
+		OutputFileId     string             `json:"output_file_id,omitempty"`
+		ErrorFileId      string             `json:"error_file_id,omitempty"`
+		CreatedAt        int64              `json:"created_at"`
+		InProgressAt     int64              `json:"in_progress_at,omitempty"`
+		ExpiresAt        int64              `json:"expires_at,omitempty"`
+		FinalizingAt     int64              `json:"finalizing_at,omitempty"`
+		CompletedAt      int64              `json:"completed_at,omitempty"`
+		FailedAt         int64              `json:"failed_at,omitempty"`
+		ExpiredAt        int64              `json:"expired_at,omitempty"`
+		CancellingAt     int64              `json:"cancelling_at,omitempty"`
+		CancelledAt      int64              `json:"cancelled_at,omitempty"`
+		RequestCounts    BatchRequestCounts `json:"request_counts"`
+		Metadata         map[string]string  `json:"metadata,omitempty"`
+	}
+)
+
+// Done reports whether the batch reached a terminal status.
+func (b *Batch) Done() bool {
+	switch b.Status {
+	case BatchStatusFailed, BatchStatusCompleted, BatchStatusExpired, BatchStatusCancelled:
+		return true
+	}
+	return false
+}
+
+func (o *OpenAI) CreateBatch(ctx context.Context, payload *CreateBatchPayload) (*Batch, error) {
+	if payload.CompletionWindow == "" {
+		payload.CompletionWindow = BatchCompletionWindow24h
+	}
+
+	var batch Batch
+	if err := o.call(ctx, http.MethodPost, batchesEndpoint, payload, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (o *OpenAI) GetBatch(ctx context.Context, batchId string) (*Batch, error) {
+	var batch Batch
+	if err := o.get(ctx, batchEndpoint(batchId), nil, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (o *OpenAI) CancelBatch(ctx context.Context, batchId string) (*Batch, error) {
+	var batch Batch
+	if err := o.call(ctx, http.MethodPost, batchEndpoint(batchId)+"/cancel", nil, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (o *OpenAI) ListBatches(ctx context.Context, params *ListParams) (*List[Batch], error) {
+	var batches List[Batch]
+	if err := o.get(ctx, batchesEndpoint, params.values(), &batches); err != nil {
+		return nil, err
+	}
+	return &batches, nil
+}
+
+func batchEndpoint(batchId string) string {
+	return batchesEndpoint + "/" + url.PathEscape(batchId)
+}
@@ -0,0 +1,71 @@
+package openaiclient
+
+import (
+	"net/http"
+	"strings"
+)
+
+const betaHeader = "OpenAI-Beta"
+
+// Feature is a set of beta features negotiated through the OpenAI-Beta
+// header.
+type Feature uint
+
+const (
+	FeatureAssistantsV2 Feature = 1 << iota
+	FeatureRealtime
+
+	DefaultFeatures = FeatureAssistantsV2 | FeatureRealtime
+)
+
+type betaFeature struct {
+	feature   Feature
+	value     string
+	endpoints []string
+}
+
+var betaFeatures = []betaFeature{
+	{
+		feature:   FeatureAssistantsV2,
+		value:     "assistants=v2",
+		endpoints: []string{"/v1/assistants", "/v1/threads", "/v1/vector_stores"},
+	},
+	{
+		feature:   FeatureRealtime,
+		value:     "realtime=v1",
+		endpoints: []string{"/v1/realtime"},
+	},
+}
+
+func (f Feature) Has(feature Feature) bool {
+	return f&feature == feature
+}
+
+// betaHeaderValue returns the OpenAI-Beta value for the endpoint: the
+// override when one is configured, otherwise the enabled features that apply
+// to it.
+func (o *OpenAI) betaHeaderValue(endpoint string) string {
+	if o.BetaOverride != "" {
+		return o.BetaOverride
+	}
+
+	var values []string
+	for _, beta := range betaFeatures {
+		if !o.Features.Has(beta.feature) {
+			continue
+		}
+		for _, prefix := range beta.endpoints {
+			if strings.HasPrefix(endpoint, prefix) {
+				values = append(values, beta.value)
+				break
+			}
+		}
+	}
+	return strings.Join(values, ",")
+}
+
+func (o *OpenAI) setBetaHeader(request *http.Request, endpoint string) {
+	if value := o.betaHeaderValue(endpoint); value != "" {
+		request.Header.Set(betaHeader, value)
+	}
+}
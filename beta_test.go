@@ -0,0 +1,88 @@
+package openaiclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestBetaHeaderValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		features Feature
+		override string
+		endpoint string
+		want     string
+	}{
+		{
+			name:     "assistants endpoint",
+			features: DefaultFeatures,
+			endpoint: "/v1/assistants/asst_123",
+			want:     "assistants=v2",
+		},
+		{
+			name:     "threads endpoint",
+			features: DefaultFeatures,
+			endpoint: "/v1/threads",
+			want:     "assistants=v2",
+		},
+		{
+			name:     "realtime endpoint",
+			features: DefaultFeatures,
+			endpoint: "/v1/realtime/sessions",
+			want:     "realtime=v1",
+		},
+		{
+			name:     "non beta endpoint",
+			features: DefaultFeatures,
+			endpoint: completionsEndpont,
+			want:     "",
+		},
+		{
+			name:     "disabled feature",
+			features: FeatureRealtime,
+			endpoint: "/v1/assistants",
+			want:     "",
+		},
+		{
+			name:     "override",
+			features: DefaultFeatures,
+			override: "gateway-beta=v3",
+			endpoint: completionsEndpont,
+			want:     "gateway-beta=v3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := createClient(t)
+			client.Features = tt.features
+			client.BetaOverride = tt.override
+
+			if got := client.betaHeaderValue(tt.endpoint); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBetaHeaderInjection(t *testing.T) {
+	var header string
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			header = req.Header.Get(betaHeader)
+			return fakeResponse(200, `{"object":"list","data":[]}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+	client.BetaOverride = "custom=v1"
+
+	if _, err := client.ListModels(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if header != "custom=v1" {
+		t.Errorf("expected beta header 'custom=v1', got '%s'", header)
+	}
+}
@@ -1,17 +1,34 @@
 package openaiclient
 
 import (
+	"cmp"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
-	"os"
+	"net/url"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
 )
 
 const (
 	completionsEndpont = "/v1/chat/completions"
 	embeddingsEndpoint = "/v1/embeddings"
+	modelsEndpoint     = "/v1/models"
+	filesEndpoint      = "/v1/files"
+	batchesEndpoint    = "/v1/batches"
+
+	organizationEndpoint     = "/v1/organization"
+	completionsUsageEndpoint = organizationEndpoint + "/usage/completions"
+	costsEndpoint            = organizationEndpoint + "/costs"
+	projectsEndpoint         = organizationEndpoint + "/projects"
+
+	defaultModel = "gpt-4o-mini"
 )
 
 type httpClient interface {
@@ -23,27 +40,122 @@ type OpenAI struct {
 	client        httpClient
 	key           string
 	MaxIterations int
+	// DefaultModel is used for completions that do not set a model. New sets
+	// it from OPENAI_MODEL; when empty, gpt-4o-mini is used.
+	DefaultModel string
+	// Router picks the model of completions that do not set one from the
+	// task hint of their context, see WithTask.
+	Router Router
+	// Presets are the named task configurations run by Preset.
+	Presets Presets
+	// MaxRetries is the number of times a failed request is retried. Only
+	// requests that are safe to repeat are retried unless RetryUnsafe is set.
+	MaxRetries   int
+	RetryBackoff time.Duration
+	RetryUnsafe  bool
+	// AdminKey authorizes the organization (Admin API) endpoints. When empty
+	// the regular API key is used.
+	AdminKey string
+	// Organization and Project select the organization and project of
+	// requests made with keys that have access to several.
+	Organization string
+	Project      string
+	// Features selects the beta features whose OpenAI-Beta header is sent to
+	// the endpoints that require it. BetaOverride, when set, is sent verbatim
+	// on every request instead, e.g. for gateways with their own betas.
+	Features     Feature
+	BetaOverride string
+	// TrimPolicy, when set, shortens payload.Messages and retries the request
+	// once if it exceeds the model's context window.
+	TrimPolicy TrimPolicy
+	// HedgeDelay, when set, sends a second identical request if the first one
+	// has not received a response within the delay, and uses whichever
+	// responds first. Only requests that are safe to repeat are hedged. The
+	// hedge goes to HedgeTarget when set, e.g. a fallback provider.
+	HedgeDelay  time.Duration
+	HedgeTarget *OpenAI
+	// FallbackModels is tried in order when the requested model is not found,
+	// rate limited or times out.
+	FallbackModels []string
+	// ModelRegistry is consulted to reject requests a model is known not to
+	// support before they are sent. Set it to nil to disable the checks.
+	ModelRegistry *ModelRegistry
+	// ValidatePayloads runs CompletionRequestPayload.Validate before every
+	// completion request.
+	ValidatePayloads bool
+	// RateLimitHeaders reads the provider's rate limit headers, which pace
+	// requests and are attached to errors. It is picked from the base URL.
+	RateLimitHeaders RateLimitHeaders
+	// EmulateTools describes the tools in the system prompt instead of
+	// sending them, and parses the model's JSON replies into tool calls, for
+	// OpenAI-compatible backends without native tool support.
+	EmulateTools bool
+	// RepanicTools lets panics raised by tools propagate instead of reporting
+	// them to the model as tool errors.
+	RepanicTools bool
+	// KeepRawResponses keeps the full response body in the Raw field of the
+	// decoded responses, so fields the library does not model yet can still
+	// be read.
+	KeepRawResponses bool
+	// LogRedaction controls how message contents appear in log records. It
+	// defaults to the policy selected by OPENAI_LOG_CONTENT.
+	LogRedaction RedactionPolicy
+	// Usage, when set, accumulates the token usage of the completions and
+	// enforces its token limit.
+	Usage *UsageTracker
+	// Traffic, when set, accumulates the size of the requests and responses
+	// by model and tenant.
+	Traffic *TrafficTracker
+	// RunLog, when set, records the requests, responses, tool calls and
+	// errors of every completion run.
+	RunLog *RunLog
+	// ToolPruning, when set, sends only the tools most relevant to the
+	// user's message in requests with many tools.
+	ToolPruning *ToolPruning
+	// Regions, when set, sends the requests to the fastest healthy of
+	// several regional endpoints instead of the base URL.
+	Regions *Regions
+	// Shadow, when set, duplicates a fraction of the completions to another
+	// model or provider in the background for comparison.
+	Shadow *Shadow
+	// OnWarning, when set, receives the non-fatal conditions that are also
+	// logged as warnings, such as calls to unknown tools or trimmed
+	// histories, so applications can report them in their own telemetry. It
+	// may be called from background goroutines.
+	OnWarning func(Warning)
+	// VirtualKeys, when set, makes ProxyHandler require one of its keys and
+	// serve each key with its own client, allowlist and quotas. Without
+	// them, ProxyHandler only serves requests when ProxyOptions allows
+	// unauthenticated callers.
+	VirtualKeys *VirtualKeys
+	// Audit, when set, receives a record of every request and its response
+	// or error, with contents redacted by LogRedaction, e.g. for
+	// compliance.
+	Audit AuditSink
+
+	pacer      *pacer
+	modelSlots *modelSlots
+	lifecycle  *lifecycle
 }
 
+// New returns a client of the base URL and API key, or of OPENAI_BASE_URL
+// and OPENAI_API_KEY when they are empty. The other settings are read from
+// the environment as LoadConfigFromEnv does.
 func New(baseUrl, apiKey string) (*OpenAI, error) {
-	if baseUrl == "" {
-		baseUrl = os.Getenv("OPENAI_BASE_URL")
-		if baseUrl == "" {
-			baseUrl = "https://api.openai.com"
-		}
+	config, problems := configFromEnv()
+	if baseUrl != "" {
+		config.BaseURL = baseUrl
 	}
-	if apiKey == "" {
-		apiKey = os.Getenv("OPENAI_API_KEY")
-		if apiKey == "" {
-			return nil, NewAuthenticationError("OPENAI_API_KEY is not set")
-		}
+	if apiKey != "" {
+		config.APIKey = apiKey
 	}
-	return &OpenAI{
-		baseUrl:       baseUrl,
-		client:        &http.Client{},
-		key:           apiKey,
-		MaxIterations: 5,
-	}, nil
+	if config.APIKey == "" {
+		return nil, NewAuthenticationError("OPENAI_API_KEY is not set")
+	}
+	if len(problems) > 0 {
+		return nil, &ConfigError{Problems: problems}
+	}
+	return NewFromConfig(config)
 }
 
 func NewDefault() (*OpenAI, error) {
@@ -51,17 +163,102 @@ func NewDefault() (*OpenAI, error) {
 }
 
 func (o *OpenAI) GetCompletion(payload *CompletionRequestPayload) (*Message, error) {
+	return o.GetCompletionContext(context.Background(), payload)
+}
+
+func (o *OpenAI) GetCompletionContext(ctx context.Context, payload *CompletionRequestPayload) (*Message, error) {
+	response, err := o.GetCompletionResponse(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	return response.Choices[0].Message, nil
+}
+
+// GetCompletionResponse runs the completion like GetCompletionContext and
+// returns the whole response to its final request, including every choice,
+// their finish reasons, the usage and, when KeepRawResponses is set, the raw
+// body.
+func (o *OpenAI) GetCompletionResponse(ctx context.Context, payload *CompletionRequestPayload) (*CompletionResponse, error) {
+	ctx, done, err := o.lifecycle.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	ctx = ensureCorrelationId(ctx)
+	applyContextOptions(ctx, payload)
+	if payload.SafetyIdentifier == "" && payload.User == "" {
+		payload.SafetyIdentifier = EndUser(ctx)
+	}
+	if err := o.Router.route(ctx, payload); err != nil {
+		return nil, err
+	}
 	if payload.Model == "" {
-		payload.Model = os.Getenv("OPENAI_MODEL")
-		if payload.Model == "" {
-			payload.Model = "gpt-4o-mini"
+		payload.Model = o.defaultModel()
+	}
+	if err := o.ModelRegistry.validate(o.wirePayload(payload)); err != nil {
+		return nil, err
+	}
+	if o.ValidatePayloads {
+		if err := payload.Validate(); err != nil {
+			return nil, err
 		}
 	}
-	return o.performReActLoop(payload, o.MaxIterations)
+	restoreTools, err := o.ToolPruning.prune(ctx, o, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer restoreTools()
+	shadow := o.Shadow.shadowPayload(payload)
+	response, err := o.performReActLoop(ctx, payload, o.MaxIterations)
+	if err == nil && shadow != nil {
+		o.Shadow.send(ctx, o, shadow, payload)
+	}
+	return response, err
 }
 
 func (o *OpenAI) GetEmbedding(payload GetEmbeddingPayload) ([]float64, error) {
+	return o.GetEmbeddingContext(context.Background(), payload)
+}
+
+func (o *OpenAI) GetEmbeddingContext(ctx context.Context, payload GetEmbeddingPayload) ([]float64, error) {
+	response, err := o.GetEmbeddingResponse(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	return response.Data[0].Embedding, nil
+}
+
+// GetEmbeddings returns the embeddings of the Inputs or Tokens of the
+// payload, in the same order.
+func (o *OpenAI) GetEmbeddings(ctx context.Context, payload GetEmbeddingPayload) ([][]float64, error) {
+	response, err := o.GetEmbeddingResponse(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	if expected := payload.inputCount(); len(response.Data) != expected {
+		return nil, NewInvalidRequestError(fmt.Sprintf("%d embeddings returned for %d inputs", len(response.Data), expected))
+	}
+
+	embeddings := make([][]float64, len(response.Data))
+	for i, data := range response.Data {
+		embeddings[i] = data.Embedding
+	}
+	return embeddings, nil
+}
+
+// GetEmbeddingResponse returns the whole embeddings response, including the
+// usage and, when KeepRawResponses is set, the raw body. Its data is sorted
+// by index, the order of the inputs.
+func (o *OpenAI) GetEmbeddingResponse(ctx context.Context, payload GetEmbeddingPayload) (*GetEmbeddingResponse, error) {
+	if (payload.Input != "" && payload.Inputs != nil) || (payload.Tokens != nil && (payload.Input != "" || payload.Inputs != nil)) {
+		return nil, NewInvalidRequestError("only one of Input, Inputs and Tokens can be set")
+	}
+	if payload.User == "" {
+		payload.User = EndUser(ctx)
+	}
 	request, err := o.createAuthorizedRequest(
+		ctx,
 		http.MethodPost,
 		embeddingsEndpoint,
 		payload,
@@ -70,18 +267,9 @@ func (o *OpenAI) GetEmbedding(payload GetEmbeddingPayload) ([]float64, error) {
 		return nil, err
 	}
 
-	response, err := o.client.Do(request)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %w", err)
-	}
-	defer response.Body.Close()
-	responseText, err := io.ReadAll(response.Body)
+	responseText, err := o.doRequest(request, true)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
-	}
-
-	if response.StatusCode != http.StatusOK {
-		return nil, NewOpenAIError(response.StatusCode, responseText)
+		return nil, err
 	}
 
 	var responseBody GetEmbeddingResponse
@@ -89,58 +277,320 @@ func (o *OpenAI) GetEmbedding(payload GetEmbeddingPayload) ([]float64, error) {
 		return nil, fmt.Errorf("error unmarshaling response body: %w", err)
 	}
 
-	return responseBody.Data[0].Embedding, nil
+	if len(responseBody.Data) == 0 {
+		return nil, NewInvalidRequestError("no embeddings returned")
+	}
+	slices.SortStableFunc(responseBody.Data, func(a, b EmbeddingObject) int {
+		return cmp.Compare(a.Index, b.Index)
+	})
+	if o.KeepRawResponses {
+		responseBody.Raw = responseText
+	}
+
+	return &responseBody, nil
 }
 
-func (o *OpenAI) endpoint(e string) string {
+func (o *OpenAI) defaultModel() string {
+	if o.DefaultModel != "" {
+		return o.DefaultModel
+	}
+	return defaultModel
+}
+
+func (o *OpenAI) endpoint(ctx context.Context, e string) string {
+	if baseUrl := o.Regions.baseUrl(ctx); baseUrl != "" {
+		return baseUrl + e
+	}
 	return fmt.Sprintf("%s%s", o.baseUrl, e)
 }
 
-func (o *OpenAI) createAuthorizedRequest(method, endpoint string, body any) (*http.Request, error) {
-	return createAuthorizedRequest(method, o.endpoint(endpoint), body, o.key)
+// endpointOf returns the endpoint of a request URL built by endpoint.
+func (o *OpenAI) endpointOf(rawUrl string) string {
+	if endpoint, ok := o.Regions.trimBaseUrl(rawUrl); ok {
+		return endpoint
+	}
+	return strings.TrimPrefix(rawUrl, o.baseUrl)
 }
 
-func (o *OpenAI) performReActLoop(payload *CompletionRequestPayload, maxIterations int) (*Message, error) {
-	for range maxIterations {
-		if err := o.getCompletion(payload); err != nil {
+func (o *OpenAI) keyFor(endpoint string) string {
+	if o.AdminKey != "" && strings.HasPrefix(endpoint, organizationEndpoint) {
+		return o.AdminKey
+	}
+	return o.key
+}
+
+func (o *OpenAI) createAuthorizedRequest(ctx context.Context, method, endpoint string, body any) (*http.Request, error) {
+	request, err := createAuthorizedRequest(method, o.endpoint(ctx, endpoint), body, o.keyFor(endpoint))
+	if err != nil {
+		return nil, err
+	}
+	o.setBetaHeader(request, endpoint)
+	o.setAccountHeaders(request)
+	ctx = withRequestModel(ctx, body)
+	request = request.WithContext(ctx)
+	setCorrelationHeader(request)
+	return request, nil
+}
+
+// setAccountHeaders sets the organization and project the request is made
+// for, when configured.
+func (o *OpenAI) setAccountHeaders(request *http.Request) {
+	if o.Organization != "" {
+		request.Header.Set("OpenAI-Organization", o.Organization)
+	}
+	if o.Project != "" {
+		request.Header.Set("OpenAI-Project", o.Project)
+	}
+}
+
+// get performs a GET request to the endpoint with the given query and decodes
+// the response into out.
+func (o *OpenAI) get(ctx context.Context, endpoint string, query url.Values, out any) error {
+	if len(query) > 0 {
+		endpoint = endpoint + "?" + query.Encode()
+	}
+	return o.call(ctx, http.MethodGet, endpoint, nil, out)
+}
+
+// call sends a JSON request and decodes the response into out. Only GET and
+// DELETE requests are considered safe to retry.
+func (o *OpenAI) call(ctx context.Context, method, endpoint string, body any, out any) error {
+	request, err := o.createAuthorizedRequest(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+
+	safe := method == http.MethodGet || method == http.MethodDelete
+	responseText, err := o.doRequest(request, safe)
+	if err != nil {
+		return err
+	}
+
+	return unmarshalResponse(responseText, out)
+}
+
+// doRequest sends the request, retrying transient failures when the request
+// is safe to repeat or the client opted into retrying unsafe requests.
+func (o *OpenAI) doRequest(request *http.Request, safe bool) ([]byte, error) {
+	if o.Audit != nil {
+		return o.auditRequest(request, safe)
+	}
+	return o.sendRequest(request, safe)
+}
+
+func (o *OpenAI) sendRequest(request *http.Request, safe bool) ([]byte, error) {
+	ctx, done, err := o.lifecycle.begin(request.Context())
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	request = request.WithContext(ctx)
+
+	retries := 0
+	if safe || o.RetryUnsafe {
+		retries = o.MaxRetries
+	}
+
+	slog.Debug(
+		"sending request",
+		slog.String("method", request.Method),
+		slog.String("endpoint", request.URL.Path),
+		correlationAttr(request.Context()),
+	)
+
+	for attempt := 0; ; attempt++ {
+		if err := o.pacer.wait(request.Context()); err != nil {
 			return nil, err
 		}
 
+		release, err := o.modelSlots.acquire(request.Context(), requestModel(request.Context()))
+		if err != nil {
+			return nil, err
+		}
+		responseText, statusCode, err := o.attempt(request, safe)
+		release()
+		o.Traffic.add(request, len(responseText))
+		if err == nil {
+			return responseText, nil
+		}
+		if attempt >= retries || !shouldRetry(request, statusCode) {
+			return nil, err
+		}
+
+		slog.Debug(
+			"retrying request",
+			slog.String("endpoint", request.URL.Path),
+			slog.Int("attempt", attempt+1),
+			slog.Any("error", err),
+			correlationAttr(request.Context()),
+		)
+
+		if err := sleep(request.Context(), o.backoff(attempt)); err != nil {
+			return nil, err
+		}
+		if request, err = rewindRequest(request); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (o *OpenAI) attempt(request *http.Request, safe bool) ([]byte, int, error) {
+	if safe && o.HedgeDelay > 0 {
+		return o.sendHedged(request)
+	}
+	return o.send(request)
+}
+
+func (o *OpenAI) send(request *http.Request) ([]byte, int, error) {
+	response, err := o.client.Do(request)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error making request: %w", err)
+	}
+	return o.readResponse(response)
+}
+
+func (o *OpenAI) readResponse(response *http.Response) ([]byte, int, error) {
+	defer response.Body.Close()
+	limits, hasLimits := o.rateLimitInfo(response.Header)
+	o.paceAfter(response, limits)
+
+	responseText, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, response.StatusCode, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		err := NewOpenAIError(response.StatusCode, responseText)
+		var apiErr *OpenAIError
+		if hasLimits && errors.As(err, &apiErr) {
+			apiErr.RateLimit = &limits
+		}
+		return nil, response.StatusCode, err
+	}
+
+	return responseText, response.StatusCode, nil
+}
+
+func (o *OpenAI) performReActLoop(ctx context.Context, payload *CompletionRequestPayload, maxIterations int) (*CompletionResponse, error) {
+	logged, fingerprint := 0, ""
+	var totalUsage *LLMUsage
+	for iteration := range maxIterations {
+		if o.RunLog != nil {
+			o.RunLog.record(ctx, RunEvent{
+				Type:       RunEventRequest,
+				Iteration:  iteration,
+				Model:      payload.Model,
+				Messages:   len(payload.Messages),
+				Tools:      len(payload.Tools),
+				PromptHash: PromptHash(payload),
+				Input:      slices.Clone(payload.Messages[min(logged, len(payload.Messages)):]),
+			})
+		}
+		start := time.Now()
+		response, err := o.getCompletion(ctx, payload)
+		if err != nil {
+			o.RunLog.recordError(ctx, iteration, err)
+			return nil, err
+		}
+
+		if response.Usage != nil {
+			total := *response.Usage
+			if totalUsage != nil {
+				total = addUsage(*totalUsage, total)
+			}
+			totalUsage = &total
+		}
+
+		if response.SystemFingerprint != "" {
+			if fingerprint != "" && response.SystemFingerprint != fingerprint {
+				o.warn(ctx, WarningFingerprintChanged, "system fingerprint changed",
+					slog.String("from", fingerprint),
+					slog.String("to", response.SystemFingerprint),
+				)
+			}
+			fingerprint = response.SystemFingerprint
+		}
+
 		responseBody := payload.Messages[len(payload.Messages)-1]
+		logged = len(payload.Messages)
+		o.RunLog.record(ctx, RunEvent{
+			Type:      RunEventResponse,
+			Iteration: iteration,
+			Model:     response.Model,
+			Message:   &responseBody,
+			Usage:     response.Usage,
+			Duration:  time.Since(start),
+		})
 
-		if len(responseBody.ToolCalls) == 0 {
+		if len(responseBody.ToolCalls) == 0 || passesToolCalls(ctx) {
 			content := responseBody.Content
 			if content != "" {
-				slog.Debug("final response", slog.String("content", content))
+				slog.Debug("final response", o.contentAttr(content), correlationAttr(ctx))
 			}
-			return &responseBody, nil
+			response.TotalUsage = totalUsage
+			return response, nil
 		}
 
-		if err := o.handleToolCalls(payload); err != nil {
+		if err := o.handleToolCalls(ctx, payload, iteration); err != nil {
+			o.RunLog.recordError(ctx, iteration, err)
 			return nil, fmt.Errorf("error handling tool calls: %w", err)
 		}
 	}
 
-	return nil, NewInvalidRequestError("reached max iterations without finalizing an answer")
+	err := NewInvalidRequestError("reached max iterations without finalizing an answer")
+	o.RunLog.recordError(ctx, maxIterations, err)
+	return nil, err
 }
 
-func (o *OpenAI) handleToolCalls(payload *CompletionRequestPayload) error {
-	slog.Debug("handling tool calls")
+func (o *OpenAI) handleToolCalls(ctx context.Context, payload *CompletionRequestPayload, iteration int) error {
+	slog.Debug("handling tool calls", correlationAttr(ctx))
 
 	message := payload.Messages[len(payload.Messages)-1]
 
 	for _, toolCall := range message.ToolCalls {
+		// Once canceled, the remaining calls are still answered so the
+		// conversation stays valid for a later request.
+		if err := ctx.Err(); err != nil {
+			payload.AddMessages(Message{
+				Role:       MessageRoleTool,
+				Content:    toolError(err),
+				ToolCallId: toolCall.Id,
+			})
+			continue
+		}
+
 		fnName := toolCall.Function.Name
 		arguments := toolCall.Function.Arguments
 		tool, toolFound := payload.toolsMap()[fnName]
 		if !toolFound {
-			slog.Warn("tool not found", slog.String("toolName", fnName))
+			o.warn(ctx, WarningUnknownTool, "tool not found", slog.String("toolName", fnName))
+			payload.AddMessages(Message{
+				Role:       MessageRoleTool,
+				Content:    toolError(fmt.Errorf("unknown tool %s", fnName)),
+				ToolCallId: toolCall.Id,
+			})
 			continue
 		}
 
-		slog.Debug("calling tool", slog.String("toolName", fnName))
+		slog.Debug("calling tool", slog.String("toolName", fnName), correlationAttr(ctx))
 
-		result := tool.Fn(arguments)
+		o.RunLog.record(ctx, RunEvent{
+			Type:       RunEventToolCall,
+			Iteration:  iteration,
+			ToolName:   fnName,
+			ToolCallId: toolCall.Id,
+			Arguments:  arguments,
+		})
+		start := time.Now()
+		result := o.runTool(ctx, tool, arguments)
+		o.RunLog.record(ctx, RunEvent{
+			Type:       RunEventToolResult,
+			Iteration:  iteration,
+			ToolName:   fnName,
+			ToolCallId: toolCall.Id,
+			Result:     result,
+			Duration:   time.Since(start),
+		})
 
 		payload.AddMessages(Message{
 			Role:       MessageRoleTool,
@@ -148,44 +598,119 @@ func (o *OpenAI) handleToolCalls(payload *CompletionRequestPayload) error {
 			ToolCallId: toolCall.Id,
 		})
 	}
-	return nil
+	return ctx.Err()
 }
 
-func (o *OpenAI) getCompletion(payload *CompletionRequestPayload) error {
-	request, err := o.createAuthorizedRequest(
-		http.MethodPost,
-		completionsEndpont,
-		payload,
-	)
-	if err != nil {
-		return err
-	}
+// runTool calls the tool, turning a panic into an error result for the model
+// unless RepanicTools is set.
+func (o *OpenAI) runTool(ctx context.Context, tool *FunctionDefinition, arguments string) (result string) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		if o.RepanicTools {
+			panic(r)
+		}
 
-	response, err := o.client.Do(request)
-	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
-	}
-	defer response.Body.Close()
+		slog.Error(
+			"tool panicked",
+			slog.String("toolName", tool.Name),
+			slog.Any("panic", r),
+			slog.String("stack", string(debug.Stack())),
+			correlationAttr(ctx),
+		)
+		result = toolError(fmt.Errorf("tool panicked: %v", r))
+	}()
+	return tool.call(ctx, arguments)
+}
 
-	responseText, err := io.ReadAll(response.Body)
-	if err != nil {
-		return fmt.Errorf("error reading response body: %w", err)
+func (o *OpenAI) getCompletion(ctx context.Context, payload *CompletionRequestPayload) (*CompletionResponse, error) {
+	if err := o.Usage.checkBudget(); err != nil {
+		return nil, err
 	}
 
-	if response.StatusCode != http.StatusOK {
-		return NewOpenAIError(response.StatusCode, responseText)
+	responseText, err := o.postCompletionWithFallback(ctx, payload)
+	if err != nil && o.trimAfterContextLengthExceeded(ctx, payload, err) {
+		responseText, err = o.postCompletionWithFallback(ctx, payload)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	var responseBody CompletionResponse
 	if err := json.Unmarshal(responseText, &responseBody); err != nil {
-		return fmt.Errorf("error unmarshaling response body: %w", err)
+		return nil, fmt.Errorf("error unmarshaling response body: %w", err)
 	}
 
 	if len(responseBody.Choices) == 0 {
-		return NewInvalidRequestError("no choices returned")
+		return nil, NewInvalidRequestError("no choices returned")
 	}
+	if responseBody.Choices[0].Message == nil {
+		return nil, NewInvalidRequestError("no message returned")
+	}
+	if len(responseBody.Choices) > 1 {
+		o.warn(ctx, WarningDroppedChoices, "using the first of several choices", slog.Int("choices", len(responseBody.Choices)))
+	}
+	if o.emulatesTools(payload) {
+		parseEmulatedToolCall(responseBody.Choices[0].Message, payload.toolsMap())
+	}
+	if o.KeepRawResponses || passesToolCalls(ctx) {
+		responseBody.Raw = responseText
+	}
+	o.Usage.add(payload.Model, responseBody.Usage)
+
+	slog.Debug(
+		"completion received",
+		slog.String("id", responseBody.Id),
+		slog.String("model", responseBody.Model),
+		slog.String("systemFingerprint", responseBody.SystemFingerprint),
+		correlationAttr(ctx),
+	)
 
 	payload.AddMessages(*responseBody.Choices[0].Message)
 
-	return nil
+	return &responseBody, nil
+}
+
+// wirePayload returns the payload as it is sent to the API.
+func (o *OpenAI) wirePayload(payload *CompletionRequestPayload) *CompletionRequestPayload {
+	payload = withPromptHash(withoutReasoningContent(payload))
+	if o.emulatesTools(payload) {
+		payload = emulatedPayload(payload)
+	}
+	return payload
+}
+
+// withoutReasoningContent strips the reasoning content of earlier replies,
+// which the providers returning it reject in requests.
+func withoutReasoningContent(payload *CompletionRequestPayload) *CompletionRequestPayload {
+	if !slices.ContainsFunc(payload.Messages, func(m Message) bool { return m.ReasoningContent != "" }) {
+		return payload
+	}
+
+	stripped := *payload
+	stripped.Messages = slices.Clone(payload.Messages)
+	for i := range stripped.Messages {
+		stripped.Messages[i].ReasoningContent = ""
+	}
+	return &stripped
+}
+
+func (o *OpenAI) postCompletion(ctx context.Context, payload *CompletionRequestPayload) ([]byte, error) {
+	if !modelAllowed(ctx, payload.Model) {
+		return nil, modelNotAllowedError(payload.Model)
+	}
+	request, err := o.createAuthorizedRequest(
+		ctx,
+		http.MethodPost,
+		completionsEndpont,
+		o.wirePayload(payload),
+	)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set(idempotencyKeyHeader, newIdempotencyKey())
+
+	return o.doRequest(request, !payload.stored())
 }
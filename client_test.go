@@ -1,6 +1,7 @@
 package openaiclient
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -124,6 +125,92 @@ func TestGetEmbedding_ClientError(t *testing.T) {
 	}
 }
 
+func TestGetEmbeddingResponse_KeepRawResponses(t *testing.T) {
+	body := `{"object":"list","data":[{"embedding":[0.1]}],"new_field":"value"}`
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return fakeResponse(http.StatusOK, body), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+	payload := GetEmbeddingPayload{Model: "test-model", Input: "Hello"}
+
+	response, err := client.GetEmbeddingResponse(context.Background(), payload)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if response.Raw != nil {
+		t.Errorf("expected no raw body by default, got %s", response.Raw)
+	}
+
+	client.KeepRawResponses = true
+	response, err = client.GetEmbeddingResponse(context.Background(), payload)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(response.Raw) != body {
+		t.Errorf("expected raw body %s, got %s", body, response.Raw)
+	}
+}
+
+func TestGetEmbedding_NoData(t *testing.T) {
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return fakeResponse(http.StatusOK, `{"data":[]}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	if _, err := client.GetEmbedding(GetEmbeddingPayload{Model: "test-model", Input: "Hello"}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestGetEmbeddings(t *testing.T) {
+	var body map[string]any
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			json.NewDecoder(req.Body).Decode(&body)
+			return fakeResponse(http.StatusOK, `{"data":[{"index":1,"embedding":[0.3,0.4]},{"index":0,"embedding":[0.1,0.2]}]}`), nil
+		},
+	}
+
+	embeddings, err := client.GetEmbeddings(context.Background(), GetEmbeddingPayload{
+		Model:      "text-embedding-3-small",
+		Inputs:     []string{"first", "second"},
+		Dimensions: Ptr(2),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(embeddings) != 2 || embeddings[0][0] != 0.1 || embeddings[1][0] != 0.3 {
+		t.Errorf("expected the embeddings in input order, got %v", embeddings)
+	}
+	if inputs, ok := body["input"].([]any); !ok || len(inputs) != 2 || inputs[1] != "second" || body["dimensions"] != 2.0 {
+		t.Errorf("expected the inputs and dimensions to be sent, got %v", body)
+	}
+
+	if _, err := client.GetEmbeddings(context.Background(), GetEmbeddingPayload{Tokens: [][]int{{1, 2}, {3}, {4}}}); GetOpenAIErrorType(err) != ErrTypeInvalidRequest {
+		t.Errorf("expected an invalid request error for missing embeddings, got %v", err)
+	}
+	if inputs, ok := body["input"].([]any); !ok || len(inputs) != 3 {
+		t.Errorf("expected the token inputs to be sent, got %v", body["input"])
+	}
+}
+
+func TestGetEmbeddings_ConflictingInputs(t *testing.T) {
+	client := NewTestClient()
+	_, err := client.GetEmbeddings(context.Background(), GetEmbeddingPayload{Input: "a", Tokens: [][]int{{1}}})
+	if GetOpenAIErrorType(err) != ErrTypeInvalidRequest || len(client.Embeddings()) != 0 {
+		t.Errorf("expected the request to be rejected, got %v", err)
+	}
+}
+
 func TestGetCompletion_Success(t *testing.T) {
 	completionMessage := Message{
 		Role:    "assistant",
@@ -166,6 +253,58 @@ func TestGetCompletion_Success(t *testing.T) {
 	}
 }
 
+func TestCompletionResponse_Metadata(t *testing.T) {
+	body := `{
+		"id": "chatcmpl-123",
+		"object": "chat.completion",
+		"created": 1677652288,
+		"model": "gpt-4o-mini-2024-07-18",
+		"system_fingerprint": "fp_44709d6fcb",
+		"choices": [{"index": 0, "message": {"role": "assistant", "content": "Hello"}}]
+	}`
+
+	var response CompletionResponse
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if response.Id != "chatcmpl-123" || response.Object != "chat.completion" || response.Created != 1677652288 {
+		t.Errorf("unexpected metadata %+v", response)
+	}
+	if response.Model != "gpt-4o-mini-2024-07-18" {
+		t.Errorf("expected model 'gpt-4o-mini-2024-07-18', got '%s'", response.Model)
+	}
+	if response.SystemFingerprint != "fp_44709d6fcb" {
+		t.Errorf("expected system fingerprint 'fp_44709d6fcb', got '%s'", response.SystemFingerprint)
+	}
+}
+
+func TestLLMUsage_Details(t *testing.T) {
+	body := `{
+		"prompt_tokens": 2000,
+		"completion_tokens": 300,
+		"total_tokens": 2300,
+		"prompt_tokens_details": {"cached_tokens": 1920, "audio_tokens": 0},
+		"completion_tokens_details": {
+			"reasoning_tokens": 256,
+			"audio_tokens": 0,
+			"accepted_prediction_tokens": 10,
+			"rejected_prediction_tokens": 4
+		}
+	}`
+
+	var usage LLMUsage
+	if err := json.Unmarshal([]byte(body), &usage); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if usage.PromptTokensDetails == nil || usage.PromptTokensDetails.CachedTokens != 1920 {
+		t.Errorf("expected 1920 cached tokens, got %+v", usage.PromptTokensDetails)
+	}
+	details := usage.CompletionTokensDetails
+	if details == nil || details.ReasoningTokens != 256 || details.AcceptedPredictionTokens != 10 || details.RejectedPredictionTokens != 4 {
+		t.Errorf("unexpected completion tokens details %+v", details)
+	}
+}
+
 func TestGetCompletion_WithToolCalls(t *testing.T) {
 	toolCall := ToolCall{
 		Id:   "tool1",
@@ -291,5 +430,282 @@ func createClient(t *testing.T) *OpenAI {
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
+	client.RetryBackoff = 0
 	return client
 }
+
+func FuzzGetCompletion(f *testing.F) {
+	f.Add(completionBody)
+	f.Add(`{"choices":[]}`)
+	f.Add(`{"choices":[{}]}`)
+	f.Add(`{"choices":[null]}`)
+	f.Add(`{"choices":[{"message":{"role":"assistant","tool_calls":[{"id":"1","function":{"name":"missing"}}]}}]}`)
+
+	f.Fuzz(func(t *testing.T, body string) {
+		client := createClient(t)
+		client.MaxIterations = 2
+		client.client = &FakeClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				return fakeResponse(http.StatusOK, body), nil
+			},
+		}
+
+		message, err := client.GetCompletion(&CompletionRequestPayload{
+			Model:    "test-model",
+			Messages: []Message{{Role: MessageRoleUser, Content: "Hi"}},
+		})
+		if (message == nil) == (err == nil) {
+			t.Errorf("expected either a message or an error, got %v and %v", message, err)
+		}
+	})
+}
+
+func TestGetCompletion_ReasoningContent(t *testing.T) {
+	var bodies []string
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			bodies = append(bodies, string(body))
+			return fakeResponse(http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":"4","reasoning_content":"2 plus 2 is 4"}}]}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	payload := &CompletionRequestPayload{Model: "deepseek-reasoner", Messages: []Message{{Role: "user", Content: "2+2?"}}}
+	message, err := client.GetCompletion(payload)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if message.ReasoningContent != "2 plus 2 is 4" {
+		t.Errorf("expected reasoning content to be parsed, got %q", message.ReasoningContent)
+	}
+
+	payload.AddMessages(Message{Role: "user", Content: "And 3+3?"})
+	if _, err := client.GetCompletion(payload); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Contains(bodies[1], "reasoning_content") {
+		t.Errorf("expected reasoning content not to be sent back, got %s", bodies[1])
+	}
+	if payload.Messages[1].ReasoningContent == "" {
+		t.Error("expected the history to keep the reasoning content")
+	}
+}
+
+func TestGetCompletion_RecoversToolPanics(t *testing.T) {
+	var toolMessage string
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			var payload CompletionRequestPayload
+			body, _ := io.ReadAll(req.Body)
+			json.Unmarshal(body, &payload)
+			if len(payload.Messages) == 1 {
+				return fakeResponse(http.StatusOK, toolCallBody), nil
+			}
+			toolMessage = payload.Messages[2].Content
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+	newPayload := func() *CompletionRequestPayload {
+		return &CompletionRequestPayload{
+			Model:    "test-model",
+			Messages: []Message{{Role: "user", Content: "Hi"}},
+			Tools: []ToolDefinition{NewToolDefinition(&FunctionDefinition{
+				Name: "echo",
+				Fn:   func(args string) string { panic("boom") },
+			})},
+		}
+	}
+
+	if _, err := client.GetCompletion(newPayload()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var result ToolResult
+	json.Unmarshal([]byte(toolMessage), &result)
+	if result.Error != "tool panicked: boom" {
+		t.Errorf("expected the panic to be reported to the model, got %q", toolMessage)
+	}
+
+	client.RepanicTools = true
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("expected the panic to propagate, got %v", r)
+		}
+	}()
+	client.GetCompletion(newPayload())
+}
+
+func TestGetCompletion_ToolErrors(t *testing.T) {
+	client := NewTestClient(
+		ScriptedTurn{ToolCalls: []ToolCall{
+			{Id: "call_1", Type: "function", Function: FunctionCall{Name: "lookup", Arguments: `{}`}},
+			{Id: "call_2", Type: "function", Function: FunctionCall{Name: "missing", Arguments: `{}`}},
+		}},
+		ScriptedTurn{Content: "Sorry, the lookup failed."},
+	)
+
+	var toolCtx context.Context
+	ctx := WithCorrelationId(context.Background(), "run-1")
+	_, err := client.GetCompletionContext(ctx, &CompletionRequestPayload{
+		Model:    "gpt-4o-mini",
+		Messages: []Message{{Role: MessageRoleUser, Content: "Weather?"}},
+		Tools: []ToolDefinition{NewToolDefinition(&FunctionDefinition{
+			Name: "lookup",
+			FnContext: func(ctx context.Context, arguments string) (string, error) {
+				toolCtx = ctx
+				return "", errors.New("service unavailable")
+			},
+		})},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if CorrelationId(toolCtx) != "run-1" {
+		t.Error("expected the tool to receive the request context")
+	}
+
+	messages := client.Completions()[1].Messages
+	for i, want := range []string{"service unavailable", "unknown tool missing"} {
+		var result ToolResult
+		json.Unmarshal([]byte(messages[2+i].Content), &result)
+		if messages[2+i].ToolCallId == "" || result.Error != want {
+			t.Errorf("expected the error %q to be sent to the model, got %+v", want, messages[2+i])
+		}
+	}
+}
+
+func TestGetCompletion_ToolCancellation(t *testing.T) {
+	client := NewTestClient(ScriptedTurn{ToolCalls: []ToolCall{
+		{Id: "call_1", Type: "function", Function: FunctionCall{Name: "cancel", Arguments: `{}`}},
+		{Id: "call_2", Type: "function", Function: FunctionCall{Name: "cancel", Arguments: `{}`}},
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	payload := &CompletionRequestPayload{
+		Model:    "gpt-4o-mini",
+		Messages: []Message{{Role: MessageRoleUser, Content: "Hi"}},
+		Tools: []ToolDefinition{NewToolDefinition(&FunctionDefinition{
+			Name: "cancel",
+			FnContext: func(ctx context.Context, arguments string) (string, error) {
+				calls++
+				cancel()
+				return "", ctx.Err()
+			},
+		})},
+	}
+	_, err := client.GetCompletionContext(ctx, payload)
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("expected the run to stop after the first tool call, got %v and %d calls", err, calls)
+	}
+	if len(payload.Messages) != 4 || payload.Messages[3].ToolCallId != "call_2" || !strings.Contains(payload.Messages[3].Content, "canceled") {
+		t.Errorf("expected every tool call to be answered, got %+v", payload.Messages)
+	}
+}
+
+func TestCompletionRequestPayload_SamplingParameters(t *testing.T) {
+	data, err := json.Marshal(&CompletionRequestPayload{
+		Messages:    []Message{{Role: MessageRoleUser, Content: "Hi"}},
+		Temperature: Ptr(0.0),
+		Seed:        Ptr(42),
+		Stop:        []string{"\n"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var body map[string]any
+	json.Unmarshal(data, &body)
+	if body["temperature"] != 0.0 || body["seed"] != 42.0 || len(body["stop"].([]any)) != 1 {
+		t.Errorf("expected the set parameters to be sent, got %s", data)
+	}
+	for _, key := range []string{"top_p", "max_tokens", "max_completion_tokens", "frequency_penalty", "presence_penalty", "n"} {
+		if _, ok := body[key]; ok {
+			t.Errorf("expected unset %s to be omitted, got %s", key, data)
+		}
+	}
+}
+
+func TestGetCompletionResponse(t *testing.T) {
+	client := createClient(t)
+	client.client = &SequentialFakeClient{
+		Responses: []*http.Response{
+			fakeResponse(http.StatusOK, `{"id":"chatcmpl-1","choices":[{"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"echo","arguments":"{}"}}]},"finish_reason":"tool_calls"}]}`),
+			fakeResponse(http.StatusOK, `{
+				"id": "chatcmpl-2",
+				"model": "gpt-4o-mini-2024-07-18",
+				"system_fingerprint": "fp_1",
+				"choices": [
+					{"index": 0, "message": {"role": "assistant", "content": "Hi"}, "finish_reason": "stop"},
+					{"index": 1, "message": {"role": "assistant", "content": "Hello"}, "finish_reason": "length"}
+				],
+				"usage": {"prompt_tokens": 10, "completion_tokens": 2, "total_tokens": 12}
+			}`),
+		},
+	}
+
+	response, err := client.GetCompletionResponse(context.Background(), &CompletionRequestPayload{
+		Model:    "gpt-4o-mini",
+		Messages: []Message{{Role: MessageRoleUser, Content: "Hi"}},
+		Tools:    []ToolDefinition{NewToolDefinition(&FunctionDefinition{Name: "echo", Fn: func(string) string { return "{}" }})},
+		N:        Ptr(2),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if response.Id != "chatcmpl-2" || response.SystemFingerprint != "fp_1" {
+		t.Errorf("expected the response to the final request, got %+v", response)
+	}
+	if len(response.Choices) != 2 || response.Choices[0].FinishReason != FinishReasonStop || response.Choices[1].FinishReason != FinishReasonLength {
+		t.Errorf("expected both choices with their finish reasons, got %+v", response.Choices)
+	}
+	if response.Usage == nil || response.Usage.TotalTokens != 12 {
+		t.Errorf("expected the usage, got %+v", response.Usage)
+	}
+}
+
+func TestGetCompletionResponse_TotalUsage(t *testing.T) {
+	client := NewTestClient(
+		ScriptedTurn{
+			ToolCalls: []ToolCall{{Id: "call_1", Type: "function", Function: FunctionCall{Name: "echo", Arguments: `{}`}}},
+			Usage: &LLMUsage{
+				PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15,
+				PromptTokensDetails:     &PromptTokensDetails{CachedTokens: 4},
+				CompletionTokensDetails: &CompletionTokensDetails{ReasoningTokens: 2},
+			},
+		},
+		ScriptedTurn{
+			Content: "done",
+			Usage: &LLMUsage{
+				PromptTokens: 20, CompletionTokens: 3, TotalTokens: 23,
+				PromptTokensDetails: &PromptTokensDetails{CachedTokens: 8},
+			},
+		},
+	)
+
+	response, err := client.GetCompletionResponse(context.Background(), &CompletionRequestPayload{
+		Model:    "gpt-4o-mini",
+		Messages: []Message{{Role: MessageRoleUser, Content: "Hi"}},
+		Tools:    []ToolDefinition{NewToolDefinition(&FunctionDefinition{Name: "echo", Fn: func(string) string { return "{}" }})},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if response.Usage.TotalTokens != 23 {
+		t.Errorf("expected the usage of the final request, got %+v", response.Usage)
+	}
+	if total := response.TotalUsage; total == nil || total.PromptTokens != 30 || total.CompletionTokens != 8 || total.TotalTokens != 38 {
+		t.Errorf("expected the usage of both requests, got %+v", total)
+	}
+	if details := response.TotalUsage.PromptTokensDetails; details == nil || details.CachedTokens != 12 {
+		t.Errorf("expected the cached tokens of both requests, got %+v", details)
+	}
+	if details := response.TotalUsage.CompletionTokensDetails; details == nil || details.ReasoningTokens != 2 {
+		t.Errorf("expected the reasoning tokens of the first request, got %+v", details)
+	}
+}
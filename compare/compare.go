@@ -0,0 +1,118 @@
+// Package compare measures how close two completions for the same input are,
+// such as the primary and shadow replies recorded by a Shadow or the outputs
+// of the variants of an experiment.
+package compare
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	openaiclient "github.com/raphael-foliveira/openai-client"
+)
+
+type (
+	// Embedder embeds a text. *openaiclient.OpenAI implements it.
+	Embedder = openaiclient.EmbeddingClient
+
+	// Comparer computes the metrics it is configured for. Token overlap is
+	// always computed; the embedding similarity needs an Embedder and the
+	// judge scores a Judge.
+	Comparer struct {
+		Embedder       Embedder
+		EmbeddingModel string
+		Judge          openaiclient.Judge
+	}
+
+	Result struct {
+		// TokenOverlap is the Jaccard similarity of the sets of words of
+		// the two outputs, from 0 to 1.
+		TokenOverlap float64
+		// Cosine is the cosine similarity of the embeddings of the outputs.
+		Cosine   float64
+		Embedded bool
+		// ScoreA and ScoreB are the judge scores of each output.
+		ScoreA float64
+		ScoreB float64
+		Scored bool
+	}
+)
+
+// Compare measures the similarity of outputs a and b to input.
+func (c *Comparer) Compare(ctx context.Context, input, a, b string) (*Result, error) {
+	result := &Result{TokenOverlap: TokenOverlap(a, b)}
+
+	if c.Embedder != nil {
+		embeddingA, err := c.Embedder.GetEmbeddingContext(ctx, openaiclient.GetEmbeddingPayload{Model: c.EmbeddingModel, Input: a})
+		if err != nil {
+			return nil, err
+		}
+		embeddingB, err := c.Embedder.GetEmbeddingContext(ctx, openaiclient.GetEmbeddingPayload{Model: c.EmbeddingModel, Input: b})
+		if err != nil {
+			return nil, err
+		}
+		result.Cosine, result.Embedded = Cosine(embeddingA, embeddingB), true
+	}
+
+	if c.Judge != nil {
+		var err error
+		if result.ScoreA, err = c.Judge(ctx, input, a); err != nil {
+			return nil, err
+		}
+		if result.ScoreB, err = c.Judge(ctx, input, b); err != nil {
+			return nil, err
+		}
+		result.Scored = true
+	}
+	return result, nil
+}
+
+// CompareShadow compares the primary and shadow replies of a shadowed
+// completion, using the last user message as the input.
+func (c *Comparer) CompareShadow(ctx context.Context, comparison openaiclient.ShadowComparison) (*Result, error) {
+	if comparison.Primary == nil || comparison.Shadow == nil {
+		return nil, openaiclient.NewInvalidRequestError("the comparison is missing a reply")
+	}
+
+	var input string
+	for i := len(comparison.Input) - 1; i >= 0; i-- {
+		if comparison.Input[i].Role == openaiclient.MessageRoleUser {
+			input = comparison.Input[i].Content
+			break
+		}
+	}
+	return c.Compare(ctx, input, comparison.Primary.Content, comparison.Shadow.Content)
+}
+
+// TokenOverlap returns the Jaccard similarity of the case-insensitive sets of
+// words of a and b. Two empty texts are identical.
+func TokenOverlap(a, b string) float64 {
+	tokensA, tokensB := tokenSet(a), tokenSet(b)
+	if len(tokensA) == 0 && len(tokensB) == 0 {
+		return 1
+	}
+
+	shared := 0
+	for token := range tokensA {
+		if tokensB[token] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(tokensA)+len(tokensB)-shared)
+}
+
+// Cosine returns the cosine similarity of a and b, see
+// openaiclient.CosineSimilarity.
+func Cosine(a, b []float64) float64 {
+	return openaiclient.CosineSimilarity(a, b)
+}
+
+func tokenSet(text string) map[string]bool {
+	tokens := map[string]bool{}
+	for _, token := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		tokens[token] = true
+	}
+	return tokens
+}
@@ -0,0 +1,80 @@
+package compare
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	openaiclient "github.com/raphael-foliveira/openai-client"
+)
+
+type fakeEmbedder map[string][]float64
+
+func (f fakeEmbedder) GetEmbeddingContext(ctx context.Context, payload openaiclient.GetEmbeddingPayload) ([]float64, error) {
+	return f[payload.Input], nil
+}
+
+func (f fakeEmbedder) GetEmbeddings(ctx context.Context, payload openaiclient.GetEmbeddingPayload) ([][]float64, error) {
+	embeddings := make([][]float64, len(payload.Inputs))
+	for i, input := range payload.Inputs {
+		embeddings[i] = f[input]
+	}
+	return embeddings, nil
+}
+
+func TestTokenOverlap(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want float64
+	}{
+		{"The cat sat.", "the CAT sat", 1},
+		{"the cat", "the dog", 1.0 / 3},
+		{"cat", "dog", 0},
+		{"", "", 1},
+	}
+	for _, c := range cases {
+		if got := TokenOverlap(c.a, c.b); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("TokenOverlap(%q, %q): expected %v, got %v", c.a, c.b, c.want, got)
+		}
+	}
+}
+
+func TestCosine(t *testing.T) {
+	if got := Cosine([]float64{1, 0}, []float64{1, 0}); got != 1 {
+		t.Errorf("expected 1 for identical vectors, got %v", got)
+	}
+	if got := Cosine([]float64{1, 0}, []float64{0, 1}); got != 0 {
+		t.Errorf("expected 0 for orthogonal vectors, got %v", got)
+	}
+	if got := Cosine([]float64{1}, []float64{1, 0}); got != 0 {
+		t.Errorf("expected 0 for mismatched lengths, got %v", got)
+	}
+}
+
+func TestComparer_CompareShadow(t *testing.T) {
+	comparer := &Comparer{
+		Embedder: fakeEmbedder{"yes": {1, 0}, "yes indeed": {1, 1}},
+		Judge: func(ctx context.Context, input, output string) (float64, error) {
+			if input != "Really?" {
+				t.Errorf("expected the last user message as input, got %q", input)
+			}
+			return float64(len(strings.Fields(output))), nil
+		},
+	}
+
+	result, err := comparer.CompareShadow(context.Background(), openaiclient.ShadowComparison{
+		Input:   []openaiclient.Message{{Role: openaiclient.MessageRoleUser, Content: "Really?"}},
+		Primary: &openaiclient.Message{Content: "yes"},
+		Shadow:  &openaiclient.Message{Content: "yes indeed"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.TokenOverlap != 0.5 || !result.Embedded || math.Abs(result.Cosine-1/math.Sqrt2) > 1e-9 {
+		t.Errorf("unexpected similarity %+v", result)
+	}
+	if !result.Scored || result.ScoreA != 1 || result.ScoreB != 2 {
+		t.Errorf("unexpected scores %+v", result)
+	}
+}
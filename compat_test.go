@@ -0,0 +1,151 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// The fixtures in testdata/compat follow the response shapes of the providers
+// the client is used against. Every provider-specific field they carry must
+// keep decoding into the shared response types.
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "compat", name))
+	if err != nil {
+		t.Fatalf("error reading fixture: %v", err)
+	}
+	return string(data)
+}
+
+func TestCompat_ChatCompletions(t *testing.T) {
+	tests := []struct {
+		fixture string
+		model   string
+		content string
+		usage   LLMUsage
+	}{
+		{
+			fixture: "openai_chat_completion.json",
+			model:   "gpt-4o-mini-2024-07-18",
+			content: "Hello! How can I assist you today?",
+			usage:   LLMUsage{PromptTokens: 19, CompletionTokens: 10, TotalTokens: 29},
+		},
+		{
+			fixture: "azure_chat_completion.json",
+			model:   "gpt-4o-2024-05-13",
+			content: "Hello! How can I help you today?",
+			usage:   LLMUsage{PromptTokens: 18, CompletionTokens: 9, TotalTokens: 27},
+		},
+		{
+			fixture: "openrouter_chat_completion.json",
+			model:   "openai/gpt-4o-mini",
+			content: "Hello! How can I help you today?",
+			usage:   LLMUsage{PromptTokens: 10, CompletionTokens: 10, TotalTokens: 20},
+		},
+		{
+			fixture: "groq_chat_completion.json",
+			model:   "llama-3.3-70b-versatile",
+			content: "Hello! How can I help you today?",
+			usage:   LLMUsage{PromptTokens: 18, CompletionTokens: 10, TotalTokens: 28},
+		},
+		{
+			fixture: "ollama_chat_completion.json",
+			model:   "llama3.2",
+			content: "Hello! How can I help you today?",
+			usage:   LLMUsage{PromptTokens: 26, CompletionTokens: 10, TotalTokens: 36},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			body := readFixture(t, tt.fixture)
+
+			var response CompletionResponse
+			if err := json.Unmarshal([]byte(body), &response); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if response.Id == "" || response.Object != "chat.completion" || response.Created == 0 {
+				t.Errorf("unexpected metadata %+v", response)
+			}
+			if response.Model != tt.model {
+				t.Errorf("expected model %q, got %q", tt.model, response.Model)
+			}
+			if response.Usage == nil {
+				t.Fatal("expected usage to be decoded")
+			}
+			usage := *response.Usage
+			usage.PromptTokensDetails, usage.CompletionTokensDetails = nil, nil
+			if usage != tt.usage {
+				t.Errorf("expected usage %+v, got %+v", tt.usage, usage)
+			}
+
+			client := createClient(t)
+			client.client = &FakeClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					return fakeResponse(http.StatusOK, body), nil
+				},
+			}
+			message, err := client.GetCompletion(&CompletionRequestPayload{
+				Model:    "test-model",
+				Messages: []Message{{Role: MessageRoleUser, Content: "Hello"}},
+			})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if message.Role != MessageRoleAssistant || message.Content != tt.content {
+				t.Errorf("unexpected message %+v", message)
+			}
+		})
+	}
+}
+
+func TestCompat_ToolCalls(t *testing.T) {
+	var response CompletionResponse
+	if err := json.Unmarshal([]byte(readFixture(t, "openai_tool_calls.json")), &response); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	message := response.Choices[0].Message
+	if message.Content != "" || len(message.ToolCalls) != 1 {
+		t.Fatalf("unexpected message %+v", message)
+	}
+	call := message.ToolCalls[0]
+	if call.Id != "call_abc123" || call.Type != "function" || call.Function.Name != "get_current_weather" {
+		t.Errorf("unexpected tool call %+v", call)
+	}
+
+	var arguments map[string]string
+	if err := json.Unmarshal([]byte(call.Function.Arguments), &arguments); err != nil || arguments["location"] != "Boston, MA" {
+		t.Errorf("unexpected arguments %q", call.Function.Arguments)
+	}
+}
+
+func TestCompat_Embeddings(t *testing.T) {
+	for _, fixture := range []string{"openai_embedding.json", "ollama_embedding.json"} {
+		t.Run(fixture, func(t *testing.T) {
+			body := readFixture(t, fixture)
+			client := createClient(t)
+			client.client = &FakeClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					return fakeResponse(http.StatusOK, body), nil
+				},
+			}
+
+			response, err := client.GetEmbeddingResponse(context.Background(), GetEmbeddingPayload{Model: "test-model", Input: "Hello"})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(response.Data[0].Embedding) != 3 {
+				t.Errorf("expected 3 dimensions, got %d", len(response.Data[0].Embedding))
+			}
+			if response.Usage == nil || response.Usage.PromptTokens == 0 {
+				t.Errorf("expected usage to be decoded, got %+v", response.Usage)
+			}
+		})
+	}
+}
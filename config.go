@@ -0,0 +1,140 @@
+package openaiclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBaseUrl    = "https://api.openai.com"
+	defaultMaxRetries = 2
+)
+
+// Config is the configuration a client is created from, see NewFromConfig.
+type Config struct {
+	APIKey  string
+	BaseURL string
+	// Model is the client's DefaultModel.
+	Model string
+	// OrganizationId and ProjectId, when set, are sent in the
+	// OpenAI-Organization and OpenAI-Project headers, for keys with access
+	// to several of them.
+	OrganizationId string
+	ProjectId      string
+	AdminKey       string
+	// Timeout bounds every HTTP request, retries excluded. Zero means no
+	// timeout.
+	Timeout time.Duration
+	// MaxRetries overrides the default of 2 retries when set.
+	MaxRetries *int
+}
+
+// ConfigError lists every missing or invalid setting of a configuration.
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid configuration: %s", strings.Join(e.Problems, "; "))
+}
+
+// LoadConfigFromEnv reads the configuration from OPENAI_API_KEY,
+// OPENAI_BASE_URL, OPENAI_MODEL, OPENAI_ORG_ID, OPENAI_PROJECT_ID,
+// OPENAI_ADMIN_KEY, OPENAI_TIMEOUT (a duration such as "30s", or a number of
+// seconds) and OPENAI_MAX_RETRIES. It returns a *ConfigError listing every
+// missing or invalid variable.
+func LoadConfigFromEnv() (*Config, error) {
+	config, problems := configFromEnv()
+	if problems = append(config.problems(), problems...); len(problems) > 0 {
+		return nil, &ConfigError{Problems: problems}
+	}
+	return config, nil
+}
+
+// configFromEnv reads the environment, returning the problems of the
+// variables that failed to parse.
+func configFromEnv() (*Config, []string) {
+	config := &Config{
+		APIKey:         os.Getenv("OPENAI_API_KEY"),
+		BaseURL:        os.Getenv("OPENAI_BASE_URL"),
+		Model:          os.Getenv("OPENAI_MODEL"),
+		OrganizationId: os.Getenv("OPENAI_ORG_ID"),
+		ProjectId:      os.Getenv("OPENAI_PROJECT_ID"),
+		AdminKey:       os.Getenv("OPENAI_ADMIN_KEY"),
+	}
+
+	var problems []string
+	if value := os.Getenv("OPENAI_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if seconds, atoiErr := strconv.Atoi(value); atoiErr == nil {
+			timeout, err = time.Duration(seconds)*time.Second, nil
+		}
+		if err != nil || timeout < 0 {
+			problems = append(problems, fmt.Sprintf("OPENAI_TIMEOUT %q is not a duration", value))
+		}
+		config.Timeout = timeout
+	}
+	if value := os.Getenv("OPENAI_MAX_RETRIES"); value != "" {
+		retries, err := strconv.Atoi(value)
+		if err != nil || retries < 0 {
+			problems = append(problems, fmt.Sprintf("OPENAI_MAX_RETRIES %q is not a non-negative integer", value))
+		}
+		config.MaxRetries = &retries
+	}
+	return config, problems
+}
+
+// problems returns the missing and invalid settings of the configuration.
+func (c *Config) problems() []string {
+	var problems []string
+	if c.APIKey == "" {
+		problems = append(problems, "OPENAI_API_KEY is not set")
+	}
+	if c.BaseURL != "" {
+		parsed, err := url.Parse(c.BaseURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			problems = append(problems, fmt.Sprintf("OPENAI_BASE_URL %q is not an http(s) URL", c.BaseURL))
+		}
+	}
+	return problems
+}
+
+// NewFromConfig returns a client of the configuration, e.g. one of
+// LoadConfigFromEnv. The base URL defaults to https://api.openai.com.
+func NewFromConfig(config *Config) (*OpenAI, error) {
+	if problems := config.problems(); len(problems) > 0 {
+		return nil, &ConfigError{Problems: problems}
+	}
+
+	baseUrl := config.BaseURL
+	if baseUrl == "" {
+		baseUrl = defaultBaseUrl
+	}
+	maxRetries := defaultMaxRetries
+	if config.MaxRetries != nil {
+		maxRetries = *config.MaxRetries
+	}
+	return &OpenAI{
+		baseUrl:          baseUrl,
+		client:           &http.Client{Timeout: config.Timeout},
+		key:              config.APIKey,
+		MaxIterations:    5,
+		DefaultModel:     config.Model,
+		MaxRetries:       maxRetries,
+		RetryBackoff:     500 * time.Millisecond,
+		AdminKey:         config.AdminKey,
+		Organization:     config.OrganizationId,
+		Project:          config.ProjectId,
+		Features:         DefaultFeatures,
+		ModelRegistry:    DefaultModelRegistry,
+		LogRedaction:     redactionFromEnv(),
+		RateLimitHeaders: rateLimitHeadersFor(baseUrl),
+		pacer:            &pacer{},
+		lifecycle:        &lifecycle{},
+	}, nil
+}
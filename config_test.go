@@ -0,0 +1,88 @@
+package openaiclient
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "env-key")
+	t.Setenv("OPENAI_BASE_URL", "https://gateway.example.com")
+	t.Setenv("OPENAI_MODEL", "gpt-4o")
+	t.Setenv("OPENAI_ORG_ID", "org-1")
+	t.Setenv("OPENAI_PROJECT_ID", "proj-1")
+	t.Setenv("OPENAI_TIMEOUT", "45")
+	t.Setenv("OPENAI_MAX_RETRIES", "0")
+
+	config, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if config.Timeout != 45*time.Second || config.MaxRetries == nil || *config.MaxRetries != 0 {
+		t.Errorf("unexpected config %+v", config)
+	}
+
+	client, err := NewFromConfig(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if client.DefaultModel != "gpt-4o" || client.MaxRetries != 0 || client.client.(*http.Client).Timeout != 45*time.Second {
+		t.Errorf("expected the configuration applied to the client, got %+v", client)
+	}
+
+	request, err := client.createAuthorizedRequest(t.Context(), http.MethodGet, modelsEndpoint, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if request.Header.Get("OpenAI-Organization") != "org-1" || request.Header.Get("OpenAI-Project") != "proj-1" {
+		t.Errorf("expected the organization and project headers, got %v", request.Header)
+	}
+
+	var upload http.Header
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			upload = req.Header
+			return fakeResponse(http.StatusOK, `{"id":"file-1"}`), nil
+		},
+	}
+	if _, err := client.UploadFile(t.Context(), "data.jsonl", FilePurposeBatch, strings.NewReader("{}")); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if upload.Get("OpenAI-Organization") != "org-1" || upload.Get("OpenAI-Project") != "proj-1" {
+		t.Errorf("expected the organization and project headers on uploads, got %v", upload)
+	}
+}
+
+func TestLoadConfigFromEnv_ListsEveryProblem(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENAI_BASE_URL", "api.openai.com")
+	t.Setenv("OPENAI_TIMEOUT", "soon")
+	t.Setenv("OPENAI_MAX_RETRIES", "-1")
+
+	_, err := LoadConfigFromEnv()
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		t.Fatalf("expected *ConfigError, got %v", err)
+	}
+	want := []string{"OPENAI_API_KEY", "OPENAI_BASE_URL", "OPENAI_TIMEOUT", "OPENAI_MAX_RETRIES"}
+	if len(configErr.Problems) != len(want) {
+		t.Fatalf("expected %d problems, got %q", len(want), configErr.Problems)
+	}
+	for i, variable := range want {
+		if !strings.HasPrefix(configErr.Problems[i], variable) {
+			t.Errorf("expected a problem with %s, got %q", variable, configErr.Problems[i])
+		}
+	}
+}
+
+func TestNew_InvalidEnv(t *testing.T) {
+	t.Setenv("OPENAI_TIMEOUT", "soon")
+
+	var configErr *ConfigError
+	if _, err := New("http://example.com", "test-key"); !errors.As(err, &configErr) {
+		t.Errorf("expected *ConfigError, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package openaiclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+const ErrCodeContentFilter = "content_filter"
+
+type (
+	ContentFilterResult struct {
+		Filtered bool   `json:"filtered"`
+		Severity string `json:"severity,omitempty"`
+		Detected *bool  `json:"detected,omitempty"`
+	}
+
+	// ContentFilterError is returned when Azure OpenAI or a compatible gateway
+	// rejects a request under its content policy. Results holds the verdict
+	// for each category (hate, self_harm, sexual, violence, jailbreak, ...).
+	ContentFilterError struct {
+		OpenAIError
+		InnerCode string                         `json:"inner_code,omitempty"`
+		Results   map[string]ContentFilterResult `json:"results,omitempty"`
+	}
+
+	contentFilterBody struct {
+		Message    string `json:"message"`
+		Code       any    `json:"code"`
+		Param      string `json:"param"`
+		InnerError *struct {
+			Code                string                         `json:"code"`
+			ContentFilterResult map[string]ContentFilterResult `json:"content_filter_result"`
+		} `json:"innererror"`
+	}
+)
+
+func (e *ContentFilterError) Error() string {
+	categories := e.FilteredCategories()
+	if len(categories) == 0 {
+		return e.OpenAIError.Error()
+	}
+
+	filtered := make([]string, len(categories))
+	for i, category := range categories {
+		filtered[i] = category
+		if severity := e.Results[category].Severity; severity != "" {
+			filtered[i] += "=" + severity
+		}
+	}
+	return fmt.Sprintf("%s [filtered: %s]", e.OpenAIError.Error(), strings.Join(filtered, ", "))
+}
+
+func (e *ContentFilterError) Unwrap() error {
+	return &e.OpenAIError
+}
+
+// FilteredCategories returns the sorted names of the categories that caused
+// the request to be filtered.
+func (e *ContentFilterError) FilteredCategories() []string {
+	var categories []string
+	for _, category := range slices.Sorted(maps.Keys(e.Results)) {
+		if e.Results[category].Filtered {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+// parseContentFilterError recognizes content filter violations in both the
+// nested {"error": {...}} and the flat error shapes. It returns nil when the
+// body is not a content filter violation.
+func parseContentFilterError(statusCode int, body []byte) *ContentFilterError {
+	var envelope struct {
+		Error *contentFilterBody `json:"error"`
+		contentFilterBody
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil
+	}
+
+	filterBody := &envelope.contentFilterBody
+	if envelope.Error != nil {
+		filterBody = envelope.Error
+	}
+
+	code, _ := filterBody.Code.(string)
+	hasResults := filterBody.InnerError != nil && filterBody.InnerError.ContentFilterResult != nil
+	if code != ErrCodeContentFilter && !hasResults {
+		return nil
+	}
+
+	filterErr := &ContentFilterError{
+		OpenAIError: OpenAIError{
+			Type:       typeForStatus(statusCode),
+			Message:    filterBody.Message,
+			Code:       ErrCodeContentFilter,
+			Param:      filterBody.Param,
+			StatusCode: statusCode,
+		},
+	}
+	if filterBody.InnerError != nil {
+		filterErr.InnerCode = filterBody.InnerError.Code
+		filterErr.Results = filterBody.InnerError.ContentFilterResult
+	}
+	return filterErr
+}
+
+func IsContentFilterError(err error) bool {
+	_, ok := AsContentFilterError(err)
+	return ok
+}
+
+func AsContentFilterError(err error) (*ContentFilterError, bool) {
+	var filterErr *ContentFilterError
+	if errors.As(err, &filterErr) {
+		return filterErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,68 @@
+package openaiclient
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewOpenAIError_ContentFilter(t *testing.T) {
+	body := []byte(`{
+		"error": {
+			"message": "The response was filtered due to the prompt triggering Azure OpenAI's content management policy.",
+			"type": null,
+			"param": "prompt",
+			"code": "content_filter",
+			"status": 400,
+			"innererror": {
+				"code": "ResponsibleAIPolicyViolation",
+				"content_filter_result": {
+					"hate": {"filtered": false, "severity": "safe"},
+					"jailbreak": {"filtered": true, "detected": true},
+					"self_harm": {"filtered": false, "severity": "safe"},
+					"sexual": {"filtered": true, "severity": "medium"},
+					"violence": {"filtered": false, "severity": "low"}
+				}
+			}
+		}
+	}`)
+
+	err := NewOpenAIError(http.StatusBadRequest, body)
+
+	filterErr, ok := AsContentFilterError(fmt.Errorf("wrapped: %w", err))
+	if !ok {
+		t.Fatalf("expected *ContentFilterError, got %T", err)
+	}
+	if filterErr.Type != ErrTypeInvalidRequest || filterErr.Code != ErrCodeContentFilter {
+		t.Errorf("unexpected type %q and code %q", filterErr.Type, filterErr.Code)
+	}
+	if filterErr.InnerCode != "ResponsibleAIPolicyViolation" {
+		t.Errorf("expected inner code 'ResponsibleAIPolicyViolation', got '%s'", filterErr.InnerCode)
+	}
+	if severity := filterErr.Results["violence"].Severity; severity != "low" {
+		t.Errorf("expected violence severity 'low', got '%s'", severity)
+	}
+
+	categories := filterErr.FilteredCategories()
+	if len(categories) != 2 || categories[0] != "jailbreak" || categories[1] != "sexual" {
+		t.Errorf("unexpected filtered categories %v", categories)
+	}
+	if !strings.Contains(err.Error(), "sexual=medium") {
+		t.Errorf("expected error message to mention filtered categories, got %q", err.Error())
+	}
+
+	if !IsOpenAIError(err) {
+		t.Errorf("expected content filter error to be an OpenAI error")
+	}
+	if GetOpenAIErrorType(err) != ErrTypeInvalidRequest {
+		t.Errorf("expected type %q, got %q", ErrTypeInvalidRequest, GetOpenAIErrorType(err))
+	}
+}
+
+func TestNewOpenAIError_NotContentFilter(t *testing.T) {
+	err := NewOpenAIError(http.StatusBadRequest, []byte(`{"type": "invalid_request_error", "message": "Invalid model", "code": "invalid_model"}`))
+	if IsContentFilterError(err) {
+		t.Errorf("expected regular OpenAI error, got %T", err)
+	}
+}
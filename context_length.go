@@ -0,0 +1,146 @@
+package openaiclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+)
+
+const ErrCodeContextLengthExceeded = "context_length_exceeded"
+
+var (
+	maxContextPattern       = regexp.MustCompile(`maximum context length is (\d+) tokens`)
+	requestedTokensPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`resulted in (\d+) tokens`),
+		regexp.MustCompile(`requested (\d+) tokens`),
+	}
+)
+
+// ContextLengthExceededError is returned when the request does not fit the
+// model's context window. MaxTokens and RequestedTokens are zero when the
+// provider's message does not report them.
+type ContextLengthExceededError struct {
+	OpenAIError
+	MaxTokens       int `json:"max_tokens,omitempty"`
+	RequestedTokens int `json:"requested_tokens,omitempty"`
+}
+
+func (e *ContextLengthExceededError) Error() string {
+	if e.MaxTokens == 0 {
+		return e.OpenAIError.Error()
+	}
+	return fmt.Sprintf("%s (max: %d, requested: %d)", e.OpenAIError.Error(), e.MaxTokens, e.RequestedTokens)
+}
+
+func (e *ContextLengthExceededError) Unwrap() error {
+	return &e.OpenAIError
+}
+
+func newContextLengthExceededError(apiErr *OpenAIError) *ContextLengthExceededError {
+	ctxErr := &ContextLengthExceededError{OpenAIError: *apiErr}
+	if match := maxContextPattern.FindStringSubmatch(apiErr.Message); match != nil {
+		ctxErr.MaxTokens, _ = strconv.Atoi(match[1])
+	}
+	for _, pattern := range requestedTokensPatterns {
+		if match := pattern.FindStringSubmatch(apiErr.Message); match != nil {
+			ctxErr.RequestedTokens, _ = strconv.Atoi(match[1])
+			break
+		}
+	}
+	return ctxErr
+}
+
+func AsContextLengthExceededError(err error) (*ContextLengthExceededError, bool) {
+	var ctxErr *ContextLengthExceededError
+	if errors.As(err, &ctxErr) {
+		return ctxErr, true
+	}
+	return nil, false
+}
+
+// TrimPolicy shortens a conversation that exceeded the model's context
+// window. It returns false when the messages cannot be trimmed any further.
+type TrimPolicy func(messages []Message, err *ContextLengthExceededError) ([]Message, bool)
+
+// TrimOldestMessages drops the oldest messages after the leading system and
+// developer messages until the estimated size fits the reported limit. Tool
+// results are never left without the assistant message that requested them,
+// and the latest message is always kept.
+func TrimOldestMessages(messages []Message, err *ContextLengthExceededError) ([]Message, bool) {
+	head := 0
+	for head < len(messages) && isInstruction(messages[head]) {
+		head++
+	}
+	if len(messages)-head <= 1 {
+		return messages, false
+	}
+
+	excess := estimateTokens(messages) / 4
+	if err.MaxTokens > 0 && err.RequestedTokens > err.MaxTokens {
+		excess = estimateTokens(messages) * (err.RequestedTokens - err.MaxTokens) / err.RequestedTokens
+	}
+
+	cut := head
+	removed := 0
+	for cut < len(messages)-1 && (removed <= excess || messages[cut].Role == MessageRoleTool) {
+		removed += estimateMessageTokens(messages[cut])
+		cut++
+	}
+	// The latest message is a tool result: keep the assistant message that
+	// requested it, along with its other results.
+	for cut > head && messages[cut].Role == MessageRoleTool {
+		cut--
+	}
+	if cut == head {
+		return messages, false
+	}
+
+	trimmed := make([]Message, 0, head+len(messages)-cut)
+	trimmed = append(trimmed, messages[:head]...)
+	trimmed = append(trimmed, messages[cut:]...)
+	return trimmed, true
+}
+
+func (o *OpenAI) trimAfterContextLengthExceeded(ctx context.Context, payload *CompletionRequestPayload, err error) bool {
+	ctxErr, ok := AsContextLengthExceededError(err)
+	if !ok || o.TrimPolicy == nil {
+		return false
+	}
+
+	trimmed, ok := o.TrimPolicy(payload.Messages, ctxErr)
+	if !ok {
+		return false
+	}
+
+	o.warn(ctx, WarningMessagesTrimmed, "trimmed messages after context length exceeded",
+		slog.Int("before", len(payload.Messages)),
+		slog.Int("after", len(trimmed)),
+	)
+	payload.Messages = trimmed
+	return true
+}
+
+func isInstruction(message Message) bool {
+	return message.Role == MessageRoleSystem || message.Role == MessageRoleDeveloper
+}
+
+// estimateMessageTokens approximates the token count of a message using the
+// common four characters per token rule of thumb.
+func estimateMessageTokens(message Message) int {
+	size := len(message.Content)
+	for _, toolCall := range message.ToolCalls {
+		size += len(toolCall.Function.Name) + len(toolCall.Function.Arguments)
+	}
+	return size/4 + 4
+}
+
+func estimateTokens(messages []Message) int {
+	total := 0
+	for _, message := range messages {
+		total += estimateMessageTokens(message)
+	}
+	return total
+}
@@ -0,0 +1,151 @@
+package openaiclient
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const contextLengthBody = `{
+	"type": "invalid_request_error",
+	"message": "This model's maximum context length is 100 tokens. However, your messages resulted in 150 tokens. Please reduce the length of the messages.",
+	"param": "messages",
+	"code": "context_length_exceeded"
+}`
+
+func TestNewOpenAIError_ContextLengthExceeded(t *testing.T) {
+	err := NewOpenAIError(http.StatusBadRequest, []byte(contextLengthBody))
+
+	ctxErr, ok := AsContextLengthExceededError(err)
+	if !ok {
+		t.Fatalf("expected *ContextLengthExceededError, got %T", err)
+	}
+	if ctxErr.MaxTokens != 100 || ctxErr.RequestedTokens != 150 {
+		t.Errorf("expected max 100 and requested 150, got max %d and requested %d", ctxErr.MaxTokens, ctxErr.RequestedTokens)
+	}
+	if GetOpenAIErrorType(err) != ErrTypeInvalidRequest {
+		t.Errorf("expected type %q, got %q", ErrTypeInvalidRequest, GetOpenAIErrorType(err))
+	}
+}
+
+func TestTrimOldestMessages(t *testing.T) {
+	long := strings.Repeat("x", 200)
+	messages := []Message{
+		{Role: MessageRoleSystem, Content: "You are helpful."},
+		{Role: MessageRoleUser, Content: long},
+		{Role: MessageRoleAssistant, ToolCalls: []ToolCall{{Id: "call_1", Function: FunctionCall{Name: "echo"}}}},
+		{Role: MessageRoleTool, Content: long, ToolCallId: "call_1"},
+		{Role: MessageRoleAssistant, Content: "done"},
+		{Role: MessageRoleUser, Content: "latest question"},
+	}
+
+	trimmed, ok := TrimOldestMessages(messages, &ContextLengthExceededError{MaxTokens: 100, RequestedTokens: 150})
+	if !ok {
+		t.Fatal("expected messages to be trimmed")
+	}
+	if trimmed[0].Role != MessageRoleSystem {
+		t.Errorf("expected system message to be kept, got %q", trimmed[0].Role)
+	}
+	if trimmed[len(trimmed)-1].Content != "latest question" {
+		t.Errorf("expected latest message to be kept, got %q", trimmed[len(trimmed)-1].Content)
+	}
+	if len(trimmed) > 1 && trimmed[1].Role == MessageRoleTool {
+		t.Errorf("expected no orphaned tool message after trimming, got %+v", trimmed)
+	}
+	if len(trimmed) >= len(messages) {
+		t.Errorf("expected fewer messages, got %d", len(trimmed))
+	}
+
+	if _, ok := TrimOldestMessages(messages[:2], &ContextLengthExceededError{}); ok {
+		t.Error("expected a single non-system message not to be trimmed")
+	}
+}
+
+func TestTrimOldestMessages_ToolResultTail(t *testing.T) {
+	long := strings.Repeat("x", 400)
+	messages := []Message{
+		{Role: MessageRoleSystem, Content: "You are helpful."},
+		{Role: MessageRoleUser, Content: long},
+		{Role: MessageRoleAssistant, ToolCalls: []ToolCall{{Id: "call_1", Function: FunctionCall{Name: "echo"}}, {Id: "call_2", Function: FunctionCall{Name: "echo"}}}},
+		{Role: MessageRoleTool, Content: "a", ToolCallId: "call_1"},
+		{Role: MessageRoleTool, Content: "b", ToolCallId: "call_2"},
+	}
+
+	trimmed, ok := TrimOldestMessages(messages, &ContextLengthExceededError{MaxTokens: 10, RequestedTokens: 1000})
+	if !ok {
+		t.Fatal("expected messages to be trimmed")
+	}
+	roles := make([]MessageRole, 0, len(trimmed))
+	for _, message := range trimmed {
+		roles = append(roles, message.Role)
+	}
+	expected := []MessageRole{MessageRoleSystem, MessageRoleAssistant, MessageRoleTool, MessageRoleTool}
+	if !slices.Equal(roles, expected) {
+		t.Errorf("expected the tool results to keep their assistant message, got %v", roles)
+	}
+
+	if _, ok := TrimOldestMessages(trimmed, &ContextLengthExceededError{MaxTokens: 10, RequestedTokens: 1000}); ok {
+		t.Error("expected a tool call and its results not to be trimmed further")
+	}
+}
+
+func TestGetCompletion_TrimsAndRetries(t *testing.T) {
+	seqClient := &SequentialFakeClient{
+		Responses: []*http.Response{
+			fakeResponse(http.StatusBadRequest, contextLengthBody),
+			fakeResponse(http.StatusOK, completionBody),
+		},
+	}
+
+	client := createClient(t)
+	client.client = seqClient
+	client.TrimPolicy = TrimOldestMessages
+
+	payload := &CompletionRequestPayload{
+		Model: "test-model",
+		Messages: []Message{
+			{Role: MessageRoleUser, Content: strings.Repeat("x", 400)},
+			{Role: MessageRoleAssistant, Content: "ok"},
+			{Role: MessageRoleUser, Content: "Hi"},
+		},
+	}
+
+	result, err := client.GetCompletion(payload)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Content != "Hello world" {
+		t.Errorf("expected 'Hello world', got '%s'", result.Content)
+	}
+	if seqClient.CallCount != 2 {
+		t.Errorf("expected 2 calls, got %d", seqClient.CallCount)
+	}
+	if payload.Messages[0].Content == strings.Repeat("x", 400) {
+		t.Errorf("expected oldest message to be trimmed")
+	}
+}
+
+func TestGetCompletion_ContextLengthWithoutPolicy(t *testing.T) {
+	seqClient := &SequentialFakeClient{
+		Responses: []*http.Response{
+			fakeResponse(http.StatusBadRequest, contextLengthBody),
+			fakeResponse(http.StatusOK, completionBody),
+		},
+	}
+
+	client := createClient(t)
+	client.client = seqClient
+
+	payload := &CompletionRequestPayload{
+		Model:    "test-model",
+		Messages: []Message{{Role: MessageRoleUser, Content: "Hi"}},
+	}
+
+	if _, err := client.GetCompletion(payload); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if seqClient.CallCount != 1 {
+		t.Errorf("expected 1 call, got %d", seqClient.CallCount)
+	}
+}
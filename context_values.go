@@ -0,0 +1,103 @@
+package openaiclient
+
+import (
+	"context"
+	"slices"
+)
+
+type (
+	userIdKey         struct{}
+	conversationIdKey struct{}
+	tenantKey         struct{}
+	contextOptionsKey struct{}
+	endUserKey        struct{}
+)
+
+// ContextOption sets a default of the completion requests made with a
+// context, see WithContextOptions. Options should only fill the fields a
+// payload leaves unset, so explicit values keep precedence.
+type ContextOption func(payload *CompletionRequestPayload)
+
+// WithUserId returns a context carrying the id of the end user a request is
+// made on behalf of. The context of a completion request reaches the tools it
+// runs, so they can attribute their actions to the user. The id stays in the
+// application: it is never sent to the API, see WithEndUser.
+func WithUserId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, userIdKey{}, id)
+}
+
+// UserId returns the user id carried by ctx, if any.
+func UserId(ctx context.Context) string {
+	id, _ := ctx.Value(userIdKey{}).(string)
+	return id
+}
+
+// WithConversationId returns a context carrying the id of the conversation a
+// request belongs to.
+func WithConversationId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, conversationIdKey{}, id)
+}
+
+// ConversationId returns the conversation id carried by ctx, if any.
+func ConversationId(ctx context.Context) string {
+	id, _ := ctx.Value(conversationIdKey{}).(string)
+	return id
+}
+
+// WithTenant returns a context carrying the tenant a request is made for,
+// which labels its traffic in a TrafficTracker.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// Tenant returns the tenant carried by ctx, if any.
+func Tenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// WithEndUser returns a context whose completion and embedding requests
+// identify the end user they are made for, as OpenAI's abuse monitoring
+// recommends, unless their payload already does: it fills SafetyIdentifier on
+// completions and User on embeddings. Unlike the id of WithUserId, which only
+// tools see, the identifier is sent to the API, so it should not reveal the
+// user: a hash of their id or email is best. The two are independent, and
+// neither is derived from the other.
+func WithEndUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, endUserKey{}, user)
+}
+
+// EndUser returns the end user identifier carried by ctx, if any.
+func EndUser(ctx context.Context) string {
+	user, _ := ctx.Value(endUserKey{}).(string)
+	return user
+}
+
+// WithContextOptions returns a context whose completion requests get the
+// options applied before they are routed and sent, so defaults set by an
+// outer layer of an application reach the calls made deep inside it. Options
+// added by inner layers are applied first, so they take precedence over the
+// outer ones.
+func WithContextOptions(ctx context.Context, options ...ContextOption) context.Context {
+	existing, _ := ctx.Value(contextOptionsKey{}).([]ContextOption)
+	return context.WithValue(ctx, contextOptionsKey{}, append(slices.Clip(existing), options...))
+}
+
+// WithContextModel returns a context whose completion requests that do not
+// set a model use model, rather than the client's Router or DefaultModel.
+func WithContextModel(ctx context.Context, model string) context.Context {
+	return WithContextOptions(ctx, func(payload *CompletionRequestPayload) {
+		if payload.Model == "" {
+			payload.Model = model
+		}
+	})
+}
+
+// applyContextOptions applies the options of ctx to the payload, the
+// innermost first.
+func applyContextOptions(ctx context.Context, payload *CompletionRequestPayload) {
+	options, _ := ctx.Value(contextOptionsKey{}).([]ContextOption)
+	for _, option := range slices.Backward(options) {
+		option(payload)
+	}
+}
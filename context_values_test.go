@@ -0,0 +1,109 @@
+package openaiclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestGetCompletion_ContextReachesTools(t *testing.T) {
+	seqClient := &SequentialFakeClient{
+		Responses: []*http.Response{
+			fakeResponse(http.StatusOK, `{"choices":[{"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"whoami","arguments":"{}"}}]}}]}`),
+			fakeResponse(http.StatusOK, completionBody),
+		},
+	}
+
+	client := createClient(t)
+	client.client = seqClient
+
+	var userId, conversationId string
+	tool, err := ToolFromFunc("whoami", "", func(ctx context.Context, args struct{}) string {
+		userId, conversationId = UserId(ctx), ConversationId(ctx)
+		return "ok"
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ctx := WithConversationId(WithUserId(context.Background(), "user-1"), "conversation-1")
+	payload := &CompletionRequestPayload{
+		Model:    "test-model",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+		Tools:    []ToolDefinition{tool},
+	}
+	if _, err := client.GetCompletionContext(ctx, payload); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if userId != "user-1" || conversationId != "conversation-1" {
+		t.Errorf("expected the tool to see the request context, got %q and %q", userId, conversationId)
+	}
+}
+
+func TestContextValues_Missing(t *testing.T) {
+	if UserId(context.Background()) != "" || ConversationId(context.Background()) != "" {
+		t.Error("expected no values on an empty context")
+	}
+}
+
+func TestGetCompletion_ContextOptions(t *testing.T) {
+	client := NewTestClient(ScriptedTurn{Content: "a"}, ScriptedTurn{Content: "b"}, ScriptedTurn{Content: "c"})
+	store := true
+	ctx := WithContextModel(context.Background(), "outer-model")
+	ctx = WithContextOptions(ctx, func(payload *CompletionRequestPayload) {
+		if payload.Store == nil {
+			payload.Store = &store
+		}
+	})
+	inner := WithContextModel(ctx, "inner-model")
+
+	messages := []Message{{Role: MessageRoleUser, Content: "Hi"}}
+	client.GetCompletionContext(ctx, &CompletionRequestPayload{Messages: messages})
+	client.GetCompletionContext(inner, &CompletionRequestPayload{Messages: messages})
+	client.GetCompletionContext(inner, &CompletionRequestPayload{Model: "explicit-model", Messages: messages})
+
+	completions := client.Completions()
+	if len(completions) != 3 {
+		t.Fatalf("expected three requests, got %d", len(completions))
+	}
+	for i, expected := range []string{"outer-model", "inner-model", "explicit-model"} {
+		if completions[i].Model != expected {
+			t.Errorf("request %d: expected model %q, got %q", i, expected, completions[i].Model)
+		}
+	}
+	if !completions[1].stored() {
+		t.Error("expected the outer options to apply under inner ones")
+	}
+}
+
+func TestWithEndUser(t *testing.T) {
+	client := NewTestClient(
+		ScriptedTurn{Content: "a"},
+		ScriptedTurn{Content: "b"},
+		ScriptedTurn{Embedding: []float64{1}},
+		ScriptedTurn{Content: "c"},
+	)
+	ctx := WithEndUser(WithUserId(context.Background(), "user-1"), "user-hash")
+	messages := []Message{{Role: MessageRoleUser, Content: "Hi"}}
+
+	client.GetCompletionContext(ctx, &CompletionRequestPayload{Messages: messages})
+	client.GetCompletionContext(ctx, &CompletionRequestPayload{Messages: messages, User: "legacy-id"})
+	client.GetEmbeddingContext(ctx, GetEmbeddingPayload{Input: "Hi"})
+
+	completions := client.Completions()
+	if completions[0].SafetyIdentifier != "user-hash" {
+		t.Errorf("expected the end user as safety identifier, got %q", completions[0].SafetyIdentifier)
+	}
+	if completions[1].SafetyIdentifier != "" || completions[1].User != "legacy-id" {
+		t.Errorf("expected the payload's user to be kept, got %+v", completions[1])
+	}
+	if user := client.Embeddings()[0].User; user != "user-hash" {
+		t.Errorf("expected the end user on the embedding request, got %q", user)
+	}
+
+	client.GetCompletionContext(WithUserId(context.Background(), "user-1"), &CompletionRequestPayload{Messages: messages})
+	if completion := client.Completions()[2]; completion.SafetyIdentifier != "" || completion.User != "" {
+		t.Errorf("expected the user id not to be sent, got %+v", completion)
+	}
+}
@@ -0,0 +1,52 @@
+package openaiclient
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+// clientRequestIdHeader carries the correlation id to the API, which echoes it
+// in its own logs and support requests.
+const clientRequestIdHeader = "X-Client-Request-Id"
+
+type correlationIdKey struct{}
+
+// WithCorrelationId returns a context carrying the id that is attached to the
+// log records and requests of every call made with it. GetCompletion
+// generates one when the context has none, so all the iterations of an agent
+// run share it.
+func WithCorrelationId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIdKey{}, id)
+}
+
+// CorrelationId returns the correlation id carried by ctx, if any.
+func CorrelationId(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIdKey{}).(string)
+	return id
+}
+
+func ensureCorrelationId(ctx context.Context) context.Context {
+	if CorrelationId(ctx) != "" {
+		return ctx
+	}
+	return WithCorrelationId(ctx, newIdempotencyKey())
+}
+
+// setCorrelationHeader sends the correlation id of the request's context, if
+// any, to the API.
+func setCorrelationHeader(request *http.Request) {
+	if id := CorrelationId(request.Context()); id != "" {
+		request.Header.Set(clientRequestIdHeader, id)
+	}
+}
+
+// correlationAttr is the log attribute for the correlation id of ctx. It is
+// empty, and therefore omitted by handlers, when ctx carries none.
+func correlationAttr(ctx context.Context) slog.Attr {
+	id := CorrelationId(ctx)
+	if id == "" {
+		return slog.Attr{}
+	}
+	return slog.String("correlationId", id)
+}
@@ -0,0 +1,83 @@
+package openaiclient
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const toolCallBody = `{"choices":[{"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"echo","arguments":"hi"}}]}}]}`
+
+func TestGetCompletion_CorrelationIdSharedAcrossIterations(t *testing.T) {
+	var ids []string
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			ids = append(ids, req.Header.Get(clientRequestIdHeader))
+			if len(ids) == 1 {
+				return fakeResponse(http.StatusOK, toolCallBody), nil
+			}
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	payload := &CompletionRequestPayload{
+		Model:    "test-model",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+		Tools: []ToolDefinition{NewToolDefinition(&FunctionDefinition{
+			Name: "echo",
+			Fn:   func(args string) string { return args },
+		})},
+	}
+	if _, err := client.GetCompletion(payload); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(ids) != 2 || ids[0] == "" || ids[0] != ids[1] {
+		t.Errorf("expected both iterations to share a generated correlation id, got %v", ids)
+	}
+}
+
+func TestGetCompletion_SuppliedCorrelationId(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer slog.SetDefault(defaultLogger)
+
+	var id string
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			id = req.Header.Get(clientRequestIdHeader)
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	ctx := WithCorrelationId(context.Background(), "run-42")
+	payload := &CompletionRequestPayload{Model: "test-model", Messages: []Message{{Role: "user", Content: "Hi"}}}
+	if _, err := client.GetCompletionContext(ctx, payload); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if id != "run-42" {
+		t.Errorf("expected correlation id 'run-42', got %q", id)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		if !strings.Contains(line, "correlationId=run-42") {
+			t.Errorf("expected log record to carry the correlation id: %s", line)
+		}
+	}
+}
+
+func TestCorrelationAttr_Empty(t *testing.T) {
+	if attr := correlationAttr(context.Background()); !attr.Equal(slog.Attr{}) {
+		t.Errorf("expected empty attribute, got %v", attr)
+	}
+}
@@ -0,0 +1,56 @@
+package openaiclient
+
+import "context"
+
+type (
+	// DuplicateGroup is a set of near-duplicate texts, by index in the input.
+	// Representative is the first of them, which the others were matched to.
+	DuplicateGroup struct {
+		Representative int
+		Members        []int
+	}
+
+	DedupeResult struct {
+		// Unique holds the representative of every group, in input order.
+		Unique []string
+		Groups []DuplicateGroup
+	}
+)
+
+// Dedupe groups the texts whose embeddings have a cosine similarity of at
+// least threshold, e.g. 0.95 for near-verbatim copies, to clean datasets
+// before fine-tuning or indexing. Texts are matched in order against the
+// representatives of the groups found so far, and identical texts are only
+// embedded once.
+func (o *OpenAI) Dedupe(ctx context.Context, model string, texts []string, threshold float64) (*DedupeResult, error) {
+	embeddings := map[string][]float64{}
+	var representatives [][]float64
+	result := &DedupeResult{}
+
+	for i, text := range texts {
+		embedding, ok := embeddings[text]
+		if !ok {
+			var err error
+			if embedding, err = o.GetEmbeddingContext(ctx, GetEmbeddingPayload{Model: model, Input: text}); err != nil {
+				return nil, err
+			}
+			embeddings[text] = embedding
+		}
+
+		group, best := -1, threshold
+		for j, representative := range representatives {
+			if similarity := CosineSimilarity(embedding, representative); similarity >= best {
+				group, best = j, similarity
+			}
+		}
+		if group >= 0 {
+			result.Groups[group].Members = append(result.Groups[group].Members, i)
+			continue
+		}
+
+		representatives = append(representatives, embedding)
+		result.Unique = append(result.Unique, text)
+		result.Groups = append(result.Groups, DuplicateGroup{Representative: i, Members: []int{i}})
+	}
+	return result, nil
+}
@@ -0,0 +1,57 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestDedupe(t *testing.T) {
+	vectors := map[string]string{
+		"How do I reset my password?":  "[1,0]",
+		"how do i reset my password":   "[0.99,0.05]",
+		"What are your opening hours?": "[0,1]",
+		"When are you open?":           "[0.1,0.98]",
+		"Do you ship to Canada?":       "[0.7,0.7]",
+	}
+	calls := 0
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			calls++
+			var payload GetEmbeddingPayload
+			body, _ := io.ReadAll(req.Body)
+			json.Unmarshal(body, &payload)
+			return fakeResponse(http.StatusOK, fmt.Sprintf(`{"data":[{"embedding":%s}]}`, vectors[payload.Input])), nil
+		},
+	}
+
+	texts := []string{
+		"How do I reset my password?",
+		"What are your opening hours?",
+		"how do i reset my password",
+		"Do you ship to Canada?",
+		"When are you open?",
+		"How do I reset my password?",
+	}
+	result, err := client.Dedupe(context.Background(), "embedding-model", texts, 0.95)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Unique) != 3 || result.Unique[2] != "Do you ship to Canada?" {
+		t.Errorf("expected three unique texts, got %q", result.Unique)
+	}
+	if members := result.Groups[0].Members; len(members) != 3 || members[1] != 2 || members[2] != 5 {
+		t.Errorf("expected the password questions grouped, got %v", members)
+	}
+	if group := result.Groups[1]; group.Representative != 1 || len(group.Members) != 2 {
+		t.Errorf("expected the opening hours questions grouped, got %+v", group)
+	}
+	if calls != 5 {
+		t.Errorf("expected identical texts to be embedded once, got %d calls", calls)
+	}
+}
@@ -0,0 +1,87 @@
+package openaiclient
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+const (
+	defaultDistillKeepTurns = 1
+
+	// minDistillTokens is the estimated size under which a tool result is
+	// not worth summarizing.
+	minDistillTokens = 100
+
+	distillInstructions = "Summarize the result of the tool call below in a few sentences. Keep the facts, " +
+		"identifiers and numbers a conversation may need later, and drop everything else. Reply with the summary only."
+)
+
+type DistillPayload struct {
+	Messages []Message
+	// Model summarizes the tool results.
+	Model string
+	// Budget is the estimated token count the messages should fit in. Tool
+	// results are only summarized until they do.
+	Budget int
+	// KeepTurns is the number of most recent user turns, with the tool
+	// results that follow them, kept verbatim. It defaults to 1.
+	KeepTurns int
+}
+
+// Distill shortens a conversation by replacing the results of the tool calls
+// of earlier turns with short summaries written by the model, the oldest
+// first, until its estimated size fits the budget. Unlike TrimPolicy, no
+// message is dropped, so the model still knows what it has done. The
+// returned messages may still exceed the budget when the recent turns alone
+// do; the input messages are not modified.
+func (o *OpenAI) Distill(ctx context.Context, payload *DistillPayload) ([]Message, error) {
+	messages := slices.Clone(payload.Messages)
+	size := estimateTokens(messages)
+	if size <= payload.Budget {
+		return messages, nil
+	}
+
+	keepTurns := payload.KeepTurns
+	if keepTurns <= 0 {
+		keepTurns = defaultDistillKeepTurns
+	}
+	recent := len(messages)
+	for turns := 0; recent > 0 && turns < keepTurns; {
+		recent--
+		if messages[recent].Role == MessageRoleUser {
+			turns++
+		}
+	}
+
+	calls := map[string]FunctionCall{}
+	for i, message := range messages[:recent] {
+		for _, call := range message.ToolCalls {
+			calls[call.Id] = call.Function
+		}
+		if message.Role != MessageRoleTool || estimateMessageTokens(message) < minDistillTokens {
+			continue
+		}
+
+		call := calls[message.ToolCallId]
+		summary, err := o.GetCompletionContext(ctx, &CompletionRequestPayload{
+			Model: payload.Model,
+			Messages: []Message{
+				{Role: MessageRoleSystem, Content: distillInstructions},
+				{Role: MessageRoleUser, Content: fmt.Sprintf("Tool: %s\nArguments: %s\nResult:\n%s", call.Name, call.Arguments, message.Content)},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error summarizing tool result: %w", err)
+		}
+
+		distilled := message
+		distilled.Content = "Summary of the tool result: " + summary.Content
+		size += estimateMessageTokens(distilled) - estimateMessageTokens(message)
+		messages[i] = distilled
+		if size <= payload.Budget {
+			break
+		}
+	}
+	return messages, nil
+}
@@ -0,0 +1,68 @@
+package openaiclient
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func distillConversation() []Message {
+	large := strings.Repeat("row ", 200)
+	return []Message{
+		{Role: MessageRoleSystem, Content: "You are an analyst."},
+		{Role: MessageRoleUser, Content: "Load the sales"},
+		{Role: MessageRoleAssistant, ToolCalls: []ToolCall{{Id: "call_1", Function: FunctionCall{Name: "query", Arguments: `{"table":"sales"}`}}}},
+		{Role: MessageRoleTool, ToolCallId: "call_1", Content: large},
+		{Role: MessageRoleAssistant, ToolCalls: []ToolCall{{Id: "call_2", Function: FunctionCall{Name: "query", Arguments: `{"table":"costs"}`}}}},
+		{Role: MessageRoleTool, ToolCallId: "call_2", Content: large},
+		{Role: MessageRoleAssistant, Content: "Loaded."},
+		{Role: MessageRoleUser, Content: "Now the refunds"},
+		{Role: MessageRoleAssistant, ToolCalls: []ToolCall{{Id: "call_3", Function: FunctionCall{Name: "query", Arguments: `{"table":"refunds"}`}}}},
+		{Role: MessageRoleTool, ToolCallId: "call_3", Content: large},
+	}
+}
+
+func TestDistill(t *testing.T) {
+	client := NewTestClient(ScriptedTurn{Content: "1200 sales rows."})
+	messages := distillConversation()
+
+	distilled, err := client.Distill(context.Background(), &DistillPayload{
+		Messages: messages,
+		Model:    "test-model",
+		Budget:   estimateTokens(messages) - 100,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if distilled[3].Content != "Summary of the tool result: 1200 sales rows." || distilled[3].ToolCallId != "call_1" {
+		t.Errorf("expected the oldest tool result summarized, got %+v", distilled[3])
+	}
+	if distilled[5].Content != messages[5].Content || distilled[9].Content != messages[9].Content {
+		t.Error("expected the other tool results kept once within budget")
+	}
+	if !strings.Contains(client.Completions()[0].Messages[1].Content, `{"table":"sales"}`) {
+		t.Errorf("expected the tool call in the summary request, got %+v", client.Completions()[0].Messages)
+	}
+	if messages[3].Content == distilled[3].Content {
+		t.Error("expected the input messages to be left untouched")
+	}
+}
+
+func TestDistill_KeepsRecentTurns(t *testing.T) {
+	client := NewTestClient(ScriptedTurn{Content: "sales"}, ScriptedTurn{Content: "costs"})
+	messages := distillConversation()
+
+	distilled, err := client.Distill(context.Background(), &DistillPayload{Messages: messages, Model: "test-model", Budget: 10})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if distilled[9].Content != messages[9].Content || client.Remaining() != 0 {
+		t.Errorf("expected only the earlier turn distilled, got %+v", distilled)
+	}
+
+	within, _ := NewTestClient().Distill(context.Background(), &DistillPayload{Messages: messages, Budget: 1 << 20})
+	if len(within) != len(messages) {
+		t.Error("expected messages within budget to be returned as they are")
+	}
+}
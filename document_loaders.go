@@ -0,0 +1,271 @@
+package openaiclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MetadataSource = "source"
+	MetadataTitle  = "title"
+	MetadataRow    = "row"
+	MetadataChunk  = "chunk"
+	MetadataOffset = "offset"
+)
+
+type (
+	// Document is a loaded text to split into chunks for retrieval. Its
+	// metadata always includes the MetadataSource it was loaded from.
+	Document struct {
+		Text     string
+		Metadata map[string]string
+	}
+
+	// Chunk is a piece of a document sized for embedding. Its metadata is the
+	// document's plus its MetadataChunk number and the byte MetadataOffset of
+	// its text in the document, ready for Index.Add. Its id is the document's
+	// id, see Document.Id, followed by "#" and the chunk number.
+	Chunk struct {
+		Id       string
+		Text     string
+		Metadata map[string]string
+	}
+
+	// PDFExtractor extracts the text of a PDF, which the library leaves to a
+	// dedicated package or service.
+	PDFExtractor interface {
+		ExtractText(r io.Reader) (string, error)
+	}
+)
+
+var (
+	markdownHeading = regexp.MustCompile(`(?m)^#\s+(.+)$`)
+	htmlTitle       = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlComment     = regexp.MustCompile(`(?s)<!--.*?-->`)
+	// htmlBoilerplate matches the elements that hold no content of the page:
+	// scripts, styles, navigation, headers, footers and the like.
+	htmlBoilerplate = regexp.MustCompile(`(?is)<(script|style|noscript|template|svg|nav|header|footer|aside|form)\b.*?</(script|style|noscript|template|svg|nav|header|footer|aside|form)>`)
+	htmlBlockTag    = regexp.MustCompile(`(?i)</?(p|div|br|li|ul|ol|h[1-6]|tr|table|section|article|main|blockquote|pre)\b[^>]*>`)
+	htmlTag         = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLines      = regexp.MustCompile(`\n\s*\n\s*`)
+	spaces          = regexp.MustCompile(`[ \t\r\f\v]+`)
+)
+
+// LoadText loads a plain text document.
+func LoadText(r io.Reader, source string) (*Document, error) {
+	text, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", source, err)
+	}
+	return newDocument(string(text), source), nil
+}
+
+// LoadMarkdown loads a Markdown document as is, which embedding models
+// handle well, and takes its title from the first top level heading.
+func LoadMarkdown(r io.Reader, source string) (*Document, error) {
+	document, err := LoadText(r, source)
+	if err != nil {
+		return nil, err
+	}
+	if match := markdownHeading.FindStringSubmatch(document.Text); match != nil {
+		document.Metadata[MetadataTitle] = strings.TrimSpace(match[1])
+	}
+	return document, nil
+}
+
+// LoadHTML loads the text of an HTML page, dropping its markup and the
+// boilerplate elements such as scripts, navigation, headers and footers.
+func LoadHTML(r io.Reader, source string) (*Document, error) {
+	page, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", source, err)
+	}
+
+	text := htmlComment.ReplaceAllString(string(page), "")
+	var title string
+	if match := htmlTitle.FindStringSubmatch(text); match != nil {
+		title = strings.TrimSpace(html.UnescapeString(match[1]))
+		text = strings.Replace(text, match[0], "", 1)
+	}
+	text = htmlBoilerplate.ReplaceAllString(text, "")
+	text = htmlBlockTag.ReplaceAllString(text, "\n\n")
+	text = html.UnescapeString(htmlTag.ReplaceAllString(text, ""))
+	text = spaces.ReplaceAllString(text, " ")
+	text = blankLines.ReplaceAllString(text, "\n\n")
+
+	document := newDocument(strings.TrimSpace(text), source)
+	if title != "" {
+		document.Metadata[MetadataTitle] = title
+	}
+	return document, nil
+}
+
+// LoadCSV loads a document per row of a CSV file with a header row. The text
+// of a row lists its fields as "column: value" lines, and its metadata holds
+// its 1-based MetadataRow.
+func LoadCSV(r io.Reader, source string) ([]Document, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", source, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	documents := make([]Document, 0, len(records)-1)
+	for i, record := range records[1:] {
+		var text strings.Builder
+		for j, value := range record {
+			column := "column " + strconv.Itoa(j+1)
+			if j < len(header) {
+				column = header[j]
+			}
+			fmt.Fprintf(&text, "%s: %s\n", column, value)
+		}
+		document := newDocument(strings.TrimSpace(text.String()), source)
+		document.Metadata[MetadataRow] = strconv.Itoa(i + 1)
+		documents = append(documents, *document)
+	}
+	return documents, nil
+}
+
+// LoadPDF loads the text of a PDF with the given extractor.
+func LoadPDF(r io.Reader, source string, extractor PDFExtractor) (*Document, error) {
+	if extractor == nil {
+		return nil, NewInvalidRequestError("a PDF extractor is required to load " + source)
+	}
+	text, err := extractor.ExtractText(r)
+	if err != nil {
+		return nil, fmt.Errorf("error extracting %s: %w", source, err)
+	}
+	return newDocument(text, source), nil
+}
+
+// LoadFile loads the file at path with the loader for its extension: .md and
+// .markdown, .html and .htm, .csv, .pdf, and plain text for anything else.
+// The extractor is only needed for PDFs.
+func LoadFile(path string, extractor PDFExtractor) ([]Document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	r := bytes.NewReader(content)
+
+	var document *Document
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		document, err = LoadMarkdown(r, path)
+	case ".html", ".htm":
+		document, err = LoadHTML(r, path)
+	case ".csv":
+		return LoadCSV(r, path)
+	case ".pdf":
+		document, err = LoadPDF(r, path, extractor)
+	default:
+		document, err = LoadText(r, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []Document{*document}, nil
+}
+
+// Chunks splits the document into chunks of at most size bytes, breaking at
+// paragraphs, lines, sentences or words where possible. Consecutive chunks
+// share about overlap bytes, so a passage cut in two is still found whole
+// in one of them.
+func (d *Document) Chunks(size, overlap int) []Chunk {
+	if size <= 0 {
+		size = len(d.Text)
+	}
+	overlap = min(max(overlap, 0), size/2)
+
+	var chunks []Chunk
+	text := d.Text
+	for start := 0; start < len(text); {
+		end := len(text)
+		if start+size < len(text) {
+			end = chunkEnd(text, start, start+size)
+		}
+
+		trimmed := strings.TrimSpace(text[start:end])
+		if trimmed != "" {
+			metadata := maps.Clone(d.Metadata)
+			if metadata == nil {
+				metadata = map[string]string{}
+			}
+			metadata[MetadataChunk] = strconv.Itoa(len(chunks))
+			metadata[MetadataOffset] = strconv.Itoa(start + strings.Index(text[start:end], trimmed))
+			chunks = append(chunks, Chunk{
+				Id:       fmt.Sprintf("%s#%d", d.Id(), len(chunks)),
+				Text:     trimmed,
+				Metadata: metadata,
+			})
+		}
+		if end == len(text) {
+			break
+		}
+
+		next := end - overlap
+		if i := strings.IndexAny(text[next:end], " \n"); overlap > 0 && i >= 0 {
+			next += i + 1
+		}
+		for next < end && !utf8.RuneStart(text[next]) {
+			next++
+		}
+		if next <= start {
+			next = end
+		}
+		start = next
+	}
+	return chunks
+}
+
+// Id identifies the document among the others: its source, followed by ":"
+// and its MetadataRow for the rows of a CSV. Documents without a source are
+// identified by a hash of their text.
+func (d *Document) Id() string {
+	id := d.Metadata[MetadataSource]
+	if id == "" {
+		sum := sha256.Sum256([]byte(d.Text))
+		id = hex.EncodeToString(sum[:8])
+	}
+	if row := d.Metadata[MetadataRow]; row != "" {
+		id += ":" + row
+	}
+	return id
+}
+
+// chunkEnd returns where to end a chunk starting at start so that it ends
+// before limit, at the latest natural break in its second half.
+func chunkEnd(text string, start, limit int) int {
+	window := text[start:limit]
+	for _, separator := range []string{"\n\n", "\n", ". ", " "} {
+		if i := strings.LastIndex(window, separator); i >= len(window)/2 {
+			return start + i + len(separator)
+		}
+	}
+	for limit > start+1 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	return limit
+}
+
+func newDocument(text, source string) *Document {
+	return &Document{Text: text, Metadata: map[string]string{MetadataSource: source}}
+}
@@ -0,0 +1,118 @@
+package openaiclient
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type fakePDFExtractor struct{}
+
+func (fakePDFExtractor) ExtractText(r io.Reader) (string, error) {
+	content, _ := io.ReadAll(r)
+	if !strings.HasPrefix(string(content), "%PDF") {
+		return "", errors.New("not a PDF")
+	}
+	return "extracted text", nil
+}
+
+func TestLoadHTML(t *testing.T) {
+	page := `<html><head><title>Docs &amp; guides</title><style>body { color: red }</style></head>
+<body><nav><a href="/">Home</a></nav>
+<main><h1>Install</h1><p>Run <code>go get</code>.</p><!-- hidden --><p>Then &lt;import&gt; it.</p></main>
+<script>track()</script><footer>Copyright</footer></body></html>`
+
+	document, err := LoadHTML(strings.NewReader(page), "docs.html")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := "Install\n\nRun go get.\n\nThen <import> it."; document.Text != want {
+		t.Errorf("expected %q, got %q", want, document.Text)
+	}
+	if document.Metadata[MetadataTitle] != "Docs & guides" || document.Metadata[MetadataSource] != "docs.html" {
+		t.Errorf("unexpected metadata %v", document.Metadata)
+	}
+}
+
+func TestLoadCSV(t *testing.T) {
+	documents, err := LoadCSV(strings.NewReader("name,role\nAda,engineer\nGrace,admiral\n"), "people.csv")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(documents) != 2 || documents[1].Text != "name: Grace\nrole: admiral" || documents[1].Metadata[MetadataRow] != "2" {
+		t.Errorf("expected a document per row, got %+v", documents)
+	}
+
+	ids := map[string]bool{}
+	for _, document := range documents {
+		for _, chunk := range document.Chunks(1000, 0) {
+			if ids[chunk.Id] {
+				t.Errorf("expected unique chunk ids, got %s twice", chunk.Id)
+			}
+			ids[chunk.Id] = true
+		}
+	}
+	if !ids["people.csv:1#0"] || !ids["people.csv:2#0"] {
+		t.Errorf("expected the rows in the chunk ids, got %v", ids)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	markdown := filepath.Join(dir, "guide.md")
+	os.WriteFile(markdown, []byte("intro\n# Getting started\nbody"), 0o600)
+	pdf := filepath.Join(dir, "report.pdf")
+	os.WriteFile(pdf, []byte("%PDF-1.7"), 0o600)
+
+	documents, err := LoadFile(markdown, nil)
+	if err != nil || documents[0].Metadata[MetadataTitle] != "Getting started" {
+		t.Errorf("expected the Markdown title, got %+v, %v", documents, err)
+	}
+	documents, err = LoadFile(pdf, fakePDFExtractor{})
+	if err != nil || documents[0].Text != "extracted text" {
+		t.Errorf("expected the extracted text, got %+v, %v", documents, err)
+	}
+	if _, err := LoadFile(pdf, nil); err == nil {
+		t.Error("expected an error without a PDF extractor")
+	}
+}
+
+func TestDocument_Chunks(t *testing.T) {
+	document := newDocument("First paragraph here.\n\nSecond one is a little longer. It has two sentences.\n\nThird.", "notes.txt")
+
+	chunks := document.Chunks(40, 10)
+	if len(chunks) < 3 {
+		t.Fatalf("expected the text to be split, got %+v", chunks)
+	}
+	for i, chunk := range chunks {
+		if len(chunk.Text) > 40 {
+			t.Errorf("chunk %d: expected at most 40 bytes, got %q", i, chunk.Text)
+		}
+		offset, _ := strconv.Atoi(chunk.Metadata[MetadataOffset])
+		if !strings.HasPrefix(document.Text[offset:], chunk.Text) {
+			t.Errorf("chunk %d: expected its text at offset %d", i, offset)
+		}
+		if chunk.Metadata[MetadataSource] != "notes.txt" || chunk.Id != "notes.txt#"+chunk.Metadata[MetadataChunk] {
+			t.Errorf("chunk %d: unexpected id and metadata %+v", i, chunk)
+		}
+	}
+	if chunks[0].Text != "First paragraph here." {
+		t.Errorf("expected the first chunk to end at the paragraph, got %q", chunks[0].Text)
+	}
+
+	if chunks := document.Chunks(0, 0); len(chunks) != 1 || chunks[0].Text != document.Text {
+		t.Errorf("expected a single chunk without a size, got %+v", chunks)
+	}
+}
+
+func TestDocument_IdWithoutSource(t *testing.T) {
+	a := Document{Text: "first"}
+	b := Document{Text: "second"}
+	if a.Id() == "" || a.Id() == b.Id() {
+		t.Errorf("expected distinct ids for documents without a source, got %q and %q", a.Id(), b.Id())
+	}
+}
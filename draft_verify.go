@@ -0,0 +1,92 @@
+package openaiclient
+
+import (
+	"context"
+	"slices"
+)
+
+const defaultRepairInstructions = "Review your previous answer against the request and fix any mistakes. " +
+	"Reply with the corrected answer only."
+
+type (
+	DraftVerifyPayload struct {
+		// Payload is the request to answer. Its model is replaced by
+		// DraftModel and, when the draft needs repairing, by VerifyModel.
+		Payload     *CompletionRequestPayload
+		DraftModel  string
+		VerifyModel string
+		// Judge scores the draft against the last user message. Drafts scoring
+		// below Threshold are sent to VerifyModel for repair.
+		Judge     Judge
+		Threshold float64
+		// RepairInstructions is the user message asking VerifyModel to fix the
+		// draft. A generic instruction is used when empty.
+		RepairInstructions string
+	}
+
+	DraftVerifyResult struct {
+		// Message is the final answer: the draft, or its repaired version.
+		Message  *Message
+		Draft    *Message
+		Score    float64
+		Repaired bool
+	}
+)
+
+// DraftAndVerify answers with a cheap model first and only involves the
+// stronger model when the judge is not confident in the draft. This cuts the
+// cost of high volume workloads where most drafts are good enough.
+func (o *OpenAI) DraftAndVerify(ctx context.Context, payload *DraftVerifyPayload) (*DraftVerifyResult, error) {
+	if payload.Judge == nil {
+		return nil, NewInvalidRequestError("a judge is required to verify drafts")
+	}
+
+	drafted := withModel(payload.Payload, payload.DraftModel)
+	draft, err := o.GetCompletionContext(ctx, drafted)
+	if err != nil {
+		return nil, err
+	}
+
+	score, err := payload.Judge(ctx, lastUserContent(payload.Payload.Messages), draft.Content)
+	if err != nil {
+		return nil, err
+	}
+	result := &DraftVerifyResult{Message: draft, Draft: draft, Score: score}
+	if score >= payload.Threshold {
+		return result, nil
+	}
+
+	instructions := payload.RepairInstructions
+	if instructions == "" {
+		instructions = defaultRepairInstructions
+	}
+	// The repair continues the draft run, so the tool calls and results the
+	// draft was based on are kept, and the draft is its last message.
+	repair := withModel(drafted, payload.VerifyModel)
+	repair.Messages = append(repair.Messages, Message{Role: MessageRoleUser, Content: instructions})
+
+	if result.Message, err = o.GetCompletionContext(ctx, repair); err != nil {
+		return nil, err
+	}
+	result.Repaired = true
+	return result, nil
+}
+
+// withModel copies the payload for the given model, so that the messages the
+// ReAct loop appends do not leak into the caller's payload.
+func withModel(payload *CompletionRequestPayload, model string) *CompletionRequestPayload {
+	copied := *payload
+	copied.Model = model
+	copied.Messages = slices.Clone(payload.Messages)
+	copied.NewMessages = nil
+	return &copied
+}
+
+func lastUserContent(messages []Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == MessageRoleUser {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
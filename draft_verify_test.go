@@ -0,0 +1,127 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func newDraftVerifyClient(t *testing.T, requests *[]CompletionRequestPayload) *OpenAI {
+	t.Helper()
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			var payload CompletionRequestPayload
+			body, _ := io.ReadAll(req.Body)
+			json.Unmarshal(body, &payload)
+			*requests = append(*requests, payload)
+
+			content, _ := json.Marshal(payload.Model + " answer")
+			return fakeResponse(http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":`+string(content)+`}}]}`), nil
+		},
+	}
+	return client
+}
+
+func TestDraftAndVerify_KeepsConfidentDraft(t *testing.T) {
+	var requests []CompletionRequestPayload
+	client := newDraftVerifyClient(t, &requests)
+
+	payload := &CompletionRequestPayload{Messages: []Message{{Role: MessageRoleUser, Content: "Extract the date"}}}
+	result, err := client.DraftAndVerify(context.Background(), &DraftVerifyPayload{
+		Payload:     payload,
+		DraftModel:  "small-model",
+		VerifyModel: "large-model",
+		Threshold:   0.8,
+		Judge: func(ctx context.Context, input, output string) (float64, error) {
+			if input != "Extract the date" || output != "small-model answer" {
+				t.Errorf("unexpected judge arguments %q and %q", input, output)
+			}
+			return 0.9, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result.Repaired || result.Message.Content != "small-model answer" || result.Score != 0.9 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if len(requests) != 1 {
+		t.Errorf("expected a single request, got %d", len(requests))
+	}
+	if len(payload.Messages) != 1 || payload.Model != "" {
+		t.Errorf("expected the caller's payload to be left untouched, got %+v", payload)
+	}
+}
+
+func TestDraftAndVerify_RepairsLowScoringDraft(t *testing.T) {
+	var requests []CompletionRequestPayload
+	client := newDraftVerifyClient(t, &requests)
+
+	result, err := client.DraftAndVerify(context.Background(), &DraftVerifyPayload{
+		Payload:     &CompletionRequestPayload{Messages: []Message{{Role: MessageRoleUser, Content: "Extract the date"}}},
+		DraftModel:  "small-model",
+		VerifyModel: "large-model",
+		Threshold:   0.8,
+		Judge: func(ctx context.Context, input, output string) (float64, error) {
+			return 0.2, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !result.Repaired || result.Message.Content != "large-model answer" || result.Draft.Content != "small-model answer" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	repair := requests[1].Messages
+	if len(repair) != 3 || repair[1].Content != "small-model answer" || repair[2].Content != defaultRepairInstructions {
+		t.Errorf("unexpected repair messages %+v", repair)
+	}
+}
+
+func TestDraftAndVerify_RepairKeepsToolResults(t *testing.T) {
+	client := NewTestClient(
+		ScriptedTurn{ToolCalls: []ToolCall{{Id: "call_1", Type: "function", Function: FunctionCall{Name: "lookup", Arguments: `{}`}}}},
+		ScriptedTurn{Content: "draft"},
+		ScriptedTurn{Content: "repaired"},
+	)
+
+	_, err := client.DraftAndVerify(context.Background(), &DraftVerifyPayload{
+		Payload: &CompletionRequestPayload{
+			Messages: []Message{{Role: MessageRoleUser, Content: "When is the order due?"}},
+			Tools: []ToolDefinition{NewToolDefinition(&FunctionDefinition{
+				Name: "lookup",
+				Fn:   func(string) string { return "due Friday" },
+			})},
+		},
+		DraftModel:  "small-model",
+		VerifyModel: "large-model",
+		Threshold:   0.8,
+		Judge: func(ctx context.Context, input, output string) (float64, error) {
+			return 0.2, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	repair := client.Completions()[2].Messages
+	if len(repair) != 5 || repair[2].Content != "due Friday" || repair[3].Content != "draft" || repair[4].Content != defaultRepairInstructions {
+		t.Errorf("expected the repair to continue the draft run, got %+v", repair)
+	}
+}
+
+func TestDraftAndVerify_RequiresJudge(t *testing.T) {
+	client := createClient(t)
+
+	if _, err := client.DraftAndVerify(context.Background(), &DraftVerifyPayload{Payload: &CompletionRequestPayload{}}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,53 @@
+package openaiclient
+
+import (
+	"context"
+	"sync"
+)
+
+// embeddingCache keeps the embeddings of texts reused across requests, such
+// as few-shot examples or tool descriptions. Its zero value is ready to use
+// and it is safe for concurrent use.
+type embeddingCache struct {
+	mu         sync.Mutex
+	embeddings map[string][]float64
+}
+
+// get returns the embeddings of texts in order, embedding the ones missing
+// from the cache in a single batch request.
+func (c *embeddingCache) get(ctx context.Context, client *OpenAI, model string, texts []string) ([][]float64, error) {
+	embeddings := make([][]float64, len(texts))
+	var missing []string
+	seen := map[string]bool{}
+	c.mu.Lock()
+	for i, text := range texts {
+		if embedding, ok := c.embeddings[text]; ok {
+			embeddings[i] = embedding
+		} else if !seen[text] {
+			seen[text] = true
+			missing = append(missing, text)
+		}
+	}
+	c.mu.Unlock()
+	if len(missing) == 0 {
+		return embeddings, nil
+	}
+
+	embedded, err := client.GetEmbeddings(ctx, GetEmbeddingPayload{Model: model, Inputs: missing})
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.embeddings == nil {
+		c.embeddings = map[string][]float64{}
+	}
+	for i, text := range missing {
+		c.embeddings[text] = embedded[i]
+	}
+	for i, text := range texts {
+		embeddings[i] = c.embeddings[text]
+	}
+	return embeddings, nil
+}
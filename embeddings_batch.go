@@ -0,0 +1,161 @@
+package openaiclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+)
+
+const defaultBatchPollInterval = 30 * time.Second
+
+type (
+	EmbedCorpusBatchPayload struct {
+		Model string
+		// Inputs maps each text's custom_id to the text to embed.
+		Inputs       map[string]string
+		Metadata     map[string]string
+		PollInterval time.Duration
+	}
+
+	EmbedCorpusBatchResult struct {
+		Batch      *Batch
+		Embeddings map[string][]float64
+		// Errors maps the custom_id of every input that could not be embedded
+		// to the reason reported by the API.
+		Errors map[string]string
+	}
+
+	batchRequestLine struct {
+		CustomId string `json:"custom_id"`
+		Method   string `json:"method"`
+		Url      string `json:"url"`
+		Body     any    `json:"body"`
+	}
+
+	batchResponseLine struct {
+		Id       string `json:"id"`
+		CustomId string `json:"custom_id"`
+		Response *struct {
+			StatusCode int             `json:"status_code"`
+			Body       json.RawMessage `json:"body"`
+		} `json:"response"`
+		Error *BatchError `json:"error"`
+	}
+)
+
+// EmbedCorpusBatch embeds the inputs through the Batch API, which is billed
+// at a discount in exchange for completing within 24 hours. It uploads the
+// requests as a JSONL file, creates the batch, polls until it finishes and
+// downloads the results.
+func (o *OpenAI) EmbedCorpusBatch(ctx context.Context, payload *EmbedCorpusBatchPayload) (*EmbedCorpusBatchResult, error) {
+	if len(payload.Inputs) == 0 {
+		return nil, NewInvalidRequestError("no inputs to embed")
+	}
+	pollInterval := payload.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultBatchPollInterval
+	}
+
+	var input bytes.Buffer
+	encoder := json.NewEncoder(&input)
+	for _, customId := range slices.Sorted(maps.Keys(payload.Inputs)) {
+		line := batchRequestLine{
+			CustomId: customId,
+			Method:   "POST",
+			Url:      embeddingsEndpoint,
+			Body:     GetEmbeddingPayload{Model: payload.Model, Input: payload.Inputs[customId]},
+		}
+		if err := encoder.Encode(line); err != nil {
+			return nil, fmt.Errorf("error encoding batch request: %w", err)
+		}
+	}
+
+	file, err := o.UploadFile(ctx, "embeddings.jsonl", FilePurposeBatch, &input)
+	if err != nil {
+		return nil, fmt.Errorf("error uploading batch input: %w", err)
+	}
+
+	batch, err := o.CreateBatch(ctx, &CreateBatchPayload{
+		InputFileId: file.Id,
+		Endpoint:    embeddingsEndpoint,
+		Metadata:    payload.Metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating batch: %w", err)
+	}
+
+	for !batch.Done() {
+		slog.Debug("waiting for batch", slog.String("batchId", batch.Id), slog.String("status", batch.Status))
+		if err := sleep(ctx, pollInterval); err != nil {
+			return nil, err
+		}
+		if batch, err = o.GetBatch(ctx, batch.Id); err != nil {
+			return nil, fmt.Errorf("error polling batch: %w", err)
+		}
+	}
+
+	if batch.Status != BatchStatusCompleted {
+		return nil, fmt.Errorf("batch %s finished with status %s", batch.Id, batch.Status)
+	}
+
+	result := &EmbedCorpusBatchResult{
+		Batch:      batch,
+		Embeddings: make(map[string][]float64, len(payload.Inputs)),
+		Errors:     make(map[string]string),
+	}
+	for _, fileId := range []string{batch.OutputFileId, batch.ErrorFileId} {
+		if fileId == "" {
+			continue
+		}
+		content, err := o.GetFileContent(ctx, fileId)
+		if err != nil {
+			return nil, fmt.Errorf("error downloading batch results: %w", err)
+		}
+		if err := result.addLines(content); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (r *EmbedCorpusBatchResult) addLines(content []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, 64<<20)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var line batchResponseLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return fmt.Errorf("error unmarshaling batch result: %w", err)
+		}
+
+		switch {
+		case line.Error != nil:
+			r.Errors[line.CustomId] = line.Error.Message
+		case line.Response == nil:
+			r.Errors[line.CustomId] = "missing response"
+		case line.Response.StatusCode != 200:
+			r.Errors[line.CustomId] = NewOpenAIError(line.Response.StatusCode, line.Response.Body).Error()
+		default:
+			var body GetEmbeddingResponse
+			if err := json.Unmarshal(line.Response.Body, &body); err != nil {
+				return fmt.Errorf("error unmarshaling batch result: %w", err)
+			}
+			if len(body.Data) == 0 {
+				r.Errors[line.CustomId] = "no embeddings returned"
+				continue
+			}
+			r.Embeddings[line.CustomId] = body.Data[0].Embedding
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,109 @@
+package openaiclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmbedCorpusBatch(t *testing.T) {
+	var uploaded []batchRequestLine
+	polls := 0
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			switch req.Method + " " + req.URL.Path {
+			case "POST /v1/files":
+				if err := req.ParseMultipartForm(1 << 20); err != nil {
+					t.Fatalf("expected multipart upload, got %v", err)
+				}
+				if purpose := req.FormValue("purpose"); purpose != FilePurposeBatch {
+					t.Errorf("expected purpose 'batch', got '%s'", purpose)
+				}
+				file, _, err := req.FormFile("file")
+				if err != nil {
+					t.Fatalf("expected uploaded file, got %v", err)
+				}
+				scanner := bufio.NewScanner(file)
+				for scanner.Scan() {
+					var line batchRequestLine
+					json.Unmarshal(scanner.Bytes(), &line)
+					uploaded = append(uploaded, line)
+				}
+				return fakeResponse(200, `{"id":"file_in","object":"file","purpose":"batch"}`), nil
+			case "POST /v1/batches":
+				var payload CreateBatchPayload
+				json.NewDecoder(req.Body).Decode(&payload)
+				if payload.InputFileId != "file_in" || payload.Endpoint != embeddingsEndpoint || payload.CompletionWindow != "24h" {
+					t.Errorf("unexpected batch payload %+v", payload)
+				}
+				return fakeResponse(200, `{"id":"batch_1","status":"validating"}`), nil
+			case "GET /v1/batches/batch_1":
+				polls++
+				if polls == 1 {
+					return fakeResponse(200, `{"id":"batch_1","status":"in_progress"}`), nil
+				}
+				return fakeResponse(200, `{"id":"batch_1","status":"completed","output_file_id":"file_out","error_file_id":"file_err"}`), nil
+			case "GET /v1/files/file_out/content":
+				return fakeResponse(200, strings.Join([]string{
+					`{"id":"r1","custom_id":"a","response":{"status_code":200,"body":{"data":[{"embedding":[0.1,0.2]}]}}}`,
+					`{"id":"r2","custom_id":"b","response":{"status_code":400,"body":{"error":{"message":"too long","type":"invalid_request_error"}}}}`,
+				}, "\n")), nil
+			case "GET /v1/files/file_err/content":
+				return fakeResponse(200, `{"id":"r3","custom_id":"c","error":{"code":"server_error","message":"failed"}}`), nil
+			}
+			t.Fatalf("unexpected request %s %s", req.Method, req.URL.Path)
+			return nil, nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	result, err := client.EmbedCorpusBatch(context.Background(), &EmbedCorpusBatchPayload{
+		Model:        "text-embedding-3-small",
+		Inputs:       map[string]string{"b": "second", "a": "first", "c": "third"},
+		PollInterval: time.Nanosecond,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(uploaded) != 3 || uploaded[0].CustomId != "a" || uploaded[0].Url != embeddingsEndpoint {
+		t.Errorf("unexpected uploaded lines %+v", uploaded)
+	}
+	if polls != 2 {
+		t.Errorf("expected 2 polls, got %d", polls)
+	}
+	if got := result.Embeddings["a"]; len(got) != 2 || got[0] != 0.1 {
+		t.Errorf("expected embedding for 'a', got %v", got)
+	}
+	if len(result.Errors) != 2 || result.Errors["c"] != "failed" {
+		t.Errorf("unexpected errors %v", result.Errors)
+	}
+}
+
+func TestEmbedCorpusBatch_FailedBatch(t *testing.T) {
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == filesEndpoint {
+				return fakeResponse(200, `{"id":"file_in"}`), nil
+			}
+			return fakeResponse(200, `{"id":"batch_1","status":"failed"}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	_, err := client.EmbedCorpusBatch(context.Background(), &EmbedCorpusBatchPayload{
+		Model:  "text-embedding-3-small",
+		Inputs: map[string]string{"a": "first"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed") {
+		t.Errorf("expected failed batch error, got %v", err)
+	}
+}
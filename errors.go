@@ -1,10 +1,12 @@
 package openaiclient
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 )
 
 const (
@@ -16,10 +18,13 @@ const (
 )
 
 type OpenAIError struct {
-	Type    string `json:"type"`
-	Message string `json:"message"`
-	Code    string `json:"code,omitempty"`
-	Param   string `json:"param,omitempty"`
+	Type       string `json:"type"`
+	Message    string `json:"message"`
+	Code       string `json:"code,omitempty"`
+	Param      string `json:"param,omitempty"`
+	StatusCode int    `json:"-"`
+	// RateLimit is the rate limit state reported with the error, if any.
+	RateLimit *RateLimitInfo `json:"-"`
 }
 
 func (e *OpenAIError) Error() string {
@@ -30,29 +35,102 @@ func (e *OpenAIError) Error() string {
 }
 
 func NewOpenAIError(statusCode int, body []byte) error {
-	var apiErr OpenAIError
-	if err := json.Unmarshal(body, &apiErr); err != nil {
+	if filterErr := parseContentFilterError(statusCode, body); filterErr != nil {
+		return filterErr
+	}
+
+	apiErr, ok := parseErrorBody(body)
+	if !ok {
 		return fmt.Errorf("request failed with status %d: %s", statusCode, string(body))
 	}
 
 	if apiErr.Type == "" {
-		switch statusCode {
-		case http.StatusBadRequest:
-			apiErr.Type = ErrTypeInvalidRequest
-		case http.StatusUnauthorized:
-			apiErr.Type = ErrTypeAuthentication
-		case http.StatusTooManyRequests:
-			apiErr.Type = ErrTypeRateLimit
-		case http.StatusServiceUnavailable:
-			apiErr.Type = ErrTypeServiceUnavailable
-		case http.StatusNotFound:
-			apiErr.Type = ErrTypeNotFound
-		default:
-			apiErr.Type = "unknown_error"
+		apiErr.Type = typeForStatus(statusCode)
+	}
+	apiErr.StatusCode = statusCode
+
+	if apiErr.Code == ErrCodeContextLengthExceeded {
+		return newContextLengthExceededError(apiErr)
+	}
+
+	return apiErr
+}
+
+// errorBody is the error object, which OpenAI nests under "error" and other
+// providers return flat. Codes are strings on OpenAI and numbers on some
+// gateways, and Ollama sends "error" as a plain message.
+type errorBody struct {
+	Type    string          `json:"type"`
+	Message string          `json:"message"`
+	Code    any             `json:"code"`
+	Param   string          `json:"param"`
+	Error   json.RawMessage `json:"error"`
+}
+
+// parseErrorBody decodes the nested {"error": {...}} and the flat error
+// shapes, as well as lists of errors, of which the first one is used.
+func parseErrorBody(body []byte) (*OpenAIError, bool) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var errs []json.RawMessage
+		if err := json.Unmarshal(body, &errs); err != nil || len(errs) == 0 {
+			return nil, false
 		}
+		body = errs[0]
+	}
+
+	var flat errorBody
+	if err := json.Unmarshal(body, &flat); err != nil {
+		return nil, false
 	}
 
-	return &apiErr
+	var message string
+	switch {
+	case len(flat.Error) == 0 || string(flat.Error) == "null":
+	case json.Unmarshal(flat.Error, &message) == nil:
+		flat.Message = message
+	default:
+		var nested errorBody
+		if err := json.Unmarshal(flat.Error, &nested); err != nil {
+			return nil, false
+		}
+		flat = nested
+	}
+
+	return &OpenAIError{
+		Type:    flat.Type,
+		Message: flat.Message,
+		Code:    errorCode(flat.Code),
+		Param:   flat.Param,
+	}, true
+}
+
+func errorCode(code any) string {
+	switch code := code.(type) {
+	case string:
+		return code
+	case float64:
+		return strconv.FormatFloat(code, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+func typeForStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return ErrTypeInvalidRequest
+	case http.StatusUnauthorized:
+		return ErrTypeAuthentication
+	case http.StatusTooManyRequests:
+		return ErrTypeRateLimit
+	case http.StatusServiceUnavailable:
+		return ErrTypeServiceUnavailable
+	case http.StatusNotFound:
+		return ErrTypeNotFound
+	default:
+		return "unknown_error"
+	}
 }
 
 func NewInvalidRequestError(message string) error {
@@ -96,7 +174,8 @@ func IsOpenAIError(err error) bool {
 }
 
 func GetOpenAIErrorType(err error) string {
-	if apiErr, ok := err.(*OpenAIError); ok {
+	var apiErr *OpenAIError
+	if errors.As(err, &apiErr) {
 		return apiErr.Type
 	}
 	return ""
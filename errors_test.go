@@ -121,6 +121,74 @@ func TestNewOpenAIError(t *testing.T) {
 	}
 }
 
+func TestNewOpenAIError_Envelopes(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		want       OpenAIError
+	}{
+		{
+			name:       "openai nested",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"error":{"message":"Incorrect API key provided: sk-abc.","type":"invalid_request_error","param":null,"code":"invalid_api_key"}}`,
+			want:       OpenAIError{Type: ErrTypeInvalidRequest, Message: "Incorrect API key provided: sk-abc.", Code: "invalid_api_key"},
+		},
+		{
+			name:       "azure nested with null type",
+			statusCode: http.StatusTooManyRequests,
+			body:       `{"error":{"code":"429","message":"Requests to the ChatCompletions_Create Operation have exceeded the call rate limit.","type":null}}`,
+			want:       OpenAIError{Type: ErrTypeRateLimit, Message: "Requests to the ChatCompletions_Create Operation have exceeded the call rate limit.", Code: "429"},
+		},
+		{
+			name:       "openrouter numeric code",
+			statusCode: http.StatusPaymentRequired,
+			body:       `{"error":{"message":"Insufficient credits","code":402,"metadata":{"provider_name":null}},"user_id":"user_123"}`,
+			want:       OpenAIError{Type: "unknown_error", Message: "Insufficient credits", Code: "402"},
+		},
+		{
+			name:       "groq nested",
+			statusCode: http.StatusNotFound,
+			body:       `{"error":{"message":"The model ` + "`llama-9`" + ` does not exist or you do not have access to it.","type":"invalid_request_error","code":"model_not_found"}}`,
+			want:       OpenAIError{Type: ErrTypeInvalidRequest, Message: "The model `llama-9` does not exist or you do not have access to it.", Code: ErrCodeModelNotFound},
+		},
+		{
+			name:       "ollama string error",
+			statusCode: http.StatusNotFound,
+			body:       `{"error":"model \"llama9\" not found, try pulling it first"}`,
+			want:       OpenAIError{Type: ErrTypeNotFound, Message: `model "llama9" not found, try pulling it first`},
+		},
+		{
+			name:       "gateway error list",
+			statusCode: http.StatusServiceUnavailable,
+			body:       `[{"error":{"code":503,"message":"The model is overloaded. Please try again later.","status":"UNAVAILABLE"}}]`,
+			want:       OpenAIError{Type: ErrTypeServiceUnavailable, Message: "The model is overloaded. Please try again later.", Code: "503"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewOpenAIError(tt.statusCode, []byte(tt.body))
+
+			apiErr, ok := err.(*OpenAIError)
+			if !ok {
+				t.Fatalf("expected *OpenAIError, got %T", err)
+			}
+			tt.want.StatusCode = tt.statusCode
+			if *apiErr != tt.want {
+				t.Errorf("got %+v, want %+v", *apiErr, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewOpenAIError_EmptyList(t *testing.T) {
+	err := NewOpenAIError(http.StatusBadGateway, []byte(`[]`))
+	if IsOpenAIError(err) {
+		t.Errorf("expected a plain error, got %T", err)
+	}
+}
+
 func TestErrorConstructors(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -312,3 +380,29 @@ func TestNewOpenAIError_StatusCodeMapping(t *testing.T) {
 		})
 	}
 }
+
+func FuzzNewOpenAIError(f *testing.F) {
+	f.Add(http.StatusBadRequest, []byte(`{"type":"invalid_request_error","message":"Invalid model","code":"invalid_model"}`))
+	f.Add(http.StatusBadRequest, []byte(`{"error":{"message":"filtered","code":"content_filter","innererror":{"code":"ResponsibleAIPolicyViolation"}}}`))
+	f.Add(http.StatusBadRequest, []byte(`{"code":"context_length_exceeded","message":"maximum context length is 8192 tokens. However, your messages resulted in 9000 tokens"}`))
+	f.Add(http.StatusInternalServerError, []byte(`<html>bad gateway</html>`))
+	f.Add(http.StatusTooManyRequests, []byte(`null`))
+
+	f.Fuzz(func(t *testing.T, statusCode int, body []byte) {
+		err := NewOpenAIError(statusCode, body)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		_ = err.Error()
+
+		var apiErr *OpenAIError
+		if errors.As(err, &apiErr) {
+			if apiErr.StatusCode != statusCode {
+				t.Errorf("expected status code %d, got %d", statusCode, apiErr.StatusCode)
+			}
+			if apiErr.Type == "" {
+				t.Error("expected error type to be set")
+			}
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package openaiclient
+
+import (
+	"context"
+	"math"
+)
+
+type (
+	// Variant is one arm of an experiment. Build returns a fresh payload for
+	// each input, so prompts and parameters can differ freely between arms.
+	Variant struct {
+		Name  string
+		Build func(input string) *CompletionRequestPayload
+	}
+
+	// Judge scores the output a variant produced for an input. Higher scores
+	// are better.
+	Judge func(ctx context.Context, input, output string) (float64, error)
+
+	Experiment struct {
+		Variants []Variant
+		Inputs   []string
+		// Judge, when set, scores every successful output.
+		Judge Judge
+	}
+
+	Trial struct {
+		Variant string
+		Input   string
+		Output  string
+		Score   float64
+		Scored  bool
+		Err     error
+	}
+
+	VariantSummary struct {
+		Name   string
+		Trials int
+		Errors int
+		// MeanScore and StdDev are computed over the scored trials only.
+		Scored    int
+		MeanScore float64
+		StdDev    float64
+	}
+
+	ExperimentResult struct {
+		Trials    []Trial
+		Summaries []VariantSummary
+	}
+)
+
+// RunExperiment assigns the inputs to the variants in turn, so every variant
+// is tried on a balanced share of the dataset, and collects the outputs and
+// judge scores. Failed completions are recorded on their trial rather than
+// aborting the experiment; only a canceled context stops it early.
+func (o *OpenAI) RunExperiment(ctx context.Context, experiment *Experiment) (*ExperimentResult, error) {
+	if len(experiment.Variants) < 2 {
+		return nil, NewInvalidRequestError("an experiment needs at least two variants")
+	}
+
+	result := &ExperimentResult{}
+	for i, input := range experiment.Inputs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		variant := experiment.Variants[i%len(experiment.Variants)]
+		result.Trials = append(result.Trials, o.runTrial(ctx, experiment.Judge, variant, input))
+	}
+
+	for _, variant := range experiment.Variants {
+		result.Summaries = append(result.Summaries, summarize(variant.Name, result.Trials))
+	}
+	return result, nil
+}
+
+func (o *OpenAI) runTrial(ctx context.Context, judge Judge, variant Variant, input string) Trial {
+	trial := Trial{Variant: variant.Name, Input: input}
+
+	message, err := o.GetCompletionContext(ctx, variant.Build(input))
+	if err != nil {
+		trial.Err = err
+		return trial
+	}
+	trial.Output = message.Content
+
+	if judge != nil {
+		if trial.Score, err = judge(ctx, input, trial.Output); err != nil {
+			trial.Err = err
+			return trial
+		}
+		trial.Scored = true
+	}
+	return trial
+}
+
+func summarize(name string, trials []Trial) VariantSummary {
+	summary := VariantSummary{Name: name}
+	var sum, sumSquares float64
+	for _, trial := range trials {
+		if trial.Variant != name {
+			continue
+		}
+		summary.Trials++
+		if trial.Err != nil {
+			summary.Errors++
+		}
+		if trial.Scored {
+			summary.Scored++
+			sum += trial.Score
+			sumSquares += trial.Score * trial.Score
+		}
+	}
+
+	if summary.Scored > 0 {
+		n := float64(summary.Scored)
+		summary.MeanScore = sum / n
+		summary.StdDev = math.Sqrt(max(sumSquares/n-summary.MeanScore*summary.MeanScore, 0))
+	}
+	return summary
+}
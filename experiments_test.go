@@ -0,0 +1,97 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestRunExperiment(t *testing.T) {
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			var payload CompletionRequestPayload
+			body, _ := io.ReadAll(req.Body)
+			json.Unmarshal(body, &payload)
+			if payload.Model == "broken-model" {
+				return fakeResponse(http.StatusBadRequest, `{"message":"bad"}`), nil
+			}
+			content, _ := json.Marshal(payload.Messages[0].Content + ":" + payload.Messages[1].Content)
+			return fakeResponse(http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":`+string(content)+`}}]}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	variant := func(name, model string) Variant {
+		return Variant{
+			Name: name,
+			Build: func(input string) *CompletionRequestPayload {
+				return &CompletionRequestPayload{
+					Model: model,
+					Messages: []Message{
+						{Role: MessageRoleSystem, Content: name},
+						{Role: MessageRoleUser, Content: input},
+					},
+				}
+			},
+		}
+	}
+
+	scores := map[string]float64{"a:1": 1, "b:2": 4}
+	result, err := client.RunExperiment(context.Background(), &Experiment{
+		Variants: []Variant{variant("a", "test-model"), variant("b", "test-model"), variant("c", "broken-model")},
+		Inputs:   []string{"1", "2", "3", "4", "5"},
+		Judge: func(ctx context.Context, input, output string) (float64, error) {
+			if score, ok := scores[output]; ok {
+				return score, nil
+			}
+			return 0, errors.New("unscored output")
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Trials) != 5 {
+		t.Fatalf("expected 5 trials, got %d", len(result.Trials))
+	}
+	if trial := result.Trials[3]; trial.Variant != "a" || trial.Input != "4" || trial.Err == nil {
+		t.Errorf("expected input 4 to be assigned to a and fail judging, got %+v", trial)
+	}
+
+	a, b, c := result.Summaries[0], result.Summaries[1], result.Summaries[2]
+	if a.Trials != 2 || a.Errors != 1 || a.Scored != 1 || a.MeanScore != 1 {
+		t.Errorf("unexpected summary for a: %+v", a)
+	}
+	if b.Trials != 2 || b.Errors != 1 || b.Scored != 1 || b.MeanScore != 4 {
+		t.Errorf("unexpected summary for b: %+v", b)
+	}
+	if c.Trials != 1 || c.Errors != 1 || c.Scored != 0 {
+		t.Errorf("unexpected summary for c: %+v", c)
+	}
+}
+
+func TestSummarize_StdDev(t *testing.T) {
+	trials := []Trial{
+		{Variant: "a", Score: 2, Scored: true},
+		{Variant: "a", Score: 4, Scored: true},
+		{Variant: "b", Score: 100, Scored: true},
+	}
+
+	summary := summarize("a", trials)
+	if summary.MeanScore != 3 || summary.StdDev != 1 {
+		t.Errorf("expected mean 3 and std dev 1, got %+v", summary)
+	}
+}
+
+func TestRunExperiment_RequiresTwoVariants(t *testing.T) {
+	client := createClient(t)
+
+	if _, err := client.RunExperiment(context.Background(), &Experiment{Variants: []Variant{{Name: "a"}}}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,77 @@
+package openaiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+)
+
+// MarshalJSON merges ExtraBody into the serialized payload.
+func (c CompletionRequestPayload) MarshalJSON() ([]byte, error) {
+	type payload CompletionRequestPayload
+	return marshalWithExtraBody(payload(c), c.ExtraBody)
+}
+
+// MarshalJSON sends Inputs or Tokens as the input when set, and merges
+// ExtraBody into the serialized payload.
+func (p GetEmbeddingPayload) MarshalJSON() ([]byte, error) {
+	type payload GetEmbeddingPayload
+	var input any
+	switch {
+	case p.Inputs != nil:
+		input = p.Inputs
+	case p.Tokens != nil:
+		input = p.Tokens
+	default:
+		return marshalWithExtraBody(payload(p), p.ExtraBody)
+	}
+
+	extra := map[string]any{"input": input}
+	maps.Copy(extra, p.ExtraBody)
+	return marshalWithExtraBody(payload(p), extra)
+}
+
+// MarshalJSON merges ExtraBody into the serialized payload.
+func (p CreateBatchPayload) MarshalJSON() ([]byte, error) {
+	type payload CreateBatchPayload
+	return marshalWithExtraBody(payload(p), p.ExtraBody)
+}
+
+// MarshalJSON merges ExtraBody into the serialized payload.
+func (p UpdateProjectRateLimitPayload) MarshalJSON() ([]byte, error) {
+	type payload UpdateProjectRateLimitPayload
+	return marshalWithExtraBody(payload(p), p.ExtraBody)
+}
+
+// marshalWithExtraBody serializes v and merges extra into it. Callers pass the
+// payload converted to a type without the MarshalJSON method to avoid
+// recursing into it.
+func marshalWithExtraBody(v any, extra map[string]any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return mergeExtraBody(data, extra)
+}
+
+// mergeExtraBody adds the extra fields to the JSON object in data. Extra
+// fields take precedence over the ones already present, so they can also
+// override what the library sends.
+func mergeExtraBody(data []byte, extra map[string]any) ([]byte, error) {
+	if len(extra) == 0 {
+		return data, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range extra {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling extra body field %q: %w", key, err)
+		}
+		fields[key] = encoded
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,105 @@
+package openaiclient
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestCompletionRequestPayload_ExtraBody(t *testing.T) {
+	var body map[string]any
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			data, _ := io.ReadAll(req.Body)
+			json.Unmarshal(data, &body)
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	payload := &CompletionRequestPayload{
+		Model:    "test-model",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+		ExtraBody: map[string]any{
+			"guided_regex": "[0-9]+",
+			"model":        "override-model",
+		},
+	}
+	if _, err := client.GetCompletion(payload); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if body["guided_regex"] != "[0-9]+" {
+		t.Errorf("expected guided_regex to be sent, got %v", body["guided_regex"])
+	}
+	if body["model"] != "override-model" {
+		t.Errorf("expected extra body to override model, got %v", body["model"])
+	}
+	if _, ok := body["messages"]; !ok {
+		t.Error("expected messages to be sent")
+	}
+}
+
+func TestCompletionRequestPayload_NoExtraBody(t *testing.T) {
+	data, err := json.Marshal(&CompletionRequestPayload{Model: "test-model", Messages: []Message{}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(data) != `{"model":"test-model","messages":[]}` {
+		t.Errorf("unexpected payload %s", data)
+	}
+}
+
+func TestMergeExtraBody_InvalidValue(t *testing.T) {
+	if _, err := mergeExtraBody([]byte(`{}`), map[string]any{"bad": func() {}}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestGetEmbeddingPayload_ExtraBody(t *testing.T) {
+	var body map[string]any
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			data, _ := io.ReadAll(req.Body)
+			json.Unmarshal(data, &body)
+			return fakeResponse(http.StatusOK, `{"data":[{"embedding":[0.1]}]}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	payload := GetEmbeddingPayload{
+		Model:     "test-model",
+		Input:     "Hi",
+		ExtraBody: map[string]any{"dimensions": 256},
+	}
+	if _, err := client.GetEmbedding(payload); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if body["dimensions"] != float64(256) || body["input"] != "Hi" {
+		t.Errorf("unexpected request body %v", body)
+	}
+}
+
+func TestCreateBatchPayload_ExtraBody(t *testing.T) {
+	data, err := json.Marshal(&CreateBatchPayload{
+		InputFileId:      "file-1",
+		Endpoint:         completionsEndpont,
+		CompletionWindow: "24h",
+		ExtraBody:        map[string]any{"output_expires_after": map[string]any{"anchor": "created_at"}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var body map[string]any
+	json.Unmarshal(data, &body)
+	if _, ok := body["output_expires_after"]; !ok || body["input_file_id"] != "file-1" {
+		t.Errorf("unexpected payload %s", data)
+	}
+}
@@ -0,0 +1,72 @@
+package openaiclient
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+)
+
+const ErrCodeModelNotFound = "model_not_found"
+
+// WithFallbackModels returns a copy of the client that falls back to the
+// given models, in order, when a completion fails because the model is not
+// found, rate limited or timed out. The copy shares the underlying HTTP
+// client and rate limit pacing.
+func (o *OpenAI) WithFallbackModels(models ...string) *OpenAI {
+	client := *o
+	client.FallbackModels = models
+	return &client
+}
+
+// postCompletionWithFallback sends the completion, moving down the fallback
+// chain on eligible failures. payload.Model is left set to the model that
+// served the request so later iterations of the ReAct loop stick to it.
+func (o *OpenAI) postCompletionWithFallback(ctx context.Context, payload *CompletionRequestPayload) ([]byte, error) {
+	responseText, err := o.postCompletion(ctx, payload)
+	for _, model := range o.fallbacksAfter(payload.Model) {
+		if err == nil || !shouldFallback(ctx, err) {
+			break
+		}
+		if !modelAllowed(ctx, model) {
+			continue
+		}
+
+		o.warn(ctx, WarningModelFallback, "falling back to next model",
+			slog.String("from", payload.Model),
+			slog.String("to", model),
+			slog.Any("error", err),
+		)
+		payload.Model = model
+		responseText, err = o.postCompletion(ctx, payload)
+	}
+	return responseText, err
+}
+
+// fallbacksAfter returns the fallback models that follow model in the chain,
+// or the whole chain when model is not part of it.
+func (o *OpenAI) fallbacksAfter(model string) []string {
+	if i := slices.Index(o.FallbackModels, model); i >= 0 {
+		return o.FallbackModels[i+1:]
+	}
+	return o.FallbackModels
+}
+
+func shouldFallback(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *OpenAIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == ErrCodeModelNotFound ||
+			apiErr.Type == ErrTypeRateLimit ||
+			apiErr.StatusCode == http.StatusRequestTimeout ||
+			apiErr.StatusCode == http.StatusGatewayTimeout
+	}
+
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
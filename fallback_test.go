@@ -0,0 +1,86 @@
+package openaiclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestWithFallbackModels(t *testing.T) {
+	var models []string
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			var payload CompletionRequestPayload
+			json.NewDecoder(req.Body).Decode(&payload)
+			models = append(models, payload.Model)
+			switch payload.Model {
+			case "gpt-primary":
+				return fakeResponse(http.StatusNotFound, `{"type":"invalid_request_error","message":"The model does not exist","code":"model_not_found"}`), nil
+			case "gpt-fallback":
+				return fakeResponse(http.StatusBadRequest, `{"type":"rate_limit_error","message":"Rate limit reached"}`), nil
+			}
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+
+	base := createClient(t)
+	base.client = fakeClient
+	client := base.WithFallbackModels("gpt-fallback", "gpt-last")
+
+	if len(base.FallbackModels) != 0 {
+		t.Errorf("expected original client to be unchanged, got %v", base.FallbackModels)
+	}
+
+	payload := &CompletionRequestPayload{
+		Model:    "gpt-primary",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	}
+	if _, err := client.GetCompletion(payload); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(models) != 3 || models[0] != "gpt-primary" || models[1] != "gpt-fallback" || models[2] != "gpt-last" {
+		t.Errorf("unexpected model sequence %v", models)
+	}
+	if payload.Model != "gpt-last" {
+		t.Errorf("expected payload to record serving model 'gpt-last', got '%s'", payload.Model)
+	}
+}
+
+func TestWithFallbackModels_IneligibleError(t *testing.T) {
+	seqClient := &SequentialFakeClient{
+		Responses: []*http.Response{
+			fakeResponse(http.StatusUnauthorized, `{"type":"authentication_error","message":"Invalid API key"}`),
+			fakeResponse(http.StatusOK, completionBody),
+		},
+	}
+
+	base := createClient(t)
+	base.client = seqClient
+	client := base.WithFallbackModels("gpt-fallback")
+
+	payload := &CompletionRequestPayload{
+		Model:    "gpt-primary",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	}
+	if _, err := client.GetCompletion(payload); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if seqClient.CallCount != 1 {
+		t.Errorf("expected 1 call, got %d", seqClient.CallCount)
+	}
+}
+
+func TestFallbacksAfter(t *testing.T) {
+	client := createClient(t).WithFallbackModels("a", "b", "c")
+
+	if got := client.fallbacksAfter("b"); len(got) != 1 || got[0] != "c" {
+		t.Errorf("expected [c], got %v", got)
+	}
+	if got := client.fallbacksAfter("primary"); len(got) != 3 {
+		t.Errorf("expected whole chain, got %v", got)
+	}
+	if got := client.fallbacksAfter("c"); len(got) != 0 {
+		t.Errorf("expected no fallbacks after the last model, got %v", got)
+	}
+}
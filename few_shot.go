@@ -0,0 +1,97 @@
+package openaiclient
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+)
+
+type (
+	// Example is a labeled input and the output expected for it.
+	Example struct {
+		Input  string
+		Output string
+	}
+
+	// ExampleSelector picks the examples of a pool most similar to an input,
+	// to use as few-shot examples in its prompt. The pool is embedded in one
+	// batch request on first use and cached, so only the input is embedded
+	// on later calls. It is safe for concurrent use.
+	ExampleSelector struct {
+		client   *OpenAI
+		model    string
+		examples []Example
+		k        int
+
+		embeddings embeddingCache
+	}
+)
+
+// NewExampleSelector returns a selector of the k examples most similar to an
+// input, embedded with the given model. A negative k is an error.
+func (o *OpenAI) NewExampleSelector(model string, examples []Example, k int) (*ExampleSelector, error) {
+	if k < 0 {
+		return nil, NewInvalidRequestError(fmt.Sprintf("k must not be negative, got %d", k))
+	}
+	return &ExampleSelector{
+		client:   o,
+		model:    model,
+		examples: examples,
+		k:        k,
+	}, nil
+}
+
+// Select returns the k examples most similar to input, the most similar
+// first.
+func (s *ExampleSelector) Select(ctx context.Context, input string) ([]Example, error) {
+	target, err := s.client.GetEmbeddingContext(ctx, GetEmbeddingPayload{Model: s.model, Input: input})
+	if err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		example    Example
+		similarity float64
+	}
+	inputs := make([]string, len(s.examples))
+	for i, example := range s.examples {
+		inputs[i] = example.Input
+	}
+	embeddings, err := s.embeddings.get(ctx, s.client, s.model, inputs)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]scored, 0, len(s.examples))
+	for i, example := range s.examples {
+		candidates = append(candidates, scored{example, CosineSimilarity(target, embeddings[i])})
+	}
+	slices.SortStableFunc(candidates, func(a, b scored) int {
+		return cmp.Compare(b.similarity, a.similarity)
+	})
+
+	selected := make([]Example, 0, s.k)
+	for _, candidate := range candidates[:min(s.k, len(candidates))] {
+		selected = append(selected, candidate.example)
+	}
+	return selected, nil
+}
+
+// Messages returns the selected examples as user and assistant turns, to
+// place between the system prompt and the input. The most similar example
+// comes last, closest to the input.
+func (s *ExampleSelector) Messages(ctx context.Context, input string) ([]Message, error) {
+	examples, err := s.Select(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]Message, 0, 2*len(examples))
+	for _, example := range slices.Backward(examples) {
+		messages = append(messages,
+			Message{Role: MessageRoleUser, Content: example.Input},
+			Message{Role: MessageRoleAssistant, Content: example.Output},
+		)
+	}
+	return messages, nil
+}
@@ -0,0 +1,74 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestExampleSelector(t *testing.T) {
+	vectors := map[string][]float64{
+		"refund my order":      {1, 0, 0},
+		"where is my parcel":   {0, 1, 0},
+		"cancel my account":    {0, 0, 1},
+		"I want my money back": {0.9, 0.1, 0},
+	}
+	embedded := map[string]int{}
+	requests := 0
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			requests++
+			var payload struct {
+				Input json.RawMessage `json:"input"`
+			}
+			body, _ := io.ReadAll(req.Body)
+			json.Unmarshal(body, &payload)
+			var inputs []string
+			if json.Unmarshal(payload.Input, &inputs) != nil {
+				inputs = []string{""}
+				json.Unmarshal(payload.Input, &inputs[0])
+			}
+			var data []EmbeddingObject
+			for i, input := range inputs {
+				embedded[input]++
+				data = append(data, EmbeddingObject{Index: i, Embedding: vectors[input]})
+			}
+			response, _ := json.Marshal(GetEmbeddingResponse{Data: data})
+			return fakeResponse(http.StatusOK, string(response)), nil
+		},
+	}
+
+	if _, err := client.NewExampleSelector("text-embedding-3-small", nil, -1); GetOpenAIErrorType(err) != ErrTypeInvalidRequest {
+		t.Errorf("expected an invalid request error for a negative k, got %v", err)
+	}
+	selector, err := client.NewExampleSelector("text-embedding-3-small", []Example{
+		{Input: "refund my order", Output: "billing"},
+		{Input: "where is my parcel", Output: "shipping"},
+		{Input: "cancel my account", Output: "account"},
+	}, 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	examples, err := selector.Select(context.Background(), "I want my money back")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(examples) != 2 || examples[0].Output != "billing" || examples[1].Output != "shipping" {
+		t.Errorf("expected the two most similar examples, got %+v", examples)
+	}
+
+	messages, err := selector.Messages(context.Background(), "I want my money back")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(messages) != 4 || messages[3].Content != "billing" || messages[2].Role != MessageRoleUser {
+		t.Errorf("expected the most similar example last, got %+v", messages)
+	}
+	if embedded["refund my order"] != 1 || embedded["I want my money back"] != 2 || requests != 3 {
+		t.Errorf("expected the examples to be embedded once in a batch, got %v in %d requests", embedded, requests)
+	}
+}
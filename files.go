@@ -0,0 +1,109 @@
+package openaiclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+const (
+	FilePurposeBatch     = "batch"
+	FilePurposeFineTune  = "fine-tune"
+	FilePurposeAssistant = "assistants"
+)
+
+type File struct {
+	Id        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int    `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+}
+
+// UploadFile uploads the contents of r as a multipart form under the given
+// filename and purpose.
+func (o *OpenAI) UploadFile(ctx context.Context, filename, purpose string, r io.Reader) (*File, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("purpose", purpose); err != nil {
+		return nil, fmt.Errorf("error writing multipart form: %w", err)
+	}
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, fmt.Errorf("error writing multipart form: %w", err)
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return nil, fmt.Errorf("error writing multipart form: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("error writing multipart form: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, o.endpoint(ctx, filesEndpoint), bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	request.Header.Add("Content-Type", writer.FormDataContentType())
+	request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", o.keyFor(filesEndpoint)))
+	o.setBetaHeader(request, filesEndpoint)
+	o.setAccountHeaders(request)
+	setCorrelationHeader(request)
+
+	responseText, err := o.doRequest(request, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var file File
+	if err := unmarshalResponse(responseText, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (o *OpenAI) GetFile(ctx context.Context, fileId string) (*File, error) {
+	var file File
+	if err := o.get(ctx, fileEndpoint(fileId), nil, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// GetFileContent downloads the raw contents of a file.
+func (o *OpenAI) GetFileContent(ctx context.Context, fileId string) ([]byte, error) {
+	request, err := o.createAuthorizedRequest(ctx, http.MethodGet, fileEndpoint(fileId)+"/content", nil)
+	if err != nil {
+		return nil, err
+	}
+	return o.doRequest(request, true)
+}
+
+func (o *OpenAI) ListFiles(ctx context.Context, params *ListParams, purpose string) (*List[File], error) {
+	query := params.values()
+	if purpose != "" {
+		query.Set("purpose", purpose)
+	}
+
+	var files List[File]
+	if err := o.get(ctx, filesEndpoint, query, &files); err != nil {
+		return nil, err
+	}
+	return &files, nil
+}
+
+func (o *OpenAI) DeleteFile(ctx context.Context, fileId string) (*DeletedObject, error) {
+	var deleted DeletedObject
+	if err := o.call(ctx, http.MethodDelete, fileEndpoint(fileId), nil, &deleted); err != nil {
+		return nil, err
+	}
+	return &deleted, nil
+}
+
+func fileEndpoint(fileId string) string {
+	return filesEndpoint + "/" + url.PathEscape(fileId)
+}
@@ -0,0 +1,67 @@
+package openaiclient
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+type HealthStatus struct {
+	Healthy        bool          `json:"healthy"`
+	Provider       string        `json:"provider"`
+	Model          string        `json:"model"`
+	ModelAvailable bool          `json:"model_available"`
+	Latency        time.Duration `json:"latency"`
+	Error          string        `json:"error,omitempty"`
+}
+
+func (o *OpenAI) ListModels(ctx context.Context) ([]Model, error) {
+	var responseBody ListModelsResponse
+	if err := o.get(ctx, modelsEndpoint, nil, &responseBody); err != nil {
+		return nil, err
+	}
+	return responseBody.Data, nil
+}
+
+// Ping performs a lightweight models listing against the configured provider
+// and returns an error if it cannot be reached or rejects the credentials.
+func (o *OpenAI) Ping(ctx context.Context) error {
+	_, err := o.ListModels(ctx)
+	return err
+}
+
+// Healthy reports the provider's status, the round-trip latency and whether
+// the default model is served. It never returns nil, so it can be wired
+// directly into readiness probes.
+func (o *OpenAI) Healthy(ctx context.Context) *HealthStatus {
+	status := &HealthStatus{
+		Provider: o.provider(),
+		Model:    o.defaultModel(),
+	}
+
+	start := time.Now()
+	models, err := o.ListModels(ctx)
+	status.Latency = time.Since(start)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+
+	status.Healthy = true
+	for _, model := range models {
+		if model.Id == status.Model {
+			status.ModelAvailable = true
+			break
+		}
+	}
+
+	return status
+}
+
+func (o *OpenAI) provider() string {
+	u, err := url.Parse(o.baseUrl)
+	if err != nil || u.Host == "" {
+		return o.baseUrl
+	}
+	return u.Host
+}
@@ -0,0 +1,101 @@
+package openaiclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestHealthy_ModelAvailable(t *testing.T) {
+	t.Setenv("OPENAI_MODEL", "test-model")
+
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet {
+				t.Errorf("expected GET request, got %s", req.Method)
+			}
+			if req.URL.Path != modelsEndpoint {
+				t.Errorf("expected path %s, got %s", modelsEndpoint, req.URL.Path)
+			}
+			return fakeResponse(200, `{"object":"list","data":[{"id":"other-model"},{"id":"test-model"}]}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	status := client.Healthy(context.Background())
+	if !status.Healthy {
+		t.Fatalf("expected healthy status, got error %q", status.Error)
+	}
+	if !status.ModelAvailable {
+		t.Errorf("expected model 'test-model' to be available")
+	}
+	if status.Provider != "example.com" {
+		t.Errorf("expected provider 'example.com', got '%s'", status.Provider)
+	}
+}
+
+func TestHealthy_ModelUnavailable(t *testing.T) {
+	t.Setenv("OPENAI_MODEL", "missing-model")
+
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return fakeResponse(200, `{"object":"list","data":[{"id":"test-model"}]}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	status := client.Healthy(context.Background())
+	if !status.Healthy {
+		t.Fatalf("expected healthy status, got error %q", status.Error)
+	}
+	if status.ModelAvailable {
+		t.Errorf("expected model 'missing-model' to be unavailable")
+	}
+}
+
+func TestPing_Error(t *testing.T) {
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return fakeResponse(http.StatusUnauthorized, `{"message":"Invalid API key"}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	err := client.Ping(context.Background())
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if GetOpenAIErrorType(err) != ErrTypeAuthentication {
+		t.Errorf("expected authentication error, got %v", err)
+	}
+
+	status := client.Healthy(context.Background())
+	if status.Healthy {
+		t.Errorf("expected unhealthy status")
+	}
+	if status.Error == "" {
+		t.Errorf("expected status error to be set")
+	}
+}
+
+func TestPing_ClientError(t *testing.T) {
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	if err := client.Ping(context.Background()); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
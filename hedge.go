@@ -0,0 +1,119 @@
+package openaiclient
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type hedgeResult struct {
+	index    int
+	target   *OpenAI
+	response *http.Response
+	err      error
+}
+
+// sendHedged sends the request and, if no response arrived within
+// HedgeDelay, a second copy to the hedge target. The first successful
+// response wins and the other request is canceled. A request that fails
+// before the delay is not hedged; the retry loop handles it.
+func (o *OpenAI) sendHedged(request *http.Request) ([]byte, int, error) {
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	launch := func(target *OpenAI, request *http.Request) {
+		ctx, cancel := context.WithCancel(request.Context())
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			response, err := target.client.Do(request.WithContext(ctx))
+			results <- hedgeResult{index: index, target: target, response: response, err: err}
+		}()
+	}
+
+	launch(o, request)
+	timer := time.NewTimer(o.HedgeDelay)
+	defer timer.Stop()
+
+	var firstErr error
+	for received := 0; received < len(cancels); {
+		select {
+		case <-timer.C:
+			hedge, err := o.hedgeRequest(request)
+			if err != nil {
+				o.warn(request.Context(), WarningHedgeFailed, "error creating hedged request", slog.Any("error", err))
+				continue
+			}
+			slog.Debug("hedging request", slog.String("endpoint", request.URL.Path), correlationAttr(request.Context()))
+			launch(o.hedgeTarget(), hedge)
+		case result := <-results:
+			received++
+			if result.err != nil {
+				cancels[result.index]()
+				if firstErr == nil {
+					firstErr = result.err
+				}
+				continue
+			}
+
+			for i, cancel := range cancels {
+				if i != result.index {
+					cancel()
+				}
+			}
+			go discardHedges(results, len(cancels)-received)
+
+			defer cancels[result.index]()
+			return result.target.readResponse(result.response)
+		}
+	}
+
+	return nil, 0, fmt.Errorf("error making request: %w", firstErr)
+}
+
+func (o *OpenAI) hedgeTarget() *OpenAI {
+	if o.HedgeTarget != nil {
+		return o.HedgeTarget
+	}
+	return o
+}
+
+// hedgeRequest copies the request, pointing it at the hedge target's base
+// URL and credentials when it differs from this client.
+func (o *OpenAI) hedgeRequest(request *http.Request) (*http.Request, error) {
+	hedge, err := rewindRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	// The hedge is a deliberate duplicate, so it must not be deduplicated
+	// against the request it races.
+	if hedge.Header.Get(idempotencyKeyHeader) != "" {
+		hedge.Header.Set(idempotencyKeyHeader, newIdempotencyKey())
+	}
+
+	target := o.hedgeTarget()
+	if target == o {
+		return hedge, nil
+	}
+
+	endpoint := o.endpointOf(request.URL.String())
+	hedge.URL, err = url.Parse(target.endpoint(request.Context(), endpoint))
+	if err != nil {
+		return nil, err
+	}
+	hedge.Host = hedge.URL.Host
+	hedge.Header.Set("Authorization", fmt.Sprintf("Bearer %s", target.keyFor(endpoint)))
+	return hedge, nil
+}
+
+// discardHedges closes the response bodies of the canceled requests that lost
+// the race once they return.
+func discardHedges(results <-chan hedgeResult, pending int) {
+	for range pending {
+		if result := <-results; result.response != nil {
+			result.response.Body.Close()
+		}
+	}
+}
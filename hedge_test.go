@@ -0,0 +1,99 @@
+package openaiclient
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSendHedged_HedgeWins(t *testing.T) {
+	var calls atomic.Int32
+	canceled := make(chan struct{})
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if calls.Add(1) == 1 {
+				<-req.Context().Done()
+				close(canceled)
+				return nil, req.Context().Err()
+			}
+			return fakeResponse(http.StatusOK, `{"data":[{"embedding":[0.5]}]}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+	client.HedgeDelay = 10 * time.Millisecond
+
+	embedding, err := client.GetEmbedding(GetEmbeddingPayload{Model: "test-model", Input: "Hi"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(embedding) != 1 || embedding[0] != 0.5 {
+		t.Errorf("expected hedged response, got %v", embedding)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("expected slow request to be canceled")
+	}
+}
+
+func TestSendHedged_FastPrimaryIsNotHedged(t *testing.T) {
+	var calls atomic.Int32
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			calls.Add(1)
+			return fakeResponse(http.StatusOK, `{"data":[{"embedding":[0.1]}]}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+	client.HedgeDelay = time.Second
+
+	if _, err := client.GetEmbedding(GetEmbeddingPayload{Model: "test-model", Input: "Hi"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 call, got %d", calls.Load())
+	}
+}
+
+func TestSendHedged_FallbackProvider(t *testing.T) {
+	primary := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		},
+	}
+	var hedgedRequest *http.Request
+	fallback := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			hedgedRequest = req
+			return fakeResponse(http.StatusOK, `{"data":[{"embedding":[0.9]}]}`), nil
+		},
+	}
+
+	target, err := New("http://fallback.example.com/openai", "fallback-key")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	target.client = fallback
+
+	client := createClient(t)
+	client.client = primary
+	client.HedgeDelay = 10 * time.Millisecond
+	client.HedgeTarget = target
+
+	if _, err := client.GetEmbedding(GetEmbeddingPayload{Model: "test-model", Input: "Hi"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if hedgedRequest.URL.String() != "http://fallback.example.com/openai"+embeddingsEndpoint {
+		t.Errorf("unexpected hedged URL %s", hedgedRequest.URL)
+	}
+	if hedgedRequest.Header.Get("Authorization") != "Bearer fallback-key" {
+		t.Errorf("expected fallback credentials, got '%s'", hedgedRequest.Header.Get("Authorization"))
+	}
+}
@@ -4,15 +4,20 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 )
 
 func createRequest(method, endpoint string, body any) (*http.Request, error) {
-	bodyJson, err := json.Marshal(body)
-	if err != nil {
-		return nil, fmt.Errorf("error marshaling request body: %w", err)
+	var bodyReader io.Reader
+	if body != nil {
+		bodyJson, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling request body: %w", err)
+		}
+		bodyReader = bytes.NewReader(bodyJson)
 	}
-	request, err := http.NewRequest(method, endpoint, bytes.NewReader(bodyJson))
+	request, err := http.NewRequest(method, endpoint, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
@@ -31,3 +36,10 @@ func createAuthorizedRequest(method, endpoint string, body any, bearer string) (
 
 	return request, nil
 }
+
+func unmarshalResponse(responseText []byte, out any) error {
+	if err := json.Unmarshal(responseText, out); err != nil {
+		return fmt.Errorf("error unmarshaling response body: %w", err)
+	}
+	return nil
+}
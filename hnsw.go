@@ -0,0 +1,214 @@
+package openaiclient
+
+import (
+	"cmp"
+	"container/heap"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+const (
+	defaultHNSWNeighbors      = 16
+	defaultHNSWEfConstruction = 200
+	defaultHNSWEfSearch       = 64
+)
+
+type (
+	// HNSWConfig configures the approximate search of an Index. Larger
+	// values improve recall at the cost of build time, memory and search
+	// time. Zero values use the defaults.
+	HNSWConfig struct {
+		// Neighbors is the number of links kept per entry and layer, twice as
+		// many on the bottom layer. It defaults to 16.
+		Neighbors int
+		// EfConstruction is the number of candidates considered when linking
+		// a new entry. It defaults to 200.
+		EfConstruction int
+		// EfSearch is the number of candidates considered by a search, at
+		// least topK. It defaults to 64.
+		EfSearch int
+	}
+
+	// hnswGraph is a hierarchical navigable small world graph over the
+	// vectors of an Index, see https://arxiv.org/abs/1603.09320.
+	hnswGraph struct {
+		config     HNSWConfig
+		levelScale float64
+		random     *rand.Rand
+		entry      int
+		maxLevel   int
+		// links holds the neighbors of every entry on each of its layers.
+		links [][][]int32
+	}
+
+	hnswCandidate struct {
+		id         int
+		similarity float64
+	}
+
+	// hnswQueue is a heap of candidates, the most similar on top unless
+	// worstFirst is set.
+	hnswQueue struct {
+		items      []hnswCandidate
+		worstFirst bool
+	}
+)
+
+// EnableHNSW switches the index to approximate search with a graph built
+// over its entries, which keeps searches fast on large indexes at the cost of
+// occasionally missing a match. The graph is kept up to date as entries are
+// added. It is not saved with the index, so it needs enabling again after
+// LoadIndex.
+func (x *Index) EnableHNSW(config HNSWConfig) {
+	if config.Neighbors <= 0 {
+		config.Neighbors = defaultHNSWNeighbors
+	}
+	if config.EfConstruction <= 0 {
+		config.EfConstruction = defaultHNSWEfConstruction
+	}
+	if config.EfSearch <= 0 {
+		config.EfSearch = defaultHNSWEfSearch
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.hnsw = &hnswGraph{
+		config:     config,
+		levelScale: 1 / math.Log(float64(config.Neighbors)),
+		random:     rand.New(rand.NewPCG(1, uint64(config.Neighbors))),
+		entry:      -1,
+	}
+	for i := range x.ids {
+		x.hnsw.insert(x, i)
+	}
+}
+
+func (g *hnswGraph) insert(x *Index, id int) {
+	level := int(-math.Log(1-g.random.Float64()) * g.levelScale)
+	g.links = append(g.links, make([][]int32, level+1))
+	if g.entry < 0 {
+		g.entry, g.maxLevel = id, level
+		return
+	}
+
+	query := x.vector(id)
+	entry := g.greedy(x, query, g.entry, g.maxLevel, level)
+	for layer := min(level, g.maxLevel); layer >= 0; layer-- {
+		candidates := g.searchLayer(x, query, entry, g.config.EfConstruction, layer, nil)
+		neighbors := candidates[:min(g.config.Neighbors, len(candidates))]
+		for _, neighbor := range neighbors {
+			g.links[id][layer] = append(g.links[id][layer], int32(neighbor.id))
+			g.link(x, neighbor.id, id, layer)
+		}
+		entry = candidates[0].id
+	}
+
+	if level > g.maxLevel {
+		g.entry, g.maxLevel = id, level
+	}
+}
+
+// link adds to as a neighbor of from, dropping the least similar neighbor
+// once from has too many.
+func (g *hnswGraph) link(x *Index, from, to, layer int) {
+	links := append(g.links[from][layer], int32(to))
+	limit := g.config.Neighbors
+	if layer == 0 {
+		limit *= 2
+	}
+	if len(links) > limit {
+		vector := x.vector(from)
+		slices.SortFunc(links, func(a, b int32) int {
+			return cmp.Compare(dot(vector, x.vector(int(b))), dot(vector, x.vector(int(a))))
+		})
+		links = links[:limit]
+	}
+	g.links[from][layer] = links
+}
+
+// greedy walks down from the top layer to the layer above bottom, moving to
+// the most similar neighbor on each, and returns where it ends.
+func (g *hnswGraph) greedy(x *Index, query []float32, entry, top, bottom int) int {
+	similarity := dot(query, x.vector(entry))
+	for layer := top; layer > bottom; layer-- {
+		for improved := true; improved; {
+			improved = false
+			for _, neighbor := range g.links[entry][layer] {
+				if s := dot(query, x.vector(int(neighbor))); s > similarity {
+					entry, similarity, improved = int(neighbor), s, true
+				}
+			}
+		}
+	}
+	return entry
+}
+
+// searchLayer returns the ef entries of the layer most similar to query that
+// it finds starting from entry, the most similar first. When accept is set,
+// the other entries are traversed but left out of the results.
+func (g *hnswGraph) searchLayer(x *Index, query []float32, entry, ef, layer int, accept func(int) bool) []hnswCandidate {
+	start := hnswCandidate{entry, dot(query, x.vector(entry))}
+	visited := map[int]bool{entry: true}
+	candidates := &hnswQueue{items: []hnswCandidate{start}}
+	results := &hnswQueue{worstFirst: true}
+	if accept == nil || accept(entry) {
+		results.items = append(results.items, start)
+	}
+
+	for candidates.Len() > 0 {
+		current := heap.Pop(candidates).(hnswCandidate)
+		if results.Len() >= ef && current.similarity < results.items[0].similarity {
+			break
+		}
+		for _, neighbor := range g.links[current.id][layer] {
+			id := int(neighbor)
+			if visited[id] {
+				continue
+			}
+			visited[id] = true
+
+			candidate := hnswCandidate{id, dot(query, x.vector(id))}
+			if results.Len() >= ef && candidate.similarity <= results.items[0].similarity {
+				continue
+			}
+			heap.Push(candidates, candidate)
+			if accept == nil || accept(id) {
+				heap.Push(results, candidate)
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(results.items, func(a, b hnswCandidate) int {
+		return cmp.Compare(b.similarity, a.similarity)
+	})
+	return results.items
+}
+
+func (g *hnswGraph) search(x *Index, query []float32, topK int, accept func(int) bool) []hnswCandidate {
+	entry := g.greedy(x, query, g.entry, g.maxLevel, 0)
+	candidates := g.searchLayer(x, query, entry, max(g.config.EfSearch, topK), 0, accept)
+	return candidates[:min(topK, len(candidates))]
+}
+
+func (q *hnswQueue) Len() int { return len(q.items) }
+
+func (q *hnswQueue) Less(i, j int) bool {
+	if q.worstFirst {
+		return q.items[i].similarity < q.items[j].similarity
+	}
+	return q.items[i].similarity > q.items[j].similarity
+}
+
+func (q *hnswQueue) Swap(i, j int) { q.items[i], q.items[j] = q.items[j], q.items[i] }
+
+func (q *hnswQueue) Push(item any) { q.items = append(q.items, item.(hnswCandidate)) }
+
+func (q *hnswQueue) Pop() any {
+	item := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return item
+}
@@ -0,0 +1,68 @@
+package openaiclient
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+)
+
+func randomVector(random *rand.Rand, dimensions int) []float64 {
+	vector := make([]float64, dimensions)
+	for i := range vector {
+		vector[i] = random.NormFloat64()
+	}
+	return vector
+}
+
+func TestIndex_HNSWRecall(t *testing.T) {
+	random := rand.New(rand.NewPCG(7, 7))
+	exact, approximate := NewIndex(), NewIndex()
+	approximate.EnableHNSW(HNSWConfig{})
+	for i := range 2000 {
+		vector := randomVector(random, 32)
+		exact.Add(fmt.Sprint(i), vector, nil)
+		approximate.Add(fmt.Sprint(i), vector, nil)
+	}
+
+	found, total := 0, 0
+	for range 20 {
+		query := randomVector(random, 32)
+		want, _ := exact.Search(query, 10)
+		got, err := approximate.Search(query, 10)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		ids := map[string]bool{}
+		for _, result := range got {
+			ids[result.Id] = true
+		}
+		for _, result := range want {
+			total++
+			if ids[result.Id] {
+				found++
+			}
+		}
+	}
+	if recall := float64(found) / float64(total); recall < 0.9 {
+		t.Errorf("expected a recall of at least 0.9, got %.2f", recall)
+	}
+}
+
+func TestIndex_EnableHNSWOnExistingEntries(t *testing.T) {
+	index := NewIndex()
+	index.Add("x", []float64{1, 0}, nil)
+	index.Add("y", []float64{0, 1}, nil)
+	index.EnableHNSW(HNSWConfig{Neighbors: 4})
+	index.Add("xy", []float64{1, 1}, nil)
+
+	results, err := index.Search([]float64{1, 0.1}, 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(results) != 2 || results[0].Id != "x" || results[1].Id != "xy" {
+		t.Errorf("expected x then xy, got %+v", results)
+	}
+	if _, err := index.Search([]float64{1, 0.1}, -1); GetOpenAIErrorType(err) != ErrTypeInvalidRequest {
+		t.Errorf("expected an error for a negative topK, got %v", err)
+	}
+}
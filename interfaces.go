@@ -0,0 +1,46 @@
+package openaiclient
+
+import (
+	"context"
+	"io"
+)
+
+// The interfaces below are implemented by *OpenAI, one per capability, so
+// code can depend on the capabilities it uses and replace them in tests.
+type (
+	CompletionClient interface {
+		GetCompletionContext(ctx context.Context, payload *CompletionRequestPayload) (*Message, error)
+	}
+
+	EmbeddingClient interface {
+		GetEmbeddingContext(ctx context.Context, payload GetEmbeddingPayload) ([]float64, error)
+		GetEmbeddings(ctx context.Context, payload GetEmbeddingPayload) ([][]float64, error)
+	}
+
+	ModelClient interface {
+		ListModels(ctx context.Context) ([]Model, error)
+	}
+
+	FileClient interface {
+		UploadFile(ctx context.Context, filename, purpose string, r io.Reader) (*File, error)
+		GetFile(ctx context.Context, fileId string) (*File, error)
+		GetFileContent(ctx context.Context, fileId string) ([]byte, error)
+		ListFiles(ctx context.Context, params *ListParams, purpose string) (*List[File], error)
+		DeleteFile(ctx context.Context, fileId string) (*DeletedObject, error)
+	}
+
+	BatchClient interface {
+		CreateBatch(ctx context.Context, payload *CreateBatchPayload) (*Batch, error)
+		GetBatch(ctx context.Context, batchId string) (*Batch, error)
+		CancelBatch(ctx context.Context, batchId string) (*Batch, error)
+		ListBatches(ctx context.Context, params *ListParams) (*List[Batch], error)
+	}
+)
+
+var (
+	_ CompletionClient = (*OpenAI)(nil)
+	_ EmbeddingClient  = (*OpenAI)(nil)
+	_ ModelClient      = (*OpenAI)(nil)
+	_ FileClient       = (*OpenAI)(nil)
+	_ BatchClient      = (*OpenAI)(nil)
+)
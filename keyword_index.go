@@ -0,0 +1,147 @@
+package openaiclient
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// reciprocalRankK dampens the weight of the top ranks in reciprocal rank
+	// fusion; 60 is the value of the original paper.
+	reciprocalRankK = 60
+)
+
+// KeywordIndex is an in-memory BM25 index over texts. It complements the
+// embeddings of an Index on exact identifiers and rare terms, see
+// HybridSearch. It is safe for concurrent use.
+type KeywordIndex struct {
+	mu          sync.RWMutex
+	ids         []string
+	metadata    []map[string]string
+	lengths     []int
+	totalLength int
+	// postings maps every term to the entries containing it and the
+	// number of times it occurs in each.
+	postings map[string]map[int]int
+}
+
+// NewKeywordIndex returns an empty keyword index.
+func NewKeywordIndex() *KeywordIndex {
+	return &KeywordIndex{postings: map[string]map[int]int{}}
+}
+
+// Len returns the number of entries of the index.
+func (k *KeywordIndex) Len() int {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return len(k.ids)
+}
+
+// Add indexes the text under id.
+func (k *KeywordIndex) Add(id, text string, metadata map[string]string) {
+	terms := keywordTerms(text)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	entry := len(k.ids)
+	k.ids = append(k.ids, id)
+	k.metadata = append(k.metadata, metadata)
+	k.lengths = append(k.lengths, len(terms))
+	k.totalLength += len(terms)
+	for _, term := range terms {
+		if k.postings[term] == nil {
+			k.postings[term] = map[int]int{}
+		}
+		k.postings[term][entry]++
+	}
+}
+
+// Search returns the topK entries with the highest BM25 score for query that
+// match all the filters, the best first. Entries sharing no term with the
+// query are not returned.
+func (k *KeywordIndex) Search(query string, topK int, filters ...MetadataFilter) []SearchResult {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if len(k.ids) == 0 {
+		return nil
+	}
+
+	averageLength := float64(k.totalLength) / float64(len(k.ids))
+	scores := map[int]float64{}
+	for _, term := range keywordTerms(query) {
+		postings := k.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		n := float64(len(postings))
+		idf := math.Log(1 + (float64(len(k.ids))-n+0.5)/(n+0.5))
+		for entry, frequency := range postings {
+			tf := float64(frequency)
+			norm := bm25K1 * (1 - bm25B + bm25B*float64(k.lengths[entry])/averageLength)
+			scores[entry] += idf * tf * (bm25K1 + 1) / (tf + norm)
+		}
+	}
+
+	results := make([]SearchResult, 0, len(scores))
+	for entry, score := range scores {
+		if matchesAll(k.metadata[entry], filters) {
+			results = append(results, SearchResult{Id: k.ids[entry], Score: score, Metadata: k.metadata[entry]})
+		}
+	}
+	slices.SortFunc(results, func(a, b SearchResult) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+	return results[:min(topK, len(results))]
+}
+
+// HybridSearch combines the results of an embedding search and a keyword
+// search with reciprocal rank fusion: every result scores 1/(60+rank) in each
+// list it appears in, so entries ranked well by both come first. Results are
+// matched by id, and their score is the fused one. A negative topK is an
+// error.
+func HybridSearch(topK int, resultLists ...[]SearchResult) ([]SearchResult, error) {
+	if topK < 0 {
+		return nil, NewInvalidRequestError(fmt.Sprintf("topK must not be negative, got %d", topK))
+	}
+	fused := map[string]*SearchResult{}
+	var order []string
+	for _, results := range resultLists {
+		for rank, result := range results {
+			entry, ok := fused[result.Id]
+			if !ok {
+				entry = &SearchResult{Id: result.Id, Metadata: result.Metadata}
+				fused[result.Id] = entry
+				order = append(order, result.Id)
+			}
+			entry.Score += 1 / float64(reciprocalRankK+rank+1)
+		}
+	}
+
+	results := make([]SearchResult, 0, len(order))
+	for _, id := range order {
+		results = append(results, *fused[id])
+	}
+	slices.SortStableFunc(results, func(a, b SearchResult) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	return results[:min(topK, len(results))], nil
+}
+
+// keywordTerms splits text into lowercase terms of letters and digits. Terms
+// such as identifiers are kept whole.
+func keywordTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '_' && r != '-'
+	})
+}
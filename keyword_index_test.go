@@ -0,0 +1,47 @@
+package openaiclient
+
+import (
+	"math"
+	"testing"
+)
+
+func TestKeywordIndex_Search(t *testing.T) {
+	index := NewKeywordIndex()
+	index.Add("a", "Error ERR_QUOTA_42 is returned when the quota is exceeded", map[string]string{"kind": "error"})
+	index.Add("b", "The quota resets every month", nil)
+	index.Add("c", "Billing happens at the end of the month", nil)
+
+	results := index.Search("what does err_quota_42 mean", 5)
+	if len(results) != 1 || results[0].Id != "a" {
+		t.Fatalf("expected the exact identifier match, got %+v", results)
+	}
+
+	results = index.Search("quota month", 5)
+	if len(results) != 3 || results[0].Id != "b" {
+		t.Errorf("expected the entry with both terms first, got %+v", results)
+	}
+
+	results = index.Search("quota", 5, MetadataEquals("kind", "error"))
+	if len(results) != 1 || results[0].Id != "a" {
+		t.Errorf("expected the filtered entry only, got %+v", results)
+	}
+}
+
+func TestHybridSearch(t *testing.T) {
+	semantic := []SearchResult{{Id: "a"}, {Id: "b"}, {Id: "c"}}
+	keyword := []SearchResult{{Id: "b"}, {Id: "c"}}
+
+	results, err := HybridSearch(2, semantic, keyword)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(results) != 2 || results[0].Id != "b" || results[1].Id != "c" {
+		t.Errorf("expected the entries found by both searches first, got %+v", results)
+	}
+	if want := 1.0/62 + 1.0/61; math.Abs(results[0].Score-want) > 1e-12 {
+		t.Errorf("expected a fused score of %v, got %v", want, results[0].Score)
+	}
+	if _, err := HybridSearch(-1, semantic); GetOpenAIErrorType(err) != ErrTypeInvalidRequest {
+		t.Errorf("expected an invalid request error for a negative topK, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package openaiclient
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+const (
+	kmeansMaxIterations = 100
+
+	clusterLabelInstructions = "The texts below belong to the same cluster of a larger corpus. " +
+		"Reply with a short label of two to five words naming the topic they share, and nothing else."
+)
+
+// Clustering is the result of KMeans. Assignments holds the cluster of every
+// vector, by index in the input.
+type Clustering struct {
+	Assignments []int
+	Centroids   [][]float64
+	Iterations  int
+}
+
+// KMeans partitions the vectors into k clusters with Lloyd's algorithm,
+// seeded with k-means++. Vectors are compared by squared euclidean distance;
+// for normalized embeddings this ranks them as cosine similarity does. The
+// seeding is deterministic, so the same input gives the same clusters.
+func KMeans(vectors [][]float64, k int) (*Clustering, error) {
+	if k <= 0 || k > len(vectors) {
+		return nil, NewInvalidRequestError(fmt.Sprintf("cannot make %d clusters of %d vectors", k, len(vectors)))
+	}
+	for _, vector := range vectors {
+		if len(vector) != len(vectors[0]) {
+			return nil, NewInvalidRequestError("vectors have different dimensions")
+		}
+	}
+
+	clustering := &Clustering{
+		Assignments: make([]int, len(vectors)),
+		Centroids:   seedCentroids(vectors, k, rand.New(rand.NewPCG(uint64(len(vectors)), uint64(k)))),
+	}
+	for clustering.Iterations < kmeansMaxIterations {
+		clustering.Iterations++
+		changed := false
+		for i, vector := range vectors {
+			if nearest := nearestCentroid(vector, clustering.Centroids); nearest != clustering.Assignments[i] {
+				clustering.Assignments[i] = nearest
+				changed = true
+			}
+		}
+		// Every assignment starts at 0, so the first iteration may not change
+		// any yet still needs the centroids updated.
+		if !changed && clustering.Iterations > 1 {
+			break
+		}
+		clustering.updateCentroids(vectors)
+	}
+	return clustering, nil
+}
+
+// Members returns the indexes of the vectors assigned to the cluster.
+func (c *Clustering) Members(cluster int) []int {
+	var members []int
+	for i, assignment := range c.Assignments {
+		if assignment == cluster {
+			members = append(members, i)
+		}
+	}
+	return members
+}
+
+func (c *Clustering) updateCentroids(vectors [][]float64) {
+	counts := make([]int, len(c.Centroids))
+	sums := make([][]float64, len(c.Centroids))
+	for i := range sums {
+		sums[i] = make([]float64, len(vectors[0]))
+	}
+	for i, vector := range vectors {
+		cluster := c.Assignments[i]
+		counts[cluster]++
+		for j, value := range vector {
+			sums[cluster][j] += value
+		}
+	}
+
+	for cluster, sum := range sums {
+		// An emptied cluster keeps its centroid rather than collapsing.
+		if counts[cluster] == 0 {
+			continue
+		}
+		for j := range sum {
+			sum[j] /= float64(counts[cluster])
+		}
+		c.Centroids[cluster] = sum
+	}
+}
+
+// seedCentroids picks k initial centroids among the vectors, each next one
+// with a probability proportional to its squared distance to the closest
+// centroid picked so far.
+func seedCentroids(vectors [][]float64, k int, random *rand.Rand) [][]float64 {
+	centroids := [][]float64{slices.Clone(vectors[random.IntN(len(vectors))])}
+	distances := make([]float64, len(vectors))
+	for len(centroids) < k {
+		var total float64
+		for i, vector := range vectors {
+			distances[i] = squaredDistance(vector, centroids[nearestCentroid(vector, centroids)])
+			total += distances[i]
+		}
+
+		next := len(centroids) % len(vectors)
+		if total > 0 {
+			target := random.Float64() * total
+			for i, distance := range distances {
+				if target -= distance; target <= 0 && distance > 0 {
+					next = i
+					break
+				}
+			}
+		}
+		centroids = append(centroids, slices.Clone(vectors[next]))
+	}
+	return centroids
+}
+
+func nearestCentroid(vector []float64, centroids [][]float64) int {
+	nearest, best := 0, math.Inf(1)
+	for i, centroid := range centroids {
+		if distance := squaredDistance(vector, centroid); distance < best {
+			nearest, best = i, distance
+		}
+	}
+	return nearest
+}
+
+func squaredDistance(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return sum
+}
+
+// LabelClusters asks the model to name every cluster of the texts, from the
+// samples members closest to its centroid. vectors are the embeddings the
+// clustering was computed from, by index in texts. Empty clusters get an
+// empty label.
+func (o *OpenAI) LabelClusters(ctx context.Context, model string, texts []string, vectors [][]float64, clustering *Clustering, samples int) ([]string, error) {
+	labels := make([]string, len(clustering.Centroids))
+	for cluster, centroid := range clustering.Centroids {
+		members := clustering.Members(cluster)
+		if len(members) == 0 {
+			continue
+		}
+		slices.SortFunc(members, func(a, b int) int {
+			return cmp.Compare(squaredDistance(vectors[a], centroid), squaredDistance(vectors[b], centroid))
+		})
+
+		var sample strings.Builder
+		for _, member := range members[:min(max(samples, 1), len(members))] {
+			fmt.Fprintf(&sample, "- %s\n", texts[member])
+		}
+		message, err := o.GetCompletionContext(ctx, &CompletionRequestPayload{
+			Model: model,
+			Messages: []Message{
+				{Role: MessageRoleSystem, Content: clusterLabelInstructions},
+				{Role: MessageRoleUser, Content: sample.String()},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error labeling cluster %d: %w", cluster, err)
+		}
+		labels[cluster] = strings.Trim(strings.TrimSpace(message.Content), `"'.`)
+	}
+	return labels, nil
+}
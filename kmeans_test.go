@@ -0,0 +1,93 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestKMeans(t *testing.T) {
+	vectors := [][]float64{
+		{1, 0}, {0.9, 0.1}, {0.95, 0.05},
+		{0, 1}, {0.1, 0.9},
+		{-1, -1}, {-0.9, -1.1},
+	}
+	clustering, err := KMeans(vectors, 3)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	groups := [][]int{{0, 1, 2}, {3, 4}, {5, 6}}
+	seen := map[int]bool{}
+	for _, group := range groups {
+		cluster := clustering.Assignments[group[0]]
+		for _, member := range group[1:] {
+			if clustering.Assignments[member] != cluster {
+				t.Errorf("expected vectors %v in the same cluster, got %v", group, clustering.Assignments)
+			}
+		}
+		if seen[cluster] {
+			t.Errorf("expected separate clusters, got %v", clustering.Assignments)
+		}
+		seen[cluster] = true
+	}
+	if centroid := clustering.Centroids[clustering.Assignments[5]]; centroid[0] != -0.95 || centroid[1] != -1.05 {
+		t.Errorf("expected the mean of the cluster as centroid, got %v", centroid)
+	}
+
+	again, _ := KMeans(vectors, 3)
+	for i := range vectors {
+		if again.Assignments[i] != clustering.Assignments[i] {
+			t.Fatalf("expected deterministic clusters, got %v and %v", clustering.Assignments, again.Assignments)
+		}
+	}
+}
+
+func TestKMeans_InvalidK(t *testing.T) {
+	if _, err := KMeans([][]float64{{1}, {2}}, 3); err == nil {
+		t.Error("expected an error for more clusters than vectors")
+	}
+	if _, err := KMeans([][]float64{{1}, {2, 3}}, 1); err == nil {
+		t.Error("expected an error for vectors of different dimensions")
+	}
+}
+
+func TestLabelClusters(t *testing.T) {
+	texts := []string{"refund please", "money back", "app crashes", "an outlier"}
+	vectors := [][]float64{{1, 0}, {0.9, 0.1}, {0, 1}, {0.5, 0.5}}
+	clustering := &Clustering{
+		Assignments: []int{0, 0, 1, 0},
+		Centroids:   [][]float64{{0.95, 0.05}, {0, 1}, {-1, -1}},
+	}
+
+	var prompts []string
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			var payload CompletionRequestPayload
+			body, _ := io.ReadAll(req.Body)
+			json.Unmarshal(body, &payload)
+			prompts = append(prompts, payload.Messages[1].Content)
+			label := `"Refunds."`
+			if strings.Contains(payload.Messages[1].Content, "crashes") {
+				label = "Crashes"
+			}
+			return fakeResponse(http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":`+strconv.Quote(label)+`}}]}`), nil
+		},
+	}
+
+	labels, err := client.LabelClusters(context.Background(), "test-model", texts, vectors, clustering, 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(labels) != 3 || labels[0] != "Refunds" || labels[1] != "Crashes" || labels[2] != "" {
+		t.Errorf("expected a label per cluster, got %q", labels)
+	}
+	if len(prompts) != 2 || strings.Contains(prompts[0], "outlier") {
+		t.Errorf("expected the members closest to the centroid as samples, got %q", prompts)
+	}
+}
@@ -0,0 +1,109 @@
+package openaiclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClientClosed is returned for requests made after Close was called.
+var ErrClientClosed = errors.New("client is closed")
+
+// lifecycle tracks the in-flight calls of a client, and of the copies made
+// from it, so that Close can wait for them.
+type lifecycle struct {
+	mu       sync.Mutex
+	closed   bool
+	inFlight int
+	drained  chan struct{}
+}
+
+// inFlightKey marks the contexts of the calls of a lifecycle. It is keyed by
+// the lifecycle, so a call of one client does not count as a call of
+// another, e.g. of a shadow or hedge client.
+type inFlightKey struct {
+	lifecycle *lifecycle
+}
+
+// begin registers a call, unless ctx already belongs to one of l: the
+// requests and tool executions of a completion that started before Close are
+// allowed to finish. It returns the context to use for the call and the
+// function to call when it ends.
+func (l *lifecycle) begin(ctx context.Context) (context.Context, func(), error) {
+	if l == nil || ctx.Value(inFlightKey{l}) != nil {
+		return ctx, func() {}, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil, nil, ErrClientClosed
+	}
+	l.inFlight++
+	return context.WithValue(ctx, inFlightKey{l}, struct{}{}), l.end, nil
+}
+
+// beginBackground registers work that outlives the call starting it, such as
+// a shadow request. It returns false when the client is closed.
+func (l *lifecycle) beginBackground() (func(), bool) {
+	if l == nil {
+		return func() {}, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil, false
+	}
+	l.inFlight++
+	return l.end, true
+}
+
+func (l *lifecycle) end() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	if l.inFlight == 0 && l.drained != nil {
+		close(l.drained)
+		l.drained = nil
+	}
+}
+
+func (l *lifecycle) close(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	l.closed = true
+	if l.inFlight == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	if l.drained == nil {
+		l.drained = make(chan struct{})
+	}
+	drained := l.drained
+	l.mu.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops the client, and the copies made from it, from accepting new
+// requests, waits for the in-flight ones to finish up to the deadline of
+// ctx, and closes the idle connections of the HTTP client. There is nothing
+// to flush: usage and traffic are tracked in memory, and RunLog and Audit
+// write every record before its request returns. Closing their writers is
+// left to the caller.
+func (o *OpenAI) Close(ctx context.Context) error {
+	err := o.lifecycle.close(ctx)
+	if client, ok := o.client.(interface{ CloseIdleConnections() }); ok {
+		client.CloseIdleConnections()
+	}
+	return err
+}
@@ -0,0 +1,153 @@
+package openaiclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClose_DrainsInFlightCompletions(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	calls := 0
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				close(started)
+				<-release
+				return fakeResponse(http.StatusOK, `{"choices":[{"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"echo","arguments":"{}"}}]}}]}`), nil
+			}
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := client.GetCompletion(&CompletionRequestPayload{
+			Model:    "test-model",
+			Messages: []Message{{Role: "user", Content: "Hi"}},
+			Tools: []ToolDefinition{NewToolDefinition(&FunctionDefinition{
+				Name: "echo",
+				Fn:   func(args string) string { return args },
+			})},
+		})
+		result <- err
+	}()
+	<-started
+
+	closed := make(chan error, 1)
+	go func() { closed <- client.Close(context.Background()) }()
+
+	for !isClosed(client) {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := client.ListModels(context.Background()); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected new requests to be rejected, got %v", err)
+	}
+	select {
+	case err := <-closed:
+		t.Fatalf("expected Close to wait for the in-flight completion, returned %v", err)
+	default:
+	}
+
+	close(release)
+	if err := <-result; err != nil {
+		t.Fatalf("expected the in-flight completion to finish, got %v", err)
+	}
+	if err := <-closed; err != nil {
+		t.Errorf("expected Close to succeed, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the run to make both its requests, got %d", calls)
+	}
+}
+
+func isClosed(client *OpenAI) bool {
+	client.lifecycle.mu.Lock()
+	defer client.lifecycle.mu.Unlock()
+	return client.lifecycle.closed
+}
+
+func TestClose_Deadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			close(started)
+			<-release
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	go client.GetCompletion(&CompletionRequestPayload{Model: "test-model", Messages: []Message{{Role: "user", Content: "Hi"}}})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := client.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestClose_Idle(t *testing.T) {
+	client := createClient(t)
+
+	if err := client.Close(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	_, err := client.GetCompletion(&CompletionRequestPayload{Model: "test-model", Messages: []Message{{Role: "user", Content: "Hi"}}})
+	if !errors.Is(err, ErrClientClosed) {
+		t.Errorf("expected ErrClientClosed, got %v", err)
+	}
+}
+
+func TestClose_TracksCallsOfOtherClients(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	inner := createClient(t)
+	inner.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			close(started)
+			<-release
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+	outer := createClient(t)
+	outer.client = &SequentialFakeClient{
+		Responses: []*http.Response{
+			fakeResponse(http.StatusOK, `{"choices":[{"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"ask","arguments":"{}"}}]}}]}`),
+			fakeResponse(http.StatusOK, completionBody),
+		},
+	}
+
+	// The tool calls inner with a context already marked by outer.
+	go outer.GetCompletion(&CompletionRequestPayload{
+		Model:    "test-model",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+		Tools: []ToolDefinition{NewToolDefinition(&FunctionDefinition{
+			Name: "ask",
+			FnContext: func(ctx context.Context, arguments string) (string, error) {
+				_, err := inner.GetCompletionContext(ctx, &CompletionRequestPayload{Model: "test-model", Messages: []Message{{Role: "user", Content: "Hi"}}})
+				return "", err
+			},
+		})},
+	})
+	<-started
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := inner.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected Close to wait for the call made from another client, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package openaiclient
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Voice is a voice of the text-to-speech models.
+type Voice string
+
+const (
+	VoiceAlloy   Voice = "alloy"
+	VoiceAsh     Voice = "ash"
+	VoiceBallad  Voice = "ballad"
+	VoiceCoral   Voice = "coral"
+	VoiceEcho    Voice = "echo"
+	VoiceFable   Voice = "fable"
+	VoiceNova    Voice = "nova"
+	VoiceOnyx    Voice = "onyx"
+	VoiceSage    Voice = "sage"
+	VoiceShimmer Voice = "shimmer"
+	VoiceVerse   Voice = "verse"
+)
+
+// AudioFormat is an audio encoding of the speech endpoint's responses.
+type AudioFormat string
+
+const (
+	AudioFormatMP3  AudioFormat = "mp3"
+	AudioFormatOpus AudioFormat = "opus"
+	AudioFormatAAC  AudioFormat = "aac"
+	AudioFormatFLAC AudioFormat = "flac"
+	AudioFormatWAV  AudioFormat = "wav"
+	// AudioFormatPCM is raw 24kHz 16-bit signed little-endian samples.
+	AudioFormatPCM AudioFormat = "pcm"
+)
+
+// ImageSize is the size of a generated image.
+type ImageSize string
+
+const (
+	ImageSize256x256   ImageSize = "256x256"
+	ImageSize512x512   ImageSize = "512x512"
+	ImageSize1024x1024 ImageSize = "1024x1024"
+	ImageSize1792x1024 ImageSize = "1792x1024"
+	ImageSize1024x1792 ImageSize = "1024x1792"
+	ImageSize1536x1024 ImageSize = "1536x1024"
+	ImageSize1024x1536 ImageSize = "1024x1536"
+	ImageSizeAuto      ImageSize = "auto"
+)
+
+var (
+	allVoices = []Voice{
+		VoiceAlloy, VoiceAsh, VoiceBallad, VoiceCoral, VoiceEcho, VoiceFable,
+		VoiceNova, VoiceOnyx, VoiceSage, VoiceShimmer, VoiceVerse,
+	}
+	ttsVoices = []Voice{
+		VoiceAlloy, VoiceAsh, VoiceCoral, VoiceEcho, VoiceFable,
+		VoiceNova, VoiceOnyx, VoiceSage, VoiceShimmer,
+	}
+	// modelVoices lists the voices of the speech models that do not support
+	// them all.
+	modelVoices = map[string][]Voice{
+		"tts-1":    ttsVoices,
+		"tts-1-hd": ttsVoices,
+	}
+
+	allAudioFormats = []AudioFormat{
+		AudioFormatMP3, AudioFormatOpus, AudioFormatAAC, AudioFormatFLAC, AudioFormatWAV, AudioFormatPCM,
+	}
+
+	allImageSizes = []ImageSize{
+		ImageSize256x256, ImageSize512x512, ImageSize1024x1024, ImageSize1792x1024,
+		ImageSize1024x1792, ImageSize1536x1024, ImageSize1024x1536, ImageSizeAuto,
+	}
+	modelImageSizes = map[string][]ImageSize{
+		"dall-e-2":    {ImageSize256x256, ImageSize512x512, ImageSize1024x1024},
+		"dall-e-3":    {ImageSize1024x1024, ImageSize1792x1024, ImageSize1024x1792},
+		"gpt-image-1": {ImageSize1024x1024, ImageSize1536x1024, ImageSize1024x1536, ImageSizeAuto},
+	}
+)
+
+// ValidateFor checks that the voice exists and that model supports it.
+// Models this library does not know of are assumed to support every voice.
+func (v Voice) ValidateFor(model string) error {
+	return validateOption("voice", v, model, allVoices, modelVoices[model])
+}
+
+// Validate checks that the format is one the speech endpoint can return.
+func (f AudioFormat) Validate() error {
+	return validateOption("audio format", f, "", allAudioFormats, nil)
+}
+
+// ValidateFor checks that the size exists and that model can generate it.
+// Models this library does not know of are assumed to support every size.
+func (s ImageSize) ValidateFor(model string) error {
+	return validateOption("image size", s, model, allImageSizes, modelImageSizes[model])
+}
+
+// validateOption returns an error naming the accepted values when value is
+// not among known, or not among supported when the model restricts them.
+func validateOption[T ~string](kind string, value T, model string, known, supported []T) error {
+	if !slices.Contains(known, value) {
+		return NewInvalidRequestError(fmt.Sprintf("unknown %s %q, expected one of %s", kind, value, joinOptions(known)))
+	}
+	if supported != nil && !slices.Contains(supported, value) {
+		return NewInvalidRequestError(fmt.Sprintf("%s %q is not supported by %s, expected one of %s", kind, value, model, joinOptions(supported)))
+	}
+	return nil
+}
+
+func joinOptions[T ~string](options []T) string {
+	names := make([]string, len(options))
+	for i, option := range options {
+		names[i] = string(option)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,56 @@
+package openaiclient
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVoice_ValidateFor(t *testing.T) {
+	if err := VoiceBallad.ValidateFor("gpt-4o-mini-tts"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := VoiceAlloy.ValidateFor("tts-1"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	err := VoiceBallad.ValidateFor("tts-1")
+	if err == nil || !strings.Contains(err.Error(), `voice "ballad" is not supported by tts-1`) {
+		t.Errorf("expected an unsupported voice error, got %v", err)
+	}
+	if GetOpenAIErrorType(err) != ErrTypeInvalidRequest {
+		t.Errorf("expected an invalid request error, got %v", err)
+	}
+	if err := Voice("robot").ValidateFor("tts-1"); err == nil || !strings.Contains(err.Error(), "unknown voice") {
+		t.Errorf("expected an unknown voice error, got %v", err)
+	}
+}
+
+func TestAudioFormat_Validate(t *testing.T) {
+	if err := AudioFormatOpus.Validate(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := AudioFormat("ogg").Validate(); err == nil || !strings.Contains(err.Error(), "mp3, opus") {
+		t.Errorf("expected the accepted formats listed, got %v", err)
+	}
+}
+
+func TestImageSize_ValidateFor(t *testing.T) {
+	tests := []struct {
+		model string
+		size  ImageSize
+		valid bool
+	}{
+		{"dall-e-2", ImageSize512x512, true},
+		{"dall-e-2", ImageSize1792x1024, false},
+		{"dall-e-3", ImageSize1792x1024, true},
+		{"dall-e-3", ImageSizeAuto, false},
+		{"gpt-image-1", ImageSize1536x1024, true},
+		{"custom-model", ImageSize256x256, true},
+		{"custom-model", "100x100", false},
+	}
+	for _, test := range tests {
+		if err := test.size.ValidateFor(test.model); (err == nil) != test.valid {
+			t.Errorf("%s with %s: expected valid %v, got %v", test.model, test.size, test.valid, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package openaiclient
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MetadataFilter selects the entries of an Index a search may return by
+// their metadata.
+type MetadataFilter func(metadata map[string]string) bool
+
+// MetadataEquals matches entries whose key is set to value.
+func MetadataEquals(key, value string) MetadataFilter {
+	return func(metadata map[string]string) bool {
+		actual, ok := metadata[key]
+		return ok && actual == value
+	}
+}
+
+// MetadataIn matches entries whose key is set to one of values.
+func MetadataIn(key string, values ...string) MetadataFilter {
+	return func(metadata map[string]string) bool {
+		actual, ok := metadata[key]
+		return ok && slices.Contains(values, actual)
+	}
+}
+
+// MetadataRange matches entries whose key holds a number between low and
+// high, inclusive. Entries where it is missing or not a number never match.
+func MetadataRange(key string, low, high float64) MetadataFilter {
+	return func(metadata map[string]string) bool {
+		value, err := strconv.ParseFloat(metadata[key], 64)
+		return err == nil && value >= low && value <= high
+	}
+}
+
+// MetadataHasTag matches entries whose key holds a comma-separated list of
+// tags that includes tag.
+func MetadataHasTag(key, tag string) MetadataFilter {
+	return func(metadata map[string]string) bool {
+		for candidate := range strings.SplitSeq(metadata[key], ",") {
+			if strings.TrimSpace(candidate) == tag {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AnyMetadata matches entries that match at least one of filters.
+func AnyMetadata(filters ...MetadataFilter) MetadataFilter {
+	return func(metadata map[string]string) bool {
+		for _, filter := range filters {
+			if filter(metadata) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// matchesAll reports whether the metadata matches every filter.
+func matchesAll(metadata map[string]string, filters []MetadataFilter) bool {
+	for _, filter := range filters {
+		if !filter(metadata) {
+			return false
+		}
+	}
+	return true
+}
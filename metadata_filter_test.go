@@ -0,0 +1,59 @@
+package openaiclient
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestMetadataFilters(t *testing.T) {
+	metadata := map[string]string{"tenant": "acme", "year": "2024", "tags": "go, rag"}
+	cases := []struct {
+		name   string
+		filter MetadataFilter
+		want   bool
+	}{
+		{"equals", MetadataEquals("tenant", "acme"), true},
+		{"equals other", MetadataEquals("tenant", "globex"), false},
+		{"in", MetadataIn("tenant", "globex", "acme"), true},
+		{"range", MetadataRange("year", 2020, 2024), true},
+		{"out of range", MetadataRange("year", 2025, 2030), false},
+		{"range on missing key", MetadataRange("month", 0, 12), false},
+		{"tag", MetadataHasTag("tags", "rag"), true},
+		{"missing tag", MetadataHasTag("tags", "python"), false},
+		{"any", AnyMetadata(MetadataEquals("tenant", "globex"), MetadataHasTag("tags", "go")), true},
+	}
+	for _, c := range cases {
+		if got := c.filter(metadata); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.name, c.want, got)
+		}
+	}
+}
+
+func TestIndex_SearchWithFilters(t *testing.T) {
+	random := rand.New(rand.NewPCG(3, 3))
+	exact, approximate := NewIndex(), NewIndex()
+	approximate.EnableHNSW(HNSWConfig{})
+	for i := range 500 {
+		vector := randomVector(random, 16)
+		metadata := map[string]string{"tenant": []string{"acme", "globex"}[i%2]}
+		exact.Add(fmt.Sprint(i), vector, metadata)
+		approximate.Add(fmt.Sprint(i), vector, metadata)
+	}
+
+	query := randomVector(random, 16)
+	for name, index := range map[string]*Index{"exact": exact, "hnsw": approximate} {
+		results, err := index.Search(query, 5, MetadataEquals("tenant", "acme"))
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+		if len(results) != 5 {
+			t.Fatalf("%s: expected 5 results, got %d", name, len(results))
+		}
+		for _, result := range results {
+			if result.Metadata["tenant"] != "acme" {
+				t.Errorf("%s: expected only acme entries, got %+v", name, result)
+			}
+		}
+	}
+}
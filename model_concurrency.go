@@ -0,0 +1,60 @@
+package openaiclient
+
+import (
+	"context"
+	"sync"
+)
+
+// AnyModel is the key of WithModelConcurrency's limit for the models it does
+// not list.
+const AnyModel = "*"
+
+// modelSlots bounds the requests in flight for each model.
+type modelSlots struct {
+	limits map[string]int
+
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// WithModelConcurrency returns a copy of the client that has at most
+// limits[model] requests in flight for each model, e.g. for local GPU
+// backends that fall over beyond a few concurrent requests. The limit under
+// AnyModel applies to the models not listed; models without a limit are not
+// bounded. Excess requests wait for a slot, or until their context is done.
+// The copy shares the underlying HTTP client and rate limit pacing.
+func (o *OpenAI) WithModelConcurrency(limits map[string]int) *OpenAI {
+	client := *o
+	client.modelSlots = &modelSlots{limits: limits, slots: map[string]chan struct{}{}}
+	return &client
+}
+
+// acquire waits for a slot of the model and returns the function releasing
+// it.
+func (s *modelSlots) acquire(ctx context.Context, model string) (func(), error) {
+	if s == nil {
+		return func() {}, nil
+	}
+	limit, ok := s.limits[model]
+	if !ok {
+		limit = s.limits[AnyModel]
+	}
+	if limit <= 0 {
+		return func() {}, nil
+	}
+
+	s.mu.Lock()
+	slots, ok := s.slots[model]
+	if !ok {
+		slots = make(chan struct{}, limit)
+		s.slots[model] = slots
+	}
+	s.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,89 @@
+package openaiclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithModelConcurrency(t *testing.T) {
+	var inFlight, maxInFlight, otherRequests atomic.Int32
+	base := createClient(t)
+	base.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if requestModel(req.Context()) != "local-model" {
+				otherRequests.Add(1)
+				return fakeResponse(http.StatusOK, completionBody), nil
+			}
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				highest := maxInFlight.Load()
+				if current <= highest || maxInFlight.CompareAndSwap(highest, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+	client := base.WithModelConcurrency(map[string]int{"local-model": 2})
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			model := "local-model"
+			if i%4 == 0 {
+				model = "remote-model"
+			}
+			payload := &CompletionRequestPayload{Model: model, Messages: []Message{{Role: MessageRoleUser, Content: "Hi"}}}
+			if _, err := client.GetCompletionContext(context.Background(), payload); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight.Load() != 2 {
+		t.Errorf("expected at most 2 requests in flight, got %d", maxInFlight.Load())
+	}
+	if otherRequests.Load() != 2 {
+		t.Errorf("expected the other model's requests to go through, got %d", otherRequests.Load())
+	}
+}
+
+func TestWithModelConcurrency_ContextCanceled(t *testing.T) {
+	release := make(chan struct{})
+	base := createClient(t)
+	base.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			<-release
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+	client := base.WithModelConcurrency(map[string]int{AnyModel: 1})
+	payload := func() *CompletionRequestPayload {
+		return &CompletionRequestPayload{Model: "any-model", Messages: []Message{{Role: MessageRoleUser, Content: "Hi"}}}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		client.GetCompletionContext(context.Background(), payload())
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetCompletionContext(ctx, payload()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the queued request to give up with its context, got %v", err)
+	}
+	close(release)
+	<-done
+}
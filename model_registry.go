@@ -0,0 +1,112 @@
+package openaiclient
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ModelInfo describes what a model accepts. A zero ContextWindow or
+// MaxOutputTokens means the limit is unknown.
+type ModelInfo struct {
+	ContextWindow       int  `json:"context_window"`
+	MaxOutputTokens     int  `json:"max_output_tokens"`
+	SupportsTools       bool `json:"supports_tools"`
+	SupportsVision      bool `json:"supports_vision"`
+	SupportsTemperature bool `json:"supports_temperature"`
+}
+
+type ModelRegistry struct {
+	mu     sync.RWMutex
+	models map[string]ModelInfo
+}
+
+var (
+	chatModel      = ModelInfo{SupportsTools: true, SupportsVision: true, SupportsTemperature: true}
+	reasoningModel = ModelInfo{ContextWindow: 200000, MaxOutputTokens: 100000, SupportsTools: true, SupportsVision: true}
+)
+
+// DefaultModelRegistry holds built-in data for common OpenAI models. Register
+// overrides or additional models on it, or give a client its own registry.
+var DefaultModelRegistry = NewModelRegistry(map[string]ModelInfo{
+	"gpt-4o":        chatModel.withLimits(128000, 16384),
+	"gpt-4o-mini":   chatModel.withLimits(128000, 16384),
+	"gpt-4.1":       chatModel.withLimits(1047576, 32768),
+	"gpt-4.1-mini":  chatModel.withLimits(1047576, 32768),
+	"gpt-4.1-nano":  chatModel.withLimits(1047576, 32768),
+	"gpt-4-turbo":   chatModel.withLimits(128000, 4096),
+	"gpt-4":         {ContextWindow: 8192, MaxOutputTokens: 8192, SupportsTools: true, SupportsTemperature: true},
+	"gpt-3.5-turbo": {ContextWindow: 16385, MaxOutputTokens: 4096, SupportsTools: true, SupportsTemperature: true},
+	"o1":            reasoningModel,
+	"o1-mini":       {ContextWindow: 128000, MaxOutputTokens: 65536},
+	"o3":            reasoningModel,
+	"o3-mini":       {ContextWindow: 200000, MaxOutputTokens: 100000, SupportsTools: true},
+	"o4-mini":       reasoningModel,
+})
+
+func NewModelRegistry(models map[string]ModelInfo) *ModelRegistry {
+	registry := &ModelRegistry{models: make(map[string]ModelInfo, len(models))}
+	for name, info := range models {
+		registry.models[name] = info
+	}
+	return registry
+}
+
+func (r *ModelRegistry) Register(model string, info ModelInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.models[model] = info
+}
+
+// Lookup returns the capabilities of the model. Dated snapshots such as
+// "gpt-4o-2024-08-06" resolve to the longest registered base name.
+func (r *ModelRegistry) Lookup(model string) (ModelInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if info, ok := r.models[model]; ok {
+		return info, true
+	}
+
+	best := ""
+	for name := range r.models {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return ModelInfo{}, false
+	}
+	return r.models[best], true
+}
+
+// validate rejects payloads the model is known not to support. Unknown models
+// are always accepted.
+func (r *ModelRegistry) validate(payload *CompletionRequestPayload) error {
+	if r == nil {
+		return nil
+	}
+	info, ok := r.Lookup(payload.Model)
+	if !ok {
+		return nil
+	}
+
+	if len(payload.Tools) > 0 && !info.SupportsTools {
+		return NewInvalidRequestError(fmt.Sprintf("model %s does not support tools", payload.Model))
+	}
+	if (payload.Temperature != nil || payload.TopP != nil) && !info.SupportsTemperature {
+		return NewInvalidRequestError(fmt.Sprintf("model %s does not support temperature or top_p", payload.Model))
+	}
+	for _, maxTokens := range []*int{payload.MaxTokens, payload.MaxCompletionTokens} {
+		if maxTokens != nil && info.MaxOutputTokens > 0 && *maxTokens > info.MaxOutputTokens {
+			return NewInvalidRequestError(fmt.Sprintf("model %s outputs at most %d tokens", payload.Model, info.MaxOutputTokens))
+		}
+	}
+	return nil
+}
+
+func (m ModelInfo) withLimits(contextWindow, maxOutputTokens int) ModelInfo {
+	m.ContextWindow = contextWindow
+	m.MaxOutputTokens = maxOutputTokens
+	return m
+}
@@ -0,0 +1,83 @@
+package openaiclient
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestModelRegistry_Lookup(t *testing.T) {
+	registry := NewModelRegistry(map[string]ModelInfo{
+		"gpt-4o":      {ContextWindow: 128000},
+		"gpt-4o-mini": {ContextWindow: 64000},
+	})
+
+	tests := []struct {
+		model      string
+		wantOk     bool
+		wantWindow int
+	}{
+		{model: "gpt-4o", wantOk: true, wantWindow: 128000},
+		{model: "gpt-4o-2024-08-06", wantOk: true, wantWindow: 128000},
+		{model: "gpt-4o-mini-2024-07-18", wantOk: true, wantWindow: 64000},
+		{model: "gpt-4oo", wantOk: false},
+		{model: "llama3", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			info, ok := registry.Lookup(tt.model)
+			if ok != tt.wantOk || info.ContextWindow != tt.wantWindow {
+				t.Errorf("got (%d, %v), want (%d, %v)", info.ContextWindow, ok, tt.wantWindow, tt.wantOk)
+			}
+		})
+	}
+
+	registry.Register("llama3", ModelInfo{ContextWindow: 8192})
+	if info, ok := registry.Lookup("llama3"); !ok || info.ContextWindow != 8192 {
+		t.Errorf("expected registered override, got (%+v, %v)", info, ok)
+	}
+}
+
+func TestGetCompletion_RejectsUnsupportedTools(t *testing.T) {
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			t.Fatal("expected request not to be sent")
+			return nil, nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+	client.ModelRegistry = NewModelRegistry(map[string]ModelInfo{"no-tools": {}})
+
+	payload := &CompletionRequestPayload{
+		Model:    "no-tools",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+		Tools:    []ToolDefinition{NewToolDefinition(&FunctionDefinition{Name: "echo"})},
+	}
+
+	_, err := client.GetCompletion(payload)
+	if GetOpenAIErrorType(err) != ErrTypeInvalidRequest {
+		t.Errorf("expected invalid request error, got %v", err)
+	}
+}
+
+func TestGetCompletion_RejectsUnsupportedSampling(t *testing.T) {
+	client := NewTestClient()
+	client.ModelRegistry = NewModelRegistry(map[string]ModelInfo{
+		"reasoning": {MaxOutputTokens: 1000},
+	})
+
+	for name, payload := range map[string]*CompletionRequestPayload{
+		"temperature": {Model: "reasoning", Temperature: Ptr(0.2)},
+		"max tokens":  {Model: "reasoning", MaxCompletionTokens: Ptr(2000)},
+	} {
+		payload.Messages = []Message{{Role: MessageRoleUser, Content: "Hi"}}
+		if _, err := client.GetCompletion(payload); GetOpenAIErrorType(err) != ErrTypeInvalidRequest {
+			t.Errorf("%s: expected invalid request error, got %v", name, err)
+		}
+	}
+	if len(client.Completions()) != 0 {
+		t.Error("expected no request to be sent")
+	}
+}
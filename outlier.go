@@ -0,0 +1,78 @@
+package openaiclient
+
+import (
+	"context"
+	"fmt"
+)
+
+const defaultOutlierNeighbors = 1
+
+type (
+	// OutlierDetector flags inputs that are far from every entry of a
+	// reference index, e.g. off-topic or novel user queries against an index
+	// of the expected ones. It is safe for concurrent use if the index is.
+	OutlierDetector struct {
+		// Threshold is the cosine distance, from 0 to 2, above which an input
+		// is an outlier.
+		Threshold float64
+		// Neighbors is the number of nearest entries the distance is averaged
+		// over, 1 by default. More neighbors make a single stray reference
+		// entry less likely to hide an outlier.
+		Neighbors int
+
+		client         *OpenAI
+		embeddingModel string
+		index          *Index
+	}
+
+	OutlierCheck struct {
+		// Distance is the mean cosine distance of the input to its nearest
+		// entries, 1 - their similarity.
+		Distance float64
+		Outlier  bool
+		Nearest  []SearchResult
+	}
+)
+
+// NewOutlierDetector returns a detector of the inputs farther than threshold
+// from the entries of the index, embedded with the given model.
+func (o *OpenAI) NewOutlierDetector(embeddingModel string, index *Index, threshold float64) *OutlierDetector {
+	return &OutlierDetector{
+		Threshold:      threshold,
+		client:         o,
+		embeddingModel: embeddingModel,
+		index:          index,
+	}
+}
+
+// Check embeds the input and checks it against the index.
+func (d *OutlierDetector) Check(ctx context.Context, input string) (*OutlierCheck, error) {
+	embedding, err := d.client.GetEmbeddingContext(ctx, GetEmbeddingPayload{Model: d.embeddingModel, Input: input})
+	if err != nil {
+		return nil, fmt.Errorf("error embedding input: %w", err)
+	}
+	return d.CheckVector(embedding)
+}
+
+// CheckVector checks an already embedded input against the index. Every
+// input is an outlier of an empty index.
+func (d *OutlierDetector) CheckVector(vector []float64) (*OutlierCheck, error) {
+	neighbors := d.Neighbors
+	if neighbors <= 0 {
+		neighbors = defaultOutlierNeighbors
+	}
+	nearest, err := d.index.Search(vector, neighbors)
+	if err != nil {
+		return nil, err
+	}
+	if len(nearest) == 0 {
+		return &OutlierCheck{Distance: 1, Outlier: true}, nil
+	}
+
+	var distance float64
+	for _, result := range nearest {
+		distance += 1 - result.Score
+	}
+	distance /= float64(len(nearest))
+	return &OutlierCheck{Distance: distance, Outlier: distance > d.Threshold, Nearest: nearest}, nil
+}
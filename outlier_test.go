@@ -0,0 +1,69 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"testing"
+)
+
+func TestOutlierDetector_Check(t *testing.T) {
+	index := NewIndex()
+	index.Add("billing", []float64{1, 0, 0}, nil)
+	index.Add("refunds", []float64{0.9, 0.1, 0}, nil)
+
+	vectors := map[string]string{
+		"Why was I charged twice?": "[0.95,0.05,0]",
+		"Write me a poem":          "[0,0,1]",
+	}
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			var payload GetEmbeddingPayload
+			body, _ := io.ReadAll(req.Body)
+			json.Unmarshal(body, &payload)
+			return fakeResponse(http.StatusOK, fmt.Sprintf(`{"data":[{"embedding":%s}]}`, vectors[payload.Input])), nil
+		},
+	}
+	detector := client.NewOutlierDetector("embedding-model", index, 0.3)
+
+	check, err := detector.Check(context.Background(), "Why was I charged twice?")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if check.Outlier || check.Distance > 0.01 || len(check.Nearest) != 1 {
+		t.Errorf("expected an on-topic query, got %+v", check)
+	}
+
+	check, err = detector.Check(context.Background(), "Write me a poem")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !check.Outlier || math.Abs(check.Distance-1) > 1e-6 {
+		t.Errorf("expected an off-topic query, got %+v", check)
+	}
+}
+
+func TestOutlierDetector_CheckVector(t *testing.T) {
+	index := NewIndex()
+	index.Add("a", []float64{1, 0}, nil)
+	index.Add("b", []float64{0, 1}, nil)
+	detector := (&OpenAI{}).NewOutlierDetector("embedding-model", index, 0.5)
+	detector.Neighbors = 2
+
+	check, err := detector.CheckVector([]float64{1, 0})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if math.Abs(check.Distance-0.5) > 1e-6 || len(check.Nearest) != 2 {
+		t.Errorf("expected the distance averaged over two neighbors, got %+v", check)
+	}
+
+	empty := (&OpenAI{}).NewOutlierDetector("embedding-model", NewIndex(), 0.5)
+	if check, _ := empty.CheckVector([]float64{1, 0}); !check.Outlier {
+		t.Errorf("expected an outlier of an empty index, got %+v", check)
+	}
+}
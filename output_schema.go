@@ -0,0 +1,152 @@
+package openaiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrUnknownOutputVersion is returned by OutputSchema.Unmarshal for outputs
+// that match neither the current schema nor a registered older version.
+var ErrUnknownOutputVersion = errors.New("output matches no known schema version")
+
+type (
+	// OutputSchema decodes the structured outputs of a Go type T whose
+	// definition changed between releases. Outputs produced for an older
+	// version of the type, e.g. by a stored prompt still in use mid-deploy,
+	// are recognized by their shape and upgraded step by step before being
+	// unmarshaled into T.
+	OutputSchema[T any] struct {
+		schema *JsonSchema
+		// versions are the older versions, oldest first.
+		versions []outputVersion
+	}
+
+	// OutputUpgrade migrates a decoded output to the next version of its
+	// schema.
+	OutputUpgrade func(output map[string]any) (map[string]any, error)
+
+	outputVersion struct {
+		schema  *JsonSchema
+		upgrade OutputUpgrade
+	}
+)
+
+// NewOutputSchema returns the schema of T, described the way ToolFromFunc
+// describes tool parameters.
+func NewOutputSchema[T any]() *OutputSchema[T] {
+	return &OutputSchema[T]{schema: jsonSchemaFor(reflect.TypeFor[T]())}
+}
+
+// Schema returns the JSON schema of the current version, to request outputs
+// with.
+func (s *OutputSchema[T]) Schema() *JsonSchema {
+	return s.schema
+}
+
+// AddVersion registers an older version of the output, described by a value
+// of the Go type it had, and the upgrade of its outputs to the next version:
+// the one registered after it, or T for the last. Versions must be
+// registered oldest first.
+func (s *OutputSchema[T]) AddVersion(previous any, upgrade OutputUpgrade) *OutputSchema[T] {
+	s.versions = append(s.versions, outputVersion{
+		schema:  jsonSchemaFor(reflect.TypeOf(previous)),
+		upgrade: upgrade,
+	})
+	return s
+}
+
+// Unmarshal decodes an output of any known version into T. Outputs matching
+// the current schema are unmarshaled as they are; otherwise the newest older
+// version they match is upgraded to the current one.
+func (s *OutputSchema[T]) Unmarshal(data []byte) (T, error) {
+	var result T
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var output any
+	if err := decoder.Decode(&output); err != nil {
+		return result, fmt.Errorf("error unmarshaling output: %w", err)
+	}
+	if matchesSchema(output, s.schema) {
+		return result, json.Unmarshal(data, &result)
+	}
+
+	for i := len(s.versions) - 1; i >= 0; i-- {
+		if !matchesSchema(output, s.versions[i].schema) {
+			continue
+		}
+		object, _ := output.(map[string]any)
+		for _, version := range s.versions[i:] {
+			var err error
+			if object, err = version.upgrade(object); err != nil {
+				return result, fmt.Errorf("error upgrading output: %w", err)
+			}
+		}
+		upgraded, err := json.Marshal(object)
+		if err != nil {
+			return result, fmt.Errorf("error marshaling upgraded output: %w", err)
+		}
+		if err := json.Unmarshal(upgraded, &result); err != nil {
+			return result, fmt.Errorf("error unmarshaling upgraded output: %w", err)
+		}
+		return result, nil
+	}
+	return result, ErrUnknownOutputVersion
+}
+
+// matchesSchema reports whether a value decoded with json.Decoder.UseNumber
+// fits the schema: objects must have every required property and no unknown
+// one, so versions that only add or rename fields are told apart.
+func matchesSchema(value any, schema *JsonSchema) bool {
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return false
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				return false
+			}
+		}
+		// Objects without properties are maps, with any keys.
+		if len(schema.Properties) == 0 {
+			return true
+		}
+		for name, property := range object {
+			propertySchema, ok := schema.Properties[name]
+			if !ok || (property != nil && !matchesSchema(property, propertySchema)) {
+				return false
+			}
+		}
+		return true
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return value == nil
+		}
+		for _, item := range items {
+			if schema.Items != nil && !matchesSchema(item, schema.Items) {
+				return false
+			}
+		}
+		return true
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "integer":
+		number, ok := value.(json.Number)
+		return ok && !strings.ContainsAny(string(number), ".eE")
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	default:
+		return true
+	}
+}
@@ -0,0 +1,83 @@
+package openaiclient
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type (
+	ticketV1 struct {
+		Title    string `json:"title"`
+		Priority string `json:"priority"`
+	}
+
+	ticketV2 struct {
+		Title    string `json:"title"`
+		Priority int    `json:"priority"`
+	}
+
+	ticket struct {
+		Summary  string   `json:"summary"`
+		Priority int      `json:"priority"`
+		Labels   []string `json:"labels,omitempty"`
+	}
+)
+
+func ticketSchema() *OutputSchema[ticket] {
+	return NewOutputSchema[ticket]().
+		AddVersion(ticketV1{}, func(output map[string]any) (map[string]any, error) {
+			priorities := map[string]int{"low": 3, "medium": 2, "high": 1}
+			priority, ok := priorities[output["priority"].(string)]
+			if !ok {
+				return nil, errors.New("unknown priority")
+			}
+			output["priority"] = priority
+			return output, nil
+		}).
+		AddVersion(ticketV2{}, func(output map[string]any) (map[string]any, error) {
+			output["summary"] = output["title"]
+			delete(output, "title")
+			return output, nil
+		})
+}
+
+func TestOutputSchema_Unmarshal(t *testing.T) {
+	schema := ticketSchema()
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{"current", `{"summary":"Login fails","priority":1,"labels":["auth"]}`},
+		{"previous", `{"title":"Login fails","priority":1}`},
+		{"oldest", `{"title":"Login fails","priority":"high"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := schema.Unmarshal([]byte(test.output))
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result.Summary != "Login fails" || result.Priority != 1 {
+				t.Errorf("unexpected result %+v", result)
+			}
+		})
+	}
+}
+
+func TestOutputSchema_Errors(t *testing.T) {
+	schema := ticketSchema()
+	if _, err := schema.Unmarshal([]byte(`{"name":"Login fails"}`)); !errors.Is(err, ErrUnknownOutputVersion) {
+		t.Errorf("expected ErrUnknownOutputVersion, got %v", err)
+	}
+	if _, err := schema.Unmarshal([]byte(`{"title":"Login fails","priority":"urgent"}`)); err == nil || !strings.Contains(err.Error(), "unknown priority") {
+		t.Errorf("expected the upgrade error, got %v", err)
+	}
+}
+
+func TestOutputSchema_Schema(t *testing.T) {
+	schema := NewOutputSchema[ticket]().Schema()
+	if len(schema.Required) != 2 || schema.Properties["labels"].Type != "array" {
+		t.Errorf("unexpected schema %+v", schema)
+	}
+}
@@ -0,0 +1,85 @@
+package openaiclient
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// pacer holds back every request of a client after the API asked for a
+// pause, so concurrent goroutines back off together instead of extending the
+// rate limit window.
+type pacer struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+func (p *pacer) wait(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	for {
+		p.mu.Lock()
+		delay := time.Until(p.until)
+		p.mu.Unlock()
+
+		if delay <= 0 {
+			return nil
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *pacer) pause(d time.Duration) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if until := time.Now().Add(d); until.After(p.until) {
+		p.until = until
+	}
+}
+
+// retryAfter reads the delay requested by the retry-after-ms or Retry-After
+// headers, the latter in either seconds or HTTP-date form.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	if ms, err := strconv.ParseFloat(header.Get("Retry-After-Ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond)), true
+	}
+
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), seconds > 0
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		d := time.Until(date)
+		return d, d > 0
+	}
+	return 0, false
+}
+
+// paceAfter pauses requests when the API asked for it after a 429, or when
+// the rate limit headers report an exhausted limit, until it resets.
+func (o *OpenAI) paceAfter(response *http.Response, limits RateLimitInfo) {
+	d, ok := time.Duration(0), false
+	if response.StatusCode == http.StatusTooManyRequests {
+		d, ok = retryAfter(response.Header)
+	}
+	if !ok {
+		d = limits.exhaustedReset()
+		ok = d > 0
+	}
+	if ok {
+		slog.Debug("pausing requests", slog.Duration("retryAfter", d))
+		o.pacer.pause(d)
+	}
+}
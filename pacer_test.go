@@ -0,0 +1,110 @@
+package openaiclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		wantOk bool
+	}{
+		{
+			name:   "seconds",
+			header: http.Header{"Retry-After": []string{"2"}},
+			want:   2 * time.Second,
+			wantOk: true,
+		},
+		{
+			name:   "milliseconds",
+			header: http.Header{"Retry-After-Ms": []string{"150"}, "Retry-After": []string{"1"}},
+			want:   150 * time.Millisecond,
+			wantOk: true,
+		},
+		{
+			name:   "missing",
+			header: http.Header{},
+			wantOk: false,
+		},
+		{
+			name:   "invalid",
+			header: http.Header{"Retry-After": []string{"soon"}},
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfter(tt.header)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("got (%v, %v), want (%v, %v)", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestPacer_SharedAcrossGoroutines(t *testing.T) {
+	var mu sync.Mutex
+	var calls []time.Time
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, time.Now())
+			if len(calls) == 1 {
+				response := fakeResponse(http.StatusTooManyRequests, `{"message":"slow down"}`)
+				response.Header = http.Header{"Retry-After-Ms": []string{"50"}}
+				return response, nil
+			}
+			return fakeResponse(http.StatusOK, `{"data":[{"embedding":[0.1]}]}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	if _, err := client.GetEmbedding(GetEmbeddingPayload{Model: "test-model", Input: "Hi"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	start := time.Now()
+	client.pacer.pause(50 * time.Millisecond)
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.GetEmbedding(GetEmbeddingPayload{Model: "test-model", Input: "Hi"})
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls[1].Sub(calls[0]) < 50*time.Millisecond {
+		t.Errorf("expected retry to wait for Retry-After, waited %v", calls[1].Sub(calls[0]))
+	}
+	for _, call := range calls[2:] {
+		if call.Sub(start) < 50*time.Millisecond {
+			t.Errorf("expected concurrent requests to wait for the pause, waited %v", call.Sub(start))
+		}
+	}
+}
+
+func TestPacer_CanceledWait(t *testing.T) {
+	var p pacer
+	p.pause(time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := p.wait(ctx); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
@@ -0,0 +1,160 @@
+package openaiclient
+
+import "context"
+
+type (
+	// Iterator walks every item of a paginated listing, fetching pages on
+	// demand. Call Next until it returns false and then check Err.
+	Iterator[T any] struct {
+		fetch   func(ctx context.Context, cursor string) (*iteratorPage[T], error)
+		items   []T
+		index   int
+		cursor  string
+		hasMore bool
+		started bool
+		current T
+		err     error
+	}
+
+	iteratorPage[T any] struct {
+		items   []T
+		cursor  string
+		hasMore bool
+	}
+)
+
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	for it.index >= len(it.items) {
+		if it.err != nil || (it.started && !it.hasMore) {
+			return false
+		}
+
+		page, err := it.fetch(ctx, it.cursor)
+		it.started = true
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.items = page.items
+		it.index = 0
+		it.cursor = page.cursor
+		it.hasMore = page.hasMore && page.cursor != ""
+	}
+
+	it.current = it.items[it.index]
+	it.index++
+	return true
+}
+
+// Current returns the item loaded by the last call to Next.
+func (it *Iterator[T]) Current() T {
+	return it.current
+}
+
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// newListIterator pages through a cursor-based listing using the after
+// parameter. id extracts the cursor from the last item when the API does not
+// return last_id.
+func newListIterator[T any](
+	params *ListParams,
+	list func(ctx context.Context, params *ListParams) (*List[T], error),
+	id func(T) string,
+) *Iterator[T] {
+	pageParams := ListParams{}
+	if params != nil {
+		pageParams = *params
+	}
+
+	return &Iterator[T]{
+		cursor: pageParams.After,
+		fetch: func(ctx context.Context, cursor string) (*iteratorPage[T], error) {
+			pageParams.After = cursor
+			page, err := list(ctx, &pageParams)
+			if err != nil {
+				return nil, err
+			}
+
+			next := page.LastId
+			if next == "" && len(page.Data) > 0 {
+				next = id(page.Data[len(page.Data)-1])
+			}
+			return &iteratorPage[T]{items: page.Data, cursor: next, hasMore: page.HasMore}, nil
+		},
+	}
+}
+
+// newUsageIterator pages through the organization usage endpoints, which use
+// page tokens instead of cursors, yielding one time bucket at a time.
+func newUsageIterator[T any](
+	params *UsageParams,
+	usage func(ctx context.Context, params *UsageParams) (*UsagePage[T], error),
+) *Iterator[UsageBucket[T]] {
+	pageParams := UsageParams{}
+	if params != nil {
+		pageParams = *params
+	}
+
+	return &Iterator[UsageBucket[T]]{
+		cursor: pageParams.Page,
+		fetch: func(ctx context.Context, cursor string) (*iteratorPage[UsageBucket[T]], error) {
+			pageParams.Page = cursor
+			page, err := usage(ctx, &pageParams)
+			if err != nil {
+				return nil, err
+			}
+			return &iteratorPage[UsageBucket[T]]{items: page.Data, cursor: page.NextPage, hasMore: page.HasMore}, nil
+		},
+	}
+}
+
+func (o *OpenAI) ListModelsAutoPaging() *Iterator[Model] {
+	return &Iterator[Model]{
+		fetch: func(ctx context.Context, _ string) (*iteratorPage[Model], error) {
+			models, err := o.ListModels(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return &iteratorPage[Model]{items: models}, nil
+		},
+	}
+}
+
+func (o *OpenAI) ListFilesAutoPaging(params *ListParams, purpose string) *Iterator[File] {
+	return newListIterator(params, func(ctx context.Context, params *ListParams) (*List[File], error) {
+		return o.ListFiles(ctx, params, purpose)
+	}, func(f File) string { return f.Id })
+}
+
+func (o *OpenAI) ListBatchesAutoPaging(params *ListParams) *Iterator[Batch] {
+	return newListIterator(params, o.ListBatches, func(b Batch) string { return b.Id })
+}
+
+func (o *OpenAI) ListProjectsAutoPaging(params *ListParams, includeArchived bool) *Iterator[Project] {
+	return newListIterator(params, func(ctx context.Context, params *ListParams) (*List[Project], error) {
+		return o.ListProjects(ctx, params, includeArchived)
+	}, func(p Project) string { return p.Id })
+}
+
+func (o *OpenAI) ListProjectApiKeysAutoPaging(projectId string, params *ListParams) *Iterator[ProjectApiKey] {
+	return newListIterator(params, func(ctx context.Context, params *ListParams) (*List[ProjectApiKey], error) {
+		return o.ListProjectApiKeys(ctx, projectId, params)
+	}, func(k ProjectApiKey) string { return k.Id })
+}
+
+func (o *OpenAI) ListProjectRateLimitsAutoPaging(projectId string, params *ListParams) *Iterator[ProjectRateLimit] {
+	return newListIterator(params, func(ctx context.Context, params *ListParams) (*List[ProjectRateLimit], error) {
+		return o.ListProjectRateLimits(ctx, projectId, params)
+	}, func(l ProjectRateLimit) string { return l.Id })
+}
+
+func (o *OpenAI) GetCompletionsUsageAutoPaging(params *UsageParams) *Iterator[UsageBucket[CompletionsUsageResult]] {
+	return newUsageIterator(params, o.GetCompletionsUsage)
+}
+
+func (o *OpenAI) GetCostsAutoPaging(params *UsageParams) *Iterator[UsageBucket[CostsResult]] {
+	return newUsageIterator(params, o.GetCosts)
+}
@@ -0,0 +1,97 @@
+package openaiclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestListFilesAutoPaging(t *testing.T) {
+	var cursors []string
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			cursors = append(cursors, query.Get("after"))
+			if query.Get("limit") != "2" || query.Get("purpose") != FilePurposeBatch {
+				t.Errorf("unexpected query %q", req.URL.RawQuery)
+			}
+			switch query.Get("after") {
+			case "":
+				return fakeResponse(200, `{"object":"list","data":[{"id":"file_1"},{"id":"file_2"}],"last_id":"file_2","has_more":true}`), nil
+			case "file_2":
+				return fakeResponse(200, `{"object":"list","data":[{"id":"file_3"}],"has_more":true}`), nil
+			default:
+				return fakeResponse(200, `{"object":"list","data":[],"has_more":false}`), nil
+			}
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	ctx := context.Background()
+	it := client.ListFilesAutoPaging(&ListParams{Limit: 2}, FilePurposeBatch)
+
+	var ids []string
+	for it.Next(ctx) {
+		ids = append(ids, it.Current().Id)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(ids) != 3 || ids[0] != "file_1" || ids[2] != "file_3" {
+		t.Errorf("unexpected ids %v", ids)
+	}
+	if len(cursors) != 3 || cursors[1] != "file_2" || cursors[2] != "file_3" {
+		t.Errorf("unexpected cursors %v", cursors)
+	}
+}
+
+func TestGetCostsAutoPaging(t *testing.T) {
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Query().Get("page") == "" {
+				return fakeResponse(200, `{"data":[{"start_time":1},{"start_time":2}],"has_more":true,"next_page":"page_2"}`), nil
+			}
+			return fakeResponse(200, `{"data":[{"start_time":3}],"has_more":false}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	ctx := context.Background()
+	it := client.GetCostsAutoPaging(nil)
+
+	var starts []int64
+	for it.Next(ctx) {
+		starts = append(starts, it.Current().StartTime)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(starts) != 3 || starts[2] != 3 {
+		t.Errorf("unexpected buckets %v", starts)
+	}
+}
+
+func TestIterator_Error(t *testing.T) {
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("client error")
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	it := client.ListBatchesAutoPaging(nil)
+	if it.Next(context.Background()) {
+		t.Fatal("expected Next to return false")
+	}
+	if it.Err() == nil {
+		t.Error("expected error, got nil")
+	}
+}
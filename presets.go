@@ -0,0 +1,69 @@
+package openaiclient
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+type (
+	// Preset is the configuration of a distinct task of a service, such as
+	// classifying support tickets, kept in one place instead of being
+	// repeated at every call site.
+	Preset struct {
+		Model        string
+		Temperature  *float64
+		SystemPrompt string
+		Tools        []ToolDefinition
+		ExtraBody    map[string]any
+	}
+
+	// Presets maps names to the presets registered on a client.
+	Presets map[string]Preset
+
+	// PresetRunner runs a preset of a client, see OpenAI.Preset.
+	PresetRunner struct {
+		client *OpenAI
+		name   string
+	}
+)
+
+// Preset returns a runner of the named preset of OpenAI.Presets. Unknown
+// names are reported when it runs.
+func (o *OpenAI) Preset(name string) *PresetRunner {
+	return &PresetRunner{client: o, name: name}
+}
+
+// Payload returns a new payload of the preset answering input, to extend
+// before sending it, e.g. with earlier turns.
+func (r *PresetRunner) Payload(input string) (*CompletionRequestPayload, error) {
+	preset, ok := r.client.Presets[r.name]
+	if !ok {
+		return nil, NewInvalidRequestError(fmt.Sprintf("no preset %q", r.name))
+	}
+
+	payload := &CompletionRequestPayload{
+		Model:     preset.Model,
+		Tools:     slices.Clone(preset.Tools),
+		ExtraBody: maps.Clone(preset.ExtraBody),
+	}
+	if preset.Temperature != nil {
+		temperature := *preset.Temperature
+		payload.Temperature = &temperature
+	}
+	if preset.SystemPrompt != "" {
+		payload.Messages = append(payload.Messages, Message{Role: MessageRoleSystem, Content: preset.SystemPrompt})
+	}
+	payload.Messages = append(payload.Messages, Message{Role: MessageRoleUser, Content: input})
+	return payload, nil
+}
+
+// Run answers input with the preset.
+func (r *PresetRunner) Run(ctx context.Context, input string) (*Message, error) {
+	payload, err := r.Payload(input)
+	if err != nil {
+		return nil, err
+	}
+	return r.client.GetCompletionContext(ctx, payload)
+}
@@ -0,0 +1,42 @@
+package openaiclient
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPreset_Run(t *testing.T) {
+	client := NewTestClient(ScriptedTurn{Content: "billing"})
+	temperature := 0.0
+	client.Presets = Presets{
+		"support-triage": {
+			Model:        "gpt-4o-mini",
+			Temperature:  &temperature,
+			SystemPrompt: "Classify the ticket.",
+			ExtraBody:    map[string]any{"seed": 1},
+		},
+	}
+
+	message, err := client.Preset("support-triage").Run(context.Background(), "I was charged twice")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if message.Content != "billing" {
+		t.Errorf("expected the scripted answer, got %q", message.Content)
+	}
+
+	sent := client.Completions()[0]
+	if sent.Model != "gpt-4o-mini" || len(sent.Messages) != 2 || sent.Messages[0].Content != "Classify the ticket." || sent.Messages[1].Content != "I was charged twice" {
+		t.Errorf("unexpected request %+v", sent)
+	}
+	if sent.Temperature == nil || *sent.Temperature != 0 {
+		t.Errorf("expected the preset's temperature, got %v", sent.Temperature)
+	}
+}
+
+func TestPreset_Unknown(t *testing.T) {
+	client := NewTestClient()
+	if _, err := client.Preset("missing").Run(context.Background(), "Hi"); GetOpenAIErrorType(err) != ErrTypeInvalidRequest {
+		t.Errorf("expected an invalid request error, got %v", err)
+	}
+}
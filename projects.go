@@ -0,0 +1,212 @@
+package openaiclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type (
+	ListParams struct {
+		Limit int
+		After string
+	}
+
+	List[T any] struct {
+		Object  string `json:"object"`
+		Data    []T    `json:"data"`
+		FirstId string `json:"first_id"`
+		LastId  string `json:"last_id"`
+		HasMore bool   `json:"has_more"`
+	}
+
+	Project struct {
+		Id         string `json:"id"`
+		Object     string `json:"object"`
+		Name       string `json:"name"`
+		CreatedAt  int64  `json:"created_at"`
+		ArchivedAt *int64 `json:"archived_at,omitempty"`
+		Status     string `json:"status"`
+	}
+
+	ProjectUser struct {
+		Id    string `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email,omitempty"`
+		Role  string `json:"role"`
+	}
+
+	ProjectServiceAccount struct {
+		Id        string `json:"id"`
+		Object    string `json:"object"`
+		Name      string `json:"name"`
+		Role      string `json:"role"`
+		CreatedAt int64  `json:"created_at"`
+	}
+
+	ProjectApiKeyOwner struct {
+		Type           string                 `json:"type"`
+		User           *ProjectUser           `json:"user,omitempty"`
+		ServiceAccount *ProjectServiceAccount `json:"service_account,omitempty"`
+	}
+
+	ProjectApiKey struct {
+		Id            string             `json:"id"`
+		Object        string             `json:"object"`
+		Name          string             `json:"name"`
+		RedactedValue string             `json:"redacted_value"`
+		CreatedAt     int64              `json:"created_at"`
+		LastUsedAt    int64              `json:"last_used_at,omitempty"`
+		Owner         ProjectApiKeyOwner `json:"owner"`
+	}
+
+	ServiceAccountApiKey struct {
+		Id        string `json:"id"`
+		Object    string `json:"object"`
+		Name      string `json:"name"`
+		Value     string `json:"value"`
+		CreatedAt int64  `json:"created_at"`
+	}
+
+	// CreatedServiceAccount is returned once when a service account is
+	// created; ApiKey.Value is the only time the unredacted key is exposed.
+	CreatedServiceAccount struct {
+		ProjectServiceAccount
+		ApiKey *ServiceAccountApiKey `json:"api_key"`
+	}
+
+	ProjectRateLimit struct {
+		Id                          string `json:"id"`
+		Object                      string `json:"object"`
+		Model                       string `json:"model"`
+		MaxRequestsPer1Minute       int    `json:"max_requests_per_1_minute"`
+		MaxTokensPer1Minute         int    `json:"max_tokens_per_1_minute"`
+		MaxImagesPer1Minute         int    `json:"max_images_per_1_minute,omitempty"`
+		MaxAudioMegabytesPer1Minute int    `json:"max_audio_megabytes_per_1_minute,omitempty"`
+		MaxRequestsPer1Day          int    `json:"max_requests_per_1_day,omitempty"`
+		Batch1DayMaxInputTokens     int    `json:"batch_1_day_max_input_tokens,omitempty"`
+	}
+
+	UpdateProjectRateLimitPayload struct {
+		MaxRequestsPer1Minute       *int `json:"max_requests_per_1_minute,omitempty"`
+		MaxTokensPer1Minute         *int `json:"max_tokens_per_1_minute,omitempty"`
+		MaxImagesPer1Minute         *int `json:"max_images_per_1_minute,omitempty"`
+		MaxAudioMegabytesPer1Minute *int `json:"max_audio_megabytes_per_1_minute,omitempty"`
+		MaxRequestsPer1Day          *int `json:"max_requests_per_1_day,omitempty"`
+		Batch1DayMaxInputTokens     *int `json:"batch_1_day_max_input_tokens,omitempty"`
+		// ExtraBody is merged into the serialized request, for parameters the
+		// library does not model yet.
+		ExtraBody map[string]any `json:"-"`
+	}
+
+	DeletedObject struct {
+		Id      string `json:"id"`
+		Object  string `json:"object"`
+		Deleted bool   `json:"deleted"`
+	}
+)
+
+func (o *OpenAI) ListProjects(ctx context.Context, params *ListParams, includeArchived bool) (*List[Project], error) {
+	query := params.values()
+	if includeArchived {
+		query.Set("include_archived", "true")
+	}
+
+	var projects List[Project]
+	if err := o.get(ctx, projectsEndpoint, query, &projects); err != nil {
+		return nil, err
+	}
+	return &projects, nil
+}
+
+func (o *OpenAI) CreateProject(ctx context.Context, name string) (*Project, error) {
+	var project Project
+	body := map[string]string{"name": name}
+	if err := o.call(ctx, http.MethodPost, projectsEndpoint, body, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (o *OpenAI) GetProject(ctx context.Context, projectId string) (*Project, error) {
+	var project Project
+	if err := o.get(ctx, projectEndpoint(projectId), nil, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (o *OpenAI) ArchiveProject(ctx context.Context, projectId string) (*Project, error) {
+	var project Project
+	if err := o.call(ctx, http.MethodPost, projectEndpoint(projectId, "archive"), nil, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (o *OpenAI) ListProjectApiKeys(ctx context.Context, projectId string, params *ListParams) (*List[ProjectApiKey], error) {
+	var keys List[ProjectApiKey]
+	if err := o.get(ctx, projectEndpoint(projectId, "api_keys"), params.values(), &keys); err != nil {
+		return nil, err
+	}
+	return &keys, nil
+}
+
+func (o *OpenAI) DeleteProjectApiKey(ctx context.Context, projectId, keyId string) (*DeletedObject, error) {
+	var deleted DeletedObject
+	if err := o.call(ctx, http.MethodDelete, projectEndpoint(projectId, "api_keys", keyId), nil, &deleted); err != nil {
+		return nil, err
+	}
+	return &deleted, nil
+}
+
+// CreateProjectServiceAccount creates a service account in the project
+// together with a new project API key, which is how keys are provisioned
+// through the Admin API.
+func (o *OpenAI) CreateProjectServiceAccount(ctx context.Context, projectId, name string) (*CreatedServiceAccount, error) {
+	var account CreatedServiceAccount
+	body := map[string]string{"name": name}
+	if err := o.call(ctx, http.MethodPost, projectEndpoint(projectId, "service_accounts"), body, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (o *OpenAI) ListProjectRateLimits(ctx context.Context, projectId string, params *ListParams) (*List[ProjectRateLimit], error) {
+	var limits List[ProjectRateLimit]
+	if err := o.get(ctx, projectEndpoint(projectId, "rate_limits"), params.values(), &limits); err != nil {
+		return nil, err
+	}
+	return &limits, nil
+}
+
+func (o *OpenAI) UpdateProjectRateLimit(ctx context.Context, projectId, rateLimitId string, payload *UpdateProjectRateLimitPayload) (*ProjectRateLimit, error) {
+	var limit ProjectRateLimit
+	if err := o.call(ctx, http.MethodPost, projectEndpoint(projectId, "rate_limits", rateLimitId), payload, &limit); err != nil {
+		return nil, err
+	}
+	return &limit, nil
+}
+
+func projectEndpoint(projectId string, path ...string) string {
+	endpoint := projectsEndpoint + "/" + url.PathEscape(projectId)
+	for _, p := range path {
+		endpoint += "/" + url.PathEscape(p)
+	}
+	return endpoint
+}
+
+func (p *ListParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Limit > 0 {
+		values.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.After != "" {
+		values.Set("after", p.After)
+	}
+	return values
+}
@@ -0,0 +1,135 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestAdminKey(t *testing.T) {
+	var authHeaders []string
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			authHeaders = append(authHeaders, req.Header.Get("Authorization"))
+			return fakeResponse(200, `{"object":"list","data":[]}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+	client.AdminKey = "admin-key"
+
+	if _, err := client.ListProjects(context.Background(), nil, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.ListModels(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if authHeaders[0] != "Bearer admin-key" {
+		t.Errorf("expected admin key for organization endpoints, got '%s'", authHeaders[0])
+	}
+	if authHeaders[1] != "Bearer test-key" {
+		t.Errorf("expected api key for other endpoints, got '%s'", authHeaders[1])
+	}
+}
+
+func TestListProjects(t *testing.T) {
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			query := req.URL.Query()
+			if query.Get("after") != "proj_1" || query.Get("limit") != "2" || query.Get("include_archived") != "true" {
+				t.Errorf("unexpected query %q", req.URL.RawQuery)
+			}
+			return fakeResponse(200, `{
+				"object": "list",
+				"data": [{"id": "proj_2", "object": "organization.project", "name": "Tenant A", "status": "active"}],
+				"first_id": "proj_2",
+				"last_id": "proj_2",
+				"has_more": false
+			}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	projects, err := client.ListProjects(context.Background(), &ListParams{Limit: 2, After: "proj_1"}, true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(projects.Data) != 1 || projects.Data[0].Name != "Tenant A" {
+		t.Errorf("unexpected projects %+v", projects.Data)
+	}
+}
+
+func TestCreateProjectServiceAccount(t *testing.T) {
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodPost {
+				t.Errorf("expected POST request, got %s", req.Method)
+			}
+			if req.URL.Path != "/v1/organization/projects/proj_abc/service_accounts" {
+				t.Errorf("unexpected path %s", req.URL.Path)
+			}
+			body, _ := io.ReadAll(req.Body)
+			if string(body) != `{"name":"tenant-bot"}` {
+				t.Errorf("unexpected body %s", body)
+			}
+			return fakeResponse(200, `{
+				"object": "organization.project.service_account",
+				"id": "svc_acct_abc",
+				"name": "tenant-bot",
+				"role": "member",
+				"created_at": 1711471533,
+				"api_key": {"object": "organization.project.service_account.api_key", "value": "sk-abcdefghijklmnop123", "name": "Secret Key", "id": "key_abc"}
+			}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	account, err := client.CreateProjectServiceAccount(context.Background(), "proj_abc", "tenant-bot")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if account.Id != "svc_acct_abc" {
+		t.Errorf("expected id 'svc_acct_abc', got '%s'", account.Id)
+	}
+	if account.ApiKey == nil || account.ApiKey.Value != "sk-abcdefghijklmnop123" {
+		t.Errorf("expected api key value to be returned, got %+v", account.ApiKey)
+	}
+}
+
+func TestUpdateProjectRateLimit(t *testing.T) {
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/v1/organization/projects/proj_abc/rate_limits/rl_gpt" {
+				t.Errorf("unexpected path %s", req.URL.Path)
+			}
+			var body map[string]any
+			json.NewDecoder(req.Body).Decode(&body)
+			if len(body) != 1 || body["max_requests_per_1_minute"] != float64(100) {
+				t.Errorf("unexpected body %v", body)
+			}
+			return fakeResponse(200, `{"object": "project.rate_limit", "id": "rl_gpt", "model": "gpt-4o", "max_requests_per_1_minute": 100, "max_tokens_per_1_minute": 1000}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	requests := 100
+	limit, err := client.UpdateProjectRateLimit(context.Background(), "proj_abc", "rl_gpt", &UpdateProjectRateLimitPayload{
+		MaxRequestsPer1Minute: &requests,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if limit.MaxRequestsPer1Minute != 100 {
+		t.Errorf("expected 100 requests per minute, got %d", limit.MaxRequestsPer1Minute)
+	}
+}
@@ -0,0 +1,56 @@
+package openaiclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// PromptHashMetadataKey is the metadata key the prompt hash of stored
+// completions is sent under.
+const PromptHashMetadataKey = "prompt_hash"
+
+// PromptHash returns a short, stable hash of the payload's system and
+// developer messages and tool schemas: the parts of a prompt that are
+// versioned with the code, as opposed to the conversation. Tools are hashed
+// in name order, so building the list from a map does not change the hash.
+func PromptHash(payload *CompletionRequestPayload) string {
+	hash := sha256.New()
+	for _, message := range payload.Messages {
+		if message.Role == MessageRoleSystem || message.Role == MessageRoleDeveloper {
+			hash.Write([]byte(string(message.Role) + "\x00" + message.Content + "\x00"))
+		}
+	}
+
+	tools := slices.Clone(payload.Tools)
+	slices.SortFunc(tools, func(a, b ToolDefinition) int {
+		return strings.Compare(a.Function.Name, b.Function.Name)
+	})
+	for _, tool := range tools {
+		schema, _ := json.Marshal(tool)
+		hash.Write(schema)
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// withPromptHash stamps the prompt hash in the metadata of stored payloads,
+// the only ones the API accepts metadata for, unless the caller set one.
+func withPromptHash(payload *CompletionRequestPayload) *CompletionRequestPayload {
+	if !payload.stored() {
+		return payload
+	}
+	if _, ok := payload.Metadata[PromptHashMetadataKey]; ok {
+		return payload
+	}
+
+	stamped := *payload
+	stamped.Metadata = maps.Clone(payload.Metadata)
+	if stamped.Metadata == nil {
+		stamped.Metadata = map[string]string{}
+	}
+	stamped.Metadata[PromptHashMetadataKey] = PromptHash(payload)
+	return &stamped
+}
@@ -0,0 +1,70 @@
+package openaiclient
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestPromptHash(t *testing.T) {
+	weather := NewToolDefinition(&FunctionDefinition{Name: "weather", Description: "Gets the weather"})
+	clock := NewToolDefinition(&FunctionDefinition{Name: "time", Description: "Gets the time"})
+	payload := &CompletionRequestPayload{
+		Messages: []Message{
+			{Role: MessageRoleSystem, Content: "You are helpful."},
+			{Role: MessageRoleUser, Content: "Hi"},
+		},
+		Tools: []ToolDefinition{weather, clock},
+	}
+	hash := PromptHash(payload)
+	if len(hash) != 16 {
+		t.Fatalf("expected a 16 character hash, got %q", hash)
+	}
+
+	conversation := &CompletionRequestPayload{
+		Messages: append(payload.Messages, Message{Role: MessageRoleAssistant, Content: "Hello"}),
+		Tools:    []ToolDefinition{clock, weather},
+	}
+	if PromptHash(conversation) != hash {
+		t.Error("expected the conversation and tool order not to change the hash")
+	}
+
+	changed := &CompletionRequestPayload{
+		Messages: []Message{{Role: MessageRoleSystem, Content: "You are terse."}},
+		Tools:    payload.Tools,
+	}
+	if PromptHash(changed) == hash {
+		t.Error("expected a different system prompt to change the hash")
+	}
+}
+
+func TestGetCompletion_StampsPromptHash(t *testing.T) {
+	store := true
+	payload := &CompletionRequestPayload{
+		Model:    "test-model",
+		Messages: []Message{{Role: MessageRoleSystem, Content: "You are helpful."}, {Role: MessageRoleUser, Content: "Hi"}},
+		Store:    &store,
+		Metadata: map[string]string{"team": "search"},
+	}
+
+	var sent CompletionRequestPayload
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			json.Unmarshal(body, &sent)
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+
+	if _, err := client.GetCompletion(payload); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if sent.Metadata[PromptHashMetadataKey] != PromptHash(payload) || sent.Metadata["team"] != "search" {
+		t.Errorf("expected the prompt hash in the metadata, got %v", sent.Metadata)
+	}
+	if _, ok := payload.Metadata[PromptHashMetadataKey]; ok {
+		t.Error("expected the caller's metadata to be left untouched")
+	}
+}
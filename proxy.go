@@ -0,0 +1,198 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// maxProxyBodySize bounds the requests accepted by ProxyHandler.
+const maxProxyBodySize = 32 << 20
+
+// passToolCallsKey marks the completions whose tool calls are returned to
+// the caller instead of run, see ProxyHandler.
+type passToolCallsKey struct{}
+
+// ProxyOptions configures a ProxyHandler.
+type ProxyOptions struct {
+	// AllowUnauthenticated serves every caller with the client's key when
+	// the client has no VirtualKeys. Without it, such a handler rejects all
+	// requests rather than act as an open relay.
+	AllowUnauthenticated bool
+}
+
+// ProxyHandler returns an http.Handler serving OpenAI-format chat completion
+// requests, e.g. mounted at /v1/chat/completions, through the client. The
+// requests get everything configured on the client, such as its retries,
+// fallback models, budgets, validation and usage tracking, which makes it a
+// small LLM gateway. Fields the library does not model are passed through,
+// in the requests and in the responses.
+//
+// The callers authenticate with the client's VirtualKeys. Other bearer keys
+// are ignored: the requests are sent with the client's key. The name of a
+// virtual key is the tenant of its requests, e.g. in Traffic and Audit
+// records. Without VirtualKeys, every request is rejected unless the
+// options allow unauthenticated callers.
+//
+// The tools of proxied requests belong to the caller, so their tool calls
+// are answered as they are rather than run. Streaming is not supported.
+func (o *OpenAI) ProxyHandler(options ProxyOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeProxyError(w, http.StatusMethodNotAllowed, &OpenAIError{Type: ErrTypeInvalidRequest, Message: "method not allowed"})
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxProxyBodySize))
+		if err != nil {
+			writeProxyError(w, http.StatusBadRequest, &OpenAIError{Type: ErrTypeInvalidRequest, Message: "error reading body"})
+			return
+		}
+		payload, err := decodeProxyPayload(body)
+		if err != nil {
+			writeProxyError(w, http.StatusBadRequest, &OpenAIError{Type: ErrTypeInvalidRequest, Message: err.Error()})
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), passToolCallsKey{}, true)
+		client := o
+		if o.VirtualKeys == nil && !options.AllowUnauthenticated {
+			writeProxyError(w, http.StatusUnauthorized, &OpenAIError{Type: ErrTypeAuthentication, Message: "no authentication is configured"})
+			return
+		}
+		if o.VirtualKeys != nil {
+			var key VirtualKey
+			if client, key, err = o.VirtualKeys.authorize(o, r, payload.Model); err != nil {
+				status, apiErr := proxyError(err)
+				writeProxyError(w, status, apiErr)
+				return
+			}
+			if Tenant(ctx) == "" {
+				ctx = WithTenant(ctx, key.Name)
+			}
+			ctx = withAllowedModels(ctx, key.Models)
+		}
+
+		response, err := client.GetCompletionResponse(ctx, payload)
+		if err != nil {
+			status, apiErr := proxyError(err)
+			writeProxyError(w, status, apiErr)
+			return
+		}
+
+		body, err = json.Marshal(response)
+		if err != nil {
+			writeProxyError(w, http.StatusInternalServerError, &OpenAIError{Type: "upstream_error", Message: err.Error()})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(mergeJSON(response.Raw, body))
+	})
+}
+
+// mergeJSON returns the JSON of base with the fields of overlay set over
+// it, merging nested objects and arrays of the same length. It keeps the
+// fields of an upstream response the library does not model.
+func mergeJSON(base, overlay json.RawMessage) json.RawMessage {
+	var baseObject, overlayObject map[string]json.RawMessage
+	if json.Unmarshal(base, &baseObject) == nil && json.Unmarshal(overlay, &overlayObject) == nil && baseObject != nil && overlayObject != nil {
+		for name, value := range overlayObject {
+			baseObject[name] = mergeJSON(baseObject[name], value)
+		}
+		if merged, err := json.Marshal(baseObject); err == nil {
+			return merged
+		}
+		return overlay
+	}
+	var baseArray, overlayArray []json.RawMessage
+	if json.Unmarshal(base, &baseArray) == nil && json.Unmarshal(overlay, &overlayArray) == nil && len(baseArray) == len(overlayArray) {
+		for i, value := range overlayArray {
+			baseArray[i] = mergeJSON(baseArray[i], value)
+		}
+		if merged, err := json.Marshal(baseArray); err == nil {
+			return merged
+		}
+	}
+	return overlay
+}
+
+// passesToolCalls reports whether the tool calls of the completion are
+// returned to the caller instead of run.
+func passesToolCalls(ctx context.Context) bool {
+	pass, _ := ctx.Value(passToolCallsKey{}).(bool)
+	return pass
+}
+
+// decodeProxyPayload decodes a chat completion request, keeping the fields
+// the payload does not model in its ExtraBody.
+func decodeProxyPayload(body []byte) (*CompletionRequestPayload, error) {
+	var payload CompletionRequestPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	if stream, ok := fields["stream"]; ok && string(stream) == "true" {
+		return nil, errors.New("streaming is not supported")
+	}
+
+	modeled, err := json.Marshal(&payload)
+	if err != nil {
+		return nil, err
+	}
+	var modeledFields map[string]json.RawMessage
+	if err := json.Unmarshal(modeled, &modeledFields); err != nil {
+		return nil, err
+	}
+	for name, value := range fields {
+		if _, ok := modeledFields[name]; ok || name == "stream" {
+			continue
+		}
+		if payload.ExtraBody == nil {
+			payload.ExtraBody = map[string]any{}
+		}
+		payload.ExtraBody[name] = value
+	}
+	return &payload, nil
+}
+
+// proxyError returns the status and API error to answer a failed completion
+// with. Errors of the upstream API keep their status; the client's own are
+// given the status the API uses for their type.
+func proxyError(err error) (int, *OpenAIError) {
+	var apiErr *OpenAIError
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.StatusCode != 0:
+		return apiErr.StatusCode, apiErr
+	case errors.As(err, &apiErr):
+		switch {
+		case apiErr.Code == ErrCodeTokenBudgetExceeded || apiErr.Type == ErrTypeRateLimit:
+			return http.StatusTooManyRequests, apiErr
+		case apiErr.Type == ErrTypeAuthentication:
+			return http.StatusUnauthorized, apiErr
+		case apiErr.Type == ErrTypeNotFound:
+			return http.StatusNotFound, apiErr
+		case apiErr.Type == ErrTypeServiceUnavailable:
+			return http.StatusServiceUnavailable, apiErr
+		default:
+			return http.StatusBadRequest, apiErr
+		}
+	case errors.As(err, &validationErr):
+		return http.StatusBadRequest, &OpenAIError{Type: ErrTypeInvalidRequest, Message: err.Error()}
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, &OpenAIError{Type: "timeout", Message: err.Error()}
+	default:
+		return http.StatusBadGateway, &OpenAIError{Type: "upstream_error", Message: err.Error()}
+	}
+}
+
+func writeProxyError(w http.ResponseWriter, status int, apiErr *OpenAIError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]*OpenAIError{"error": apiErr})
+}
@@ -0,0 +1,125 @@
+package openaiclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func proxy(t *testing.T, client *OpenAI, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	client.ProxyHandler(ProxyOptions{AllowUnauthenticated: true}).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+	return recorder
+}
+
+func TestProxyHandler(t *testing.T) {
+	var sent map[string]any
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			json.NewDecoder(req.Body).Decode(&sent)
+			return fakeResponse(http.StatusOK, `{"id":"chatcmpl-1","model":"gpt-4o-mini","service_tier":"default","choices":[{"message":{"role":"assistant","annotations":[],"tool_calls":[{"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{}"}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":5,"completion_tokens":1,"total_tokens":6}}`), nil
+		},
+	}
+	client.Usage = &UsageTracker{}
+
+	recorder := proxy(t, client, `{
+		"model": "gpt-4o-mini",
+		"messages": [{"role": "user", "content": "Weather?"}],
+		"tools": [{"type": "function", "function": {"name": "lookup"}}],
+		"response_format": {"type": "json_object"},
+		"stream": false
+	}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body)
+	}
+
+	var response CompletionResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if response.Id != "chatcmpl-1" || len(response.Choices[0].Message.ToolCalls) != 1 || response.Choices[0].FinishReason != FinishReasonToolCalls {
+		t.Errorf("expected the tool call to be passed back, got %s", recorder.Body)
+	}
+	if body := recorder.Body.String(); !strings.Contains(body, `"service_tier":"default"`) || !strings.Contains(body, `"annotations":[]`) {
+		t.Errorf("expected unmodeled response fields to be kept, got %s", body)
+	}
+	if format, ok := sent["response_format"].(map[string]any); !ok || format["type"] != "json_object" {
+		t.Errorf("expected unmodeled fields to be passed through, got %v", sent)
+	}
+	if _, ok := sent["stream"]; ok {
+		t.Errorf("expected stream not to be forwarded, got %v", sent)
+	}
+	if client.Usage.Total().TotalTokens != 6 {
+		t.Errorf("expected the usage to be tracked, got %+v", client.Usage.Total())
+	}
+}
+
+func TestProxyHandler_Unauthenticated(t *testing.T) {
+	client := NewTestClient(ScriptedTurn{Content: "Hi"})
+	recorder := httptest.NewRecorder()
+	client.ProxyHandler(ProxyOptions{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"messages":[{"role":"user","content":"Hi"}]}`)))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d: %s", recorder.Code, recorder.Body)
+	}
+	if client.Remaining() != 1 {
+		t.Error("expected the request not to be relayed")
+	}
+}
+
+func TestProxyHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *OpenAI
+		body   string
+		status int
+	}{
+		{
+			name:   "malformed body",
+			client: NewTestClient().OpenAI,
+			body:   `{"messages":`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "streaming",
+			client: NewTestClient().OpenAI,
+			body:   `{"messages":[{"role":"user","content":"Hi"}],"stream":true}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "upstream error",
+			client: NewTestClient(ScriptedTurn{StatusCode: http.StatusTooManyRequests, Content: "slow down"}).OpenAI,
+			body:   `{"messages":[{"role":"user","content":"Hi"}]}`,
+			status: http.StatusTooManyRequests,
+		},
+		{
+			name: "token budget",
+			client: func() *OpenAI {
+				client := NewTestClient().OpenAI
+				client.Usage = &UsageTracker{TokenLimit: 1}
+				client.Usage.add("gpt-4o-mini", &LLMUsage{TotalTokens: 1})
+				return client
+			}(),
+			body:   `{"messages":[{"role":"user","content":"Hi"}]}`,
+			status: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := proxy(t, tt.client, tt.body)
+			if recorder.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, recorder.Code, recorder.Body)
+			}
+			var body struct {
+				Error *OpenAIError `json:"error"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil || body.Error == nil || body.Error.Message == "" {
+				t.Errorf("expected an OpenAI-format error, got %s", recorder.Body)
+			}
+		})
+	}
+}
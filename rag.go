@@ -0,0 +1,241 @@
+package openaiclient
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MetadataText holds the text of a chunk in the entries a Retriever adds
+	// to its index, so that saved indexes keep it.
+	MetadataText = "text"
+
+	defaultRetrieverTopK = 5
+
+	citationInstructions = "Answer the question using only the numbered sources below. " +
+		"Cite the sources you use with their number in brackets, e.g. [1] or [1][3], right after the statement they support. " +
+		"If the sources do not contain the answer, say so."
+	paraphraseInstructions = "Rewrite the search query below in %d different ways that could match relevant documents, " +
+		"using other words and spelling out what it implies. Reply with one rewrite per line and nothing else."
+	hypotheticalAnswerInstructions = "Write a short passage that plausibly answers the question below, " +
+		"as it could appear in a reference document. Reply with the passage only."
+)
+
+var (
+	citationPattern = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)\]`)
+	listMarker      = regexp.MustCompile(`^\s*(?:[-*•]|\d+[.)])\s*`)
+)
+
+type (
+	// Retriever answers questions from the chunks of an Index: it embeds and
+	// indexes chunks, retrieves the ones most similar to a question and has
+	// the model answer with citations of them.
+	Retriever struct {
+		// Model answers the questions. When empty, the client's default model
+		// is used.
+		Model string
+		// TopK is the number of chunks retrieved per question. It defaults
+		// to 5.
+		TopK int
+		// Paraphrases, when set, has the model rewrite the query that many
+		// ways, and HypotheticalAnswer has it write a passage answering it
+		// (HyDE). The chunks retrieved for the query and each of them are
+		// merged by reciprocal rank fusion, which improves recall for vague
+		// questions at the cost of a completion per Retrieve.
+		Paraphrases        int
+		HypotheticalAnswer bool
+
+		client         *OpenAI
+		embeddingModel string
+		index          *Index
+	}
+
+	RetrievedChunk struct {
+		Chunk
+		Score float64
+	}
+
+	// Citation is a source cited in an answer, numbered as in the prompt.
+	Citation struct {
+		Number  int
+		ChunkId string
+		Source  string
+		// Offset is the byte offset of the chunk in its source document.
+		Offset int
+	}
+
+	RAGAnswer struct {
+		Text string
+		// Citations lists the sources the answer cites, in order of first
+		// citation. Numbers that match no source are dropped.
+		Citations []Citation
+		Chunks    []RetrievedChunk
+	}
+)
+
+// NewRetriever returns a retriever over index that embeds with the given
+// model.
+func (o *OpenAI) NewRetriever(embeddingModel string, index *Index) *Retriever {
+	return &Retriever{client: o, embeddingModel: embeddingModel, index: index}
+}
+
+// Add embeds the chunks and adds them to the index, with their text under
+// MetadataText.
+func (r *Retriever) Add(ctx context.Context, chunks ...Chunk) error {
+	for _, chunk := range chunks {
+		embedding, err := r.client.GetEmbeddingContext(ctx, GetEmbeddingPayload{Model: r.embeddingModel, Input: chunk.Text})
+		if err != nil {
+			return fmt.Errorf("error embedding chunk %s: %w", chunk.Id, err)
+		}
+		metadata := maps.Clone(chunk.Metadata)
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		metadata[MetadataText] = chunk.Text
+		if err := r.index.Add(chunk.Id, embedding, metadata); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Retrieve returns the chunks most similar to query that match the filters.
+// With Paraphrases or HypotheticalAnswer set, the scores of the chunks are
+// their fused reciprocal rank scores rather than similarities.
+func (r *Retriever) Retrieve(ctx context.Context, query string, filters ...MetadataFilter) ([]RetrievedChunk, error) {
+	topK := r.TopK
+	if topK <= 0 {
+		topK = defaultRetrieverTopK
+	}
+
+	queries, err := r.expandQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var lists [][]SearchResult
+	for _, query := range queries {
+		embedding, err := r.client.GetEmbeddingContext(ctx, GetEmbeddingPayload{Model: r.embeddingModel, Input: query})
+		if err != nil {
+			return nil, err
+		}
+		results, err := r.index.Search(embedding, topK, filters...)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, results)
+	}
+
+	results := lists[0]
+	if len(lists) > 1 {
+		if results, err = HybridSearch(topK, lists...); err != nil {
+			return nil, err
+		}
+	}
+	chunks := make([]RetrievedChunk, 0, len(results))
+	for _, result := range results {
+		chunks = append(chunks, RetrievedChunk{
+			Chunk: Chunk{Id: result.Id, Text: result.Metadata[MetadataText], Metadata: result.Metadata},
+			Score: result.Score,
+		})
+	}
+	return chunks, nil
+}
+
+// expandQuery returns the query followed by its paraphrases and hypothetical
+// answer, as configured.
+func (r *Retriever) expandQuery(ctx context.Context, query string) ([]string, error) {
+	queries := []string{query}
+	if r.Paraphrases > 0 {
+		rewrites, err := r.complete(ctx, fmt.Sprintf(paraphraseInstructions, r.Paraphrases), query)
+		if err != nil {
+			return nil, fmt.Errorf("error rewriting query: %w", err)
+		}
+		for line := range strings.Lines(rewrites) {
+			if line = strings.TrimSpace(listMarker.ReplaceAllString(line, "")); line != "" && len(queries) <= r.Paraphrases {
+				queries = append(queries, line)
+			}
+		}
+	}
+	if r.HypotheticalAnswer {
+		answer, err := r.complete(ctx, hypotheticalAnswerInstructions, query)
+		if err != nil {
+			return nil, fmt.Errorf("error writing hypothetical answer: %w", err)
+		}
+		if answer = strings.TrimSpace(answer); answer != "" {
+			queries = append(queries, answer)
+		}
+	}
+	return queries, nil
+}
+
+func (r *Retriever) complete(ctx context.Context, instructions, content string) (string, error) {
+	message, err := r.client.GetCompletionContext(ctx, &CompletionRequestPayload{
+		Model: r.Model,
+		Messages: []Message{
+			{Role: MessageRoleSystem, Content: instructions},
+			{Role: MessageRoleUser, Content: content},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return message.Content, nil
+}
+
+// Answer answers the question from the retrieved chunks, numbered in the
+// prompt so the model can cite them, and parses the citations of the answer.
+func (r *Retriever) Answer(ctx context.Context, question string, filters ...MetadataFilter) (*RAGAnswer, error) {
+	chunks, err := r.Retrieve(ctx, question, filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := r.complete(ctx, citationInstructions+"\n\n"+numberedSources(chunks), question)
+	if err != nil {
+		return nil, err
+	}
+	return &RAGAnswer{Text: text, Citations: parseCitations(text, chunks), Chunks: chunks}, nil
+}
+
+func numberedSources(chunks []RetrievedChunk) string {
+	var sources strings.Builder
+	for i, chunk := range chunks {
+		fmt.Fprintf(&sources, "[%d]", i+1)
+		if source := chunk.Metadata[MetadataSource]; source != "" {
+			fmt.Fprintf(&sources, " (%s)", source)
+		}
+		fmt.Fprintf(&sources, "\n%s\n\n", chunk.Text)
+	}
+	return strings.TrimSpace(sources.String())
+}
+
+// parseCitations returns the sources cited in text as [n] or [n, m].
+func parseCitations(text string, chunks []RetrievedChunk) []Citation {
+	var numbers []int
+	for _, match := range citationPattern.FindAllStringSubmatch(text, -1) {
+		for field := range strings.SplitSeq(match[1], ",") {
+			number, _ := strconv.Atoi(strings.TrimSpace(field))
+			if number >= 1 && number <= len(chunks) && !slices.Contains(numbers, number) {
+				numbers = append(numbers, number)
+			}
+		}
+	}
+
+	citations := make([]Citation, 0, len(numbers))
+	for _, number := range numbers {
+		chunk := chunks[number-1]
+		offset, _ := strconv.Atoi(chunk.Metadata[MetadataOffset])
+		citations = append(citations, Citation{
+			Number:  number,
+			ChunkId: chunk.Id,
+			Source:  chunk.Metadata[MetadataSource],
+			Offset:  offset,
+		})
+	}
+	return citations
+}
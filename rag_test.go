@@ -0,0 +1,141 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// ragClient answers embeddings with the vector of the first keyword the
+// input contains, and completions with answer.
+func ragClient(t *testing.T, answer string, prompts *[]string) *OpenAI {
+	t.Helper()
+	keywords := map[string]string{"refund": "[1,0,0]", "shipping": "[0,1,0]", "account": "[0,0,1]"}
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			if req.URL.Path == embeddingsEndpoint {
+				var payload GetEmbeddingPayload
+				json.Unmarshal(body, &payload)
+				for keyword, vector := range keywords {
+					if strings.Contains(strings.ToLower(payload.Input), keyword) {
+						return fakeResponse(http.StatusOK, fmt.Sprintf(`{"data":[{"embedding":%s}]}`, vector)), nil
+					}
+				}
+				return fakeResponse(http.StatusOK, `{"data":[{"embedding":[0.1,0.1,0.1]}]}`), nil
+			}
+
+			var payload CompletionRequestPayload
+			json.Unmarshal(body, &payload)
+			if prompts != nil {
+				*prompts = append(*prompts, payload.Messages[0].Content)
+			}
+			content, _ := json.Marshal(answer)
+			return fakeResponse(http.StatusOK, fmt.Sprintf(`{"choices":[{"message":{"role":"assistant","content":%s}}]}`, content)), nil
+		},
+	}
+	return client
+}
+
+func ragChunks() []Chunk {
+	return []Chunk{
+		{Id: "policy.md#0", Text: "Refunds are issued within 14 days.", Metadata: map[string]string{MetadataSource: "policy.md", MetadataOffset: "0"}},
+		{Id: "policy.md#1", Text: "Refund requests need the order number.", Metadata: map[string]string{MetadataSource: "policy.md", MetadataOffset: "120"}},
+		{Id: "faq.md#0", Text: "Shipping takes 3 days.", Metadata: map[string]string{MetadataSource: "faq.md", MetadataOffset: "0"}},
+	}
+}
+
+func TestRetriever_Answer(t *testing.T) {
+	var prompts []string
+	client := ragClient(t, "Refunds take 14 days [1], and need the order number [2, 1]. See also [7].", &prompts)
+	retriever := client.NewRetriever("embedding-model", NewIndex())
+	retriever.TopK = 2
+	if err := retriever.Add(context.Background(), ragChunks()...); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	answer, err := retriever.Answer(context.Background(), "How do refunds work?")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(answer.Chunks) != 2 || answer.Chunks[0].Text == "" {
+		t.Fatalf("expected the two refund chunks, got %+v", answer.Chunks)
+	}
+	if !strings.Contains(prompts[0], "[1] (policy.md)\n"+answer.Chunks[0].Text) {
+		t.Errorf("expected numbered sources in the prompt, got %q", prompts[0])
+	}
+
+	if len(answer.Citations) != 2 {
+		t.Fatalf("expected the two valid citations, got %+v", answer.Citations)
+	}
+	second := answer.Citations[1]
+	if second.Number != 2 || second.ChunkId != answer.Chunks[1].Id || second.Source != "policy.md" {
+		t.Errorf("unexpected citation %+v", second)
+	}
+}
+
+func TestRetriever_RetrieveWithFilters(t *testing.T) {
+	retriever := ragClient(t, "", nil).NewRetriever("embedding-model", NewIndex())
+	retriever.Add(context.Background(), ragChunks()...)
+
+	chunks, err := retriever.Retrieve(context.Background(), "refund", MetadataEquals(MetadataSource, "faq.md"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].Id != "faq.md#0" {
+		t.Errorf("expected the faq chunk only, got %+v", chunks)
+	}
+}
+
+func TestRetriever_MultiQuery(t *testing.T) {
+	var embedded []string
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			if req.URL.Path == embeddingsEndpoint {
+				var payload GetEmbeddingPayload
+				json.Unmarshal(body, &payload)
+				embedded = append(embedded, payload.Input)
+				vector := "[0.1,0.1,0.1]"
+				if strings.Contains(strings.ToLower(payload.Input), "shipping") {
+					vector = "[0,1,0]"
+				} else if strings.Contains(strings.ToLower(payload.Input), "refund") {
+					vector = "[1,0,0]"
+				}
+				return fakeResponse(http.StatusOK, fmt.Sprintf(`{"data":[{"embedding":%s}]}`, vector)), nil
+			}
+
+			var payload CompletionRequestPayload
+			json.Unmarshal(body, &payload)
+			content := `"Shipping passage"`
+			if strings.HasPrefix(payload.Messages[0].Content, "Rewrite") {
+				content = `"1. shipping time\n2. delivery delay"`
+			}
+			return fakeResponse(http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":`+content+`}}]}`), nil
+		},
+	}
+
+	retriever := client.NewRetriever("embedding-model", NewIndex())
+	retriever.Add(context.Background(), ragChunks()...)
+	embedded = nil
+	retriever.TopK = 2
+	retriever.Paraphrases = 1
+	retriever.HypotheticalAnswer = true
+
+	chunks, err := retriever.Retrieve(context.Background(), "money back?")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := []string{"money back?", "shipping time", "Shipping passage"}; strings.Join(embedded, "|") != strings.Join(want, "|") {
+		t.Errorf("expected the query, one paraphrase and the hypothetical answer, got %q", embedded)
+	}
+	if len(chunks) != 2 || chunks[0].Id != "policy.md#0" || chunks[1].Id != "faq.md#0" {
+		t.Errorf("expected the fused results, got %+v", chunks)
+	}
+}
@@ -0,0 +1,122 @@
+package openaiclient
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// RateLimitInfo is the rate limit state a provider reports in response
+	// headers. Limits are zero when not reported.
+	RateLimitInfo struct {
+		LimitRequests     int
+		RemainingRequests int
+		ResetRequests     time.Duration
+		LimitTokens       int
+		RemainingTokens   int
+		ResetTokens       time.Duration
+	}
+
+	// RateLimitHeaders reads the rate limit headers of a provider. It reports
+	// false when the response carries none.
+	RateLimitHeaders func(header http.Header) (RateLimitInfo, bool)
+)
+
+// OpenAIRateLimitHeaders reads the x-ratelimit-* headers of OpenAI, which
+// Groq and most compatible servers also send.
+func OpenAIRateLimitHeaders(header http.Header) (RateLimitInfo, bool) {
+	info := RateLimitInfo{
+		LimitRequests:     headerInt(header, "X-Ratelimit-Limit-Requests"),
+		RemainingRequests: headerInt(header, "X-Ratelimit-Remaining-Requests"),
+		ResetRequests:     headerDuration(header, "X-Ratelimit-Reset-Requests"),
+		LimitTokens:       headerInt(header, "X-Ratelimit-Limit-Tokens"),
+		RemainingTokens:   headerInt(header, "X-Ratelimit-Remaining-Tokens"),
+		ResetTokens:       headerDuration(header, "X-Ratelimit-Reset-Tokens"),
+	}
+	return info, info.LimitRequests > 0 || info.LimitTokens > 0
+}
+
+// TogetherRateLimitHeaders reads the headers of Together AI, which reports
+// requests under x-ratelimit-* and tokens under x-tokenlimit-*.
+func TogetherRateLimitHeaders(header http.Header) (RateLimitInfo, bool) {
+	info := RateLimitInfo{
+		LimitRequests:     headerInt(header, "X-Ratelimit-Limit"),
+		RemainingRequests: headerInt(header, "X-Ratelimit-Remaining"),
+		ResetRequests:     headerDuration(header, "X-Ratelimit-Reset"),
+		LimitTokens:       headerInt(header, "X-Tokenlimit-Limit"),
+		RemainingTokens:   headerInt(header, "X-Tokenlimit-Remaining"),
+	}
+	return info, info.LimitRequests > 0 || info.LimitTokens > 0
+}
+
+// FireworksRateLimitHeaders reads the headers of Fireworks AI, which limits
+// prompt and generated tokens separately. The tighter of the two is used.
+func FireworksRateLimitHeaders(header http.Header) (RateLimitInfo, bool) {
+	info, _ := OpenAIRateLimitHeaders(header)
+	for _, kind := range []string{"Prompt", "Generated"} {
+		limit := headerInt(header, "X-Ratelimit-Limit-Tokens-"+kind)
+		if limit <= 0 {
+			continue
+		}
+		remaining := headerInt(header, "X-Ratelimit-Remaining-Tokens-"+kind)
+		if info.LimitTokens == 0 || remaining < info.RemainingTokens {
+			info.LimitTokens, info.RemainingTokens = limit, remaining
+		}
+	}
+	return info, info.LimitRequests > 0 || info.LimitTokens > 0
+}
+
+// rateLimitHeadersFor picks the adapter for the provider at baseUrl.
+func rateLimitHeadersFor(baseUrl string) RateLimitHeaders {
+	u, err := url.Parse(baseUrl)
+	if err != nil {
+		return OpenAIRateLimitHeaders
+	}
+	switch host := u.Hostname(); {
+	case strings.HasSuffix(host, "together.xyz"), strings.HasSuffix(host, "together.ai"):
+		return TogetherRateLimitHeaders
+	case strings.HasSuffix(host, "fireworks.ai"):
+		return FireworksRateLimitHeaders
+	default:
+		return OpenAIRateLimitHeaders
+	}
+}
+
+func (o *OpenAI) rateLimitInfo(header http.Header) (RateLimitInfo, bool) {
+	if o.RateLimitHeaders == nil {
+		return OpenAIRateLimitHeaders(header)
+	}
+	return o.RateLimitHeaders(header)
+}
+
+// exhaustedReset returns how long until an exhausted limit resets, or zero
+// when no limit is exhausted.
+func (i RateLimitInfo) exhaustedReset() time.Duration {
+	var reset time.Duration
+	if i.LimitRequests > 0 && i.RemainingRequests <= 0 {
+		reset = max(reset, i.ResetRequests)
+	}
+	if i.LimitTokens > 0 && i.RemainingTokens <= 0 {
+		reset = max(reset, i.ResetTokens)
+	}
+	return reset
+}
+
+func headerInt(header http.Header, key string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(header.Get(key)))
+	return n
+}
+
+// headerDuration parses durations in Go form ("6m0s", "20ms") as sent by
+// OpenAI and Groq, or in plain seconds.
+func headerDuration(header http.Header, key string) time.Duration {
+	value := strings.TrimSpace(header.Get(key))
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	d, _ := time.ParseDuration(value)
+	return d
+}
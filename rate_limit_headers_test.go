@@ -0,0 +1,124 @@
+package openaiclient
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimitHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		adapter RateLimitHeaders
+		header  map[string]string
+		want    RateLimitInfo
+	}{
+		{
+			name:    "openai",
+			adapter: OpenAIRateLimitHeaders,
+			header: map[string]string{
+				"x-ratelimit-limit-requests":     "500",
+				"x-ratelimit-remaining-requests": "499",
+				"x-ratelimit-reset-requests":     "120ms",
+				"x-ratelimit-limit-tokens":       "30000",
+				"x-ratelimit-remaining-tokens":   "29900",
+				"x-ratelimit-reset-tokens":       "6m0s",
+			},
+			want: RateLimitInfo{500, 499, 120 * time.Millisecond, 30000, 29900, 6 * time.Minute},
+		},
+		{
+			name:    "groq",
+			adapter: OpenAIRateLimitHeaders,
+			header: map[string]string{
+				"x-ratelimit-limit-requests":     "14400",
+				"x-ratelimit-remaining-requests": "14370",
+				"x-ratelimit-reset-requests":     "2m59.56s",
+				"x-ratelimit-limit-tokens":       "18000",
+				"x-ratelimit-remaining-tokens":   "17997",
+				"x-ratelimit-reset-tokens":       "7.66s",
+			},
+			want: RateLimitInfo{14400, 14370, 2*time.Minute + 59560*time.Millisecond, 18000, 17997, 7660 * time.Millisecond},
+		},
+		{
+			name:    "together",
+			adapter: TogetherRateLimitHeaders,
+			header: map[string]string{
+				"x-ratelimit-limit":      "10",
+				"x-ratelimit-remaining":  "0",
+				"x-ratelimit-reset":      "1",
+				"x-tokenlimit-limit":     "200000",
+				"x-tokenlimit-remaining": "199000",
+			},
+			want: RateLimitInfo{LimitRequests: 10, ResetRequests: time.Second, LimitTokens: 200000, RemainingTokens: 199000},
+		},
+		{
+			name:    "fireworks",
+			adapter: FireworksRateLimitHeaders,
+			header: map[string]string{
+				"x-ratelimit-limit-requests":             "600",
+				"x-ratelimit-remaining-requests":         "599",
+				"x-ratelimit-limit-tokens-prompt":        "60000",
+				"x-ratelimit-remaining-tokens-prompt":    "59000",
+				"x-ratelimit-limit-tokens-generated":     "6000",
+				"x-ratelimit-remaining-tokens-generated": "100",
+			},
+			want: RateLimitInfo{LimitRequests: 600, RemainingRequests: 599, LimitTokens: 6000, RemainingTokens: 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range tt.header {
+				header.Set(key, value)
+			}
+
+			got, ok := tt.adapter(header)
+			if !ok || got != tt.want {
+				t.Errorf("got %+v (%v), want %+v", got, ok, tt.want)
+			}
+		})
+	}
+
+	if _, ok := OpenAIRateLimitHeaders(http.Header{}); ok {
+		t.Error("expected no rate limit info without headers")
+	}
+}
+
+func TestRateLimitHeadersFor(t *testing.T) {
+	fireworks := rateLimitHeadersFor("https://api.fireworks.ai/inference")
+	together := rateLimitHeadersFor("https://api.together.xyz")
+
+	header := http.Header{}
+	header.Set("x-ratelimit-limit", "10")
+	if _, ok := together(header); !ok {
+		t.Error("expected the Together adapter for api.together.xyz")
+	}
+	header.Set("x-ratelimit-limit-tokens-generated", "10")
+	if info, _ := fireworks(header); info.LimitTokens != 10 {
+		t.Error("expected the Fireworks adapter for api.fireworks.ai")
+	}
+	if info, _ := rateLimitHeadersFor("https://api.groq.com/openai")(header); info.LimitTokens != 0 {
+		t.Error("expected the OpenAI adapter for api.groq.com")
+	}
+}
+
+func TestReadResponse_ExhaustedLimits(t *testing.T) {
+	response := fakeResponse(http.StatusTooManyRequests, `{"error":{"message":"Rate limit reached","type":"rate_limit_error"}}`)
+	response.Header = http.Header{}
+	response.Header.Set("x-ratelimit-limit-tokens", "1000")
+	response.Header.Set("x-ratelimit-remaining-tokens", "0")
+	response.Header.Set("x-ratelimit-reset-tokens", "250ms")
+
+	client := createClient(t)
+	_, _, err := client.readResponse(response)
+
+	var apiErr *OpenAIError
+	if !errors.As(err, &apiErr) || apiErr.RateLimit == nil || apiErr.RateLimit.ResetTokens != 250*time.Millisecond {
+		t.Fatalf("expected the rate limit info on the error, got %v", err)
+	}
+	if delay := time.Until(client.pacer.until); delay <= 0 || delay > 250*time.Millisecond {
+		t.Errorf("expected requests to be paused until the limit resets, got %v", delay)
+	}
+}
@@ -0,0 +1,64 @@
+package openaiclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"strconv"
+	"unicode/utf8"
+)
+
+// RedactionPolicy decides how message contents appear in log records.
+type RedactionPolicy func(content string) slog.Value
+
+// LogFullContent logs contents verbatim.
+func LogFullContent(content string) slog.Value {
+	return slog.StringValue(content)
+}
+
+// LogContentHash logs only the length and SHA-256 hash of contents, which is
+// enough to tell responses apart without recording what they say.
+func LogContentHash(content string) slog.Value {
+	sum := sha256.Sum256([]byte(content))
+	return slog.GroupValue(
+		slog.Int("length", len(content)),
+		slog.String("sha256", hex.EncodeToString(sum[:])),
+	)
+}
+
+// TruncateLoggedContent logs at most the first n characters of contents.
+func TruncateLoggedContent(n int) RedactionPolicy {
+	return func(content string) slog.Value {
+		if utf8.RuneCountInString(content) <= n {
+			return slog.StringValue(content)
+		}
+		return slog.StringValue(string([]rune(content)[:n]) + "…")
+	}
+}
+
+// redactionFromEnv reads the policy from OPENAI_LOG_CONTENT, which is "full"
+// (the default), "hash" or the number of characters to truncate contents to.
+// Unrecognized values fall back to hashing, as the safest choice.
+func redactionFromEnv() RedactionPolicy {
+	switch value := os.Getenv("OPENAI_LOG_CONTENT"); value {
+	case "", "full":
+		return LogFullContent
+	case "hash":
+		return LogContentHash
+	default:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return LogContentHash
+		}
+		return TruncateLoggedContent(n)
+	}
+}
+
+func (o *OpenAI) contentAttr(content string) slog.Attr {
+	redact := o.LogRedaction
+	if redact == nil {
+		redact = LogFullContent
+	}
+	return slog.Attr{Key: "content", Value: redact(content)}
+}
@@ -0,0 +1,79 @@
+package openaiclient
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRedactionPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RedactionPolicy
+		want   string
+	}{
+		{name: "full", policy: LogFullContent, want: "Hello world"},
+		{name: "truncate", policy: TruncateLoggedContent(5), want: "Hello…"},
+		{name: "truncate shorter content", policy: TruncateLoggedContent(50), want: "Hello world"},
+		{name: "hash", policy: LogContentHash, want: "[length=11 sha256=64ec88ca00b268e5ba1a35678a1b5316d212f4f366b2477232534a8aeca37f3c]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy("Hello world").String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactionFromEnv(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: "Hello world"},
+		{value: "full", want: "Hello world"},
+		{value: "hash", want: LogContentHash("Hello world").String()},
+		{value: "3", want: "Hel…"},
+		{value: "bogus", want: LogContentHash("Hello world").String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("OPENAI_LOG_CONTENT", tt.value)
+			if got := redactionFromEnv()("Hello world").String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCompletion_RedactsLoggedContent(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer slog.SetDefault(defaultLogger)
+
+	client := createClient(t)
+	client.LogRedaction = LogContentHash
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+
+	payload := &CompletionRequestPayload{Model: "test-model", Messages: []Message{{Role: "user", Content: "Hi"}}}
+	if _, err := client.GetCompletion(payload); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if strings.Contains(logs.String(), "Hello world") {
+		t.Errorf("expected the content to be redacted, got %s", logs.String())
+	}
+	if !strings.Contains(logs.String(), "content.length=11") {
+		t.Errorf("expected the content length to be logged, got %s", logs.String())
+	}
+}
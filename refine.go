@@ -0,0 +1,131 @@
+package openaiclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultRefineRounds = 3
+
+	// refineApproved is the reply of the critique model to an answer that
+	// needs no revision.
+	refineApproved = "APPROVED"
+
+	critiqueInstructions = "You review an answer to a request. If the answer fully satisfies the request " +
+		"and the rubric, reply with " + refineApproved + " and nothing else. Otherwise list the problems to fix, " +
+		"without rewriting the answer."
+
+	reviseInstructions = "A review of your previous answer found these problems:\n\n%s\n\n" +
+		"Revise your answer to fix them. Reply with the revised answer only."
+)
+
+type (
+	RefinePayload struct {
+		// Payload is the request to answer. It is sent unchanged for the first
+		// answer, then with the answer and its critique appended for every
+		// revision.
+		Payload *CompletionRequestPayload
+		// Rubric is the criteria the critique model checks the answers
+		// against, in addition to the request itself.
+		Rubric string
+		// CritiqueModel reviews the answers. It defaults to the model of
+		// Payload.
+		CritiqueModel string
+		// Validate, when set, reviews the answers instead of the critique
+		// model. It returns an empty critique for an acceptable answer, e.g.
+		// when a schema or a test suite is a better judge than a model.
+		Validate func(ctx context.Context, answer string) (critique string, err error)
+		// MaxRounds is the maximum number of revisions, 3 by default.
+		MaxRounds int
+	}
+
+	RefineRound struct {
+		Answer *Message
+		// Critique is empty for the accepted answer.
+		Critique string
+	}
+
+	RefineResult struct {
+		// Message is the last answer, accepted or not.
+		Message *Message
+		Rounds  []RefineRound
+		// Accepted reports whether the last answer passed the review, rather
+		// than the rounds running out.
+		Accepted bool
+	}
+)
+
+// Refine runs the generate, critique and revise loop: it answers the payload,
+// has the answer reviewed and, until the review passes or MaxRounds revisions
+// were made, asks for a revision addressing the critique. Every answer,
+// including the last revision, is reviewed.
+func (o *OpenAI) Refine(ctx context.Context, payload *RefinePayload) (*RefineResult, error) {
+	maxRounds := payload.MaxRounds
+	if maxRounds <= 0 {
+		maxRounds = defaultRefineRounds
+	}
+
+	answer, err := o.GetCompletionContext(ctx, withModel(payload.Payload, payload.Payload.Model))
+	if err != nil {
+		return nil, err
+	}
+	result := &RefineResult{Message: answer}
+	for round := 0; ; round++ {
+		critique, err := o.critique(ctx, payload, answer.Content)
+		if err != nil {
+			return nil, fmt.Errorf("error critiquing answer: %w", err)
+		}
+		result.Rounds = append(result.Rounds, RefineRound{Answer: answer, Critique: critique})
+		if critique == "" {
+			result.Accepted = true
+			return result, nil
+		}
+		if round == maxRounds {
+			return result, nil
+		}
+
+		revision := withModel(payload.Payload, payload.Payload.Model)
+		revision.Messages = append(revision.Messages, *answer, Message{Role: MessageRoleUser, Content: fmt.Sprintf(reviseInstructions, critique)})
+		if answer, err = o.GetCompletionContext(ctx, revision); err != nil {
+			return nil, err
+		}
+		result.Message = answer
+	}
+}
+
+// critique returns the problems found in the answer, or an empty string when
+// it is acceptable.
+func (o *OpenAI) critique(ctx context.Context, payload *RefinePayload, answer string) (string, error) {
+	if payload.Validate != nil {
+		critique, err := payload.Validate(ctx, answer)
+		return strings.TrimSpace(critique), err
+	}
+
+	model := payload.CritiqueModel
+	if model == "" {
+		model = payload.Payload.Model
+	}
+	var review strings.Builder
+	fmt.Fprintf(&review, "Request:\n%s\n\nAnswer:\n%s\n", lastUserContent(payload.Payload.Messages), answer)
+	if payload.Rubric != "" {
+		fmt.Fprintf(&review, "\nRubric:\n%s\n", payload.Rubric)
+	}
+	message, err := o.GetCompletionContext(ctx, &CompletionRequestPayload{
+		Model: model,
+		Messages: []Message{
+			{Role: MessageRoleSystem, Content: critiqueInstructions},
+			{Role: MessageRoleUser, Content: review.String()},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	critique := strings.TrimSpace(message.Content)
+	if strings.EqualFold(strings.Trim(critique, ".! "), refineApproved) {
+		return "", nil
+	}
+	return critique, nil
+}
@@ -0,0 +1,86 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRefine_RevisesUntilApproved(t *testing.T) {
+	var requests []CompletionRequestPayload
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			var payload CompletionRequestPayload
+			body, _ := io.ReadAll(req.Body)
+			json.Unmarshal(body, &payload)
+			requests = append(requests, payload)
+
+			content := "draft"
+			switch {
+			case payload.Model == "critic-model" && strings.Contains(payload.Messages[1].Content, "Answer:\nrevised"):
+				content = "APPROVED."
+			case payload.Model == "critic-model":
+				content = "Too vague."
+			case len(payload.Messages) > 1:
+				content = "revised"
+			}
+			return fakeResponse(http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":`+strconv.Quote(content)+`}}]}`), nil
+		},
+	}
+
+	payload := &CompletionRequestPayload{Model: "test-model", Messages: []Message{{Role: MessageRoleUser, Content: "Summarize"}}}
+	result, err := client.Refine(context.Background(), &RefinePayload{
+		Payload:       payload,
+		Rubric:        "Be specific.",
+		CritiqueModel: "critic-model",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !result.Accepted || result.Message.Content != "revised" || len(result.Rounds) != 2 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if result.Rounds[0].Critique != "Too vague." || result.Rounds[1].Critique != "" {
+		t.Errorf("unexpected rounds %+v", result.Rounds)
+	}
+	if len(requests) != 4 || !strings.Contains(requests[1].Messages[1].Content, "Be specific.") {
+		t.Fatalf("expected the rubric in the critique, got %+v", requests)
+	}
+	if revision := requests[2].Messages; len(revision) != 3 || !strings.Contains(revision[2].Content, "Too vague.") {
+		t.Errorf("expected the critique appended to the revision, got %+v", revision)
+	}
+	if len(payload.Messages) != 1 {
+		t.Errorf("expected the caller's payload to be left untouched, got %+v", payload.Messages)
+	}
+}
+
+func TestRefine_StopsAfterMaxRounds(t *testing.T) {
+	var requests []CompletionRequestPayload
+	client := newDraftVerifyClient(t, &requests)
+
+	validations := 0
+	result, err := client.Refine(context.Background(), &RefinePayload{
+		Payload: &CompletionRequestPayload{Model: "test-model", Messages: []Message{{Role: MessageRoleUser, Content: "Write JSON"}}},
+		Validate: func(ctx context.Context, answer string) (string, error) {
+			validations++
+			return "invalid JSON", nil
+		},
+		MaxRounds: 2,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result.Accepted || len(result.Rounds) != 3 || validations != 3 {
+		t.Errorf("expected two revisions, all rejected, got %+v", result)
+	}
+	if len(requests) != 3 {
+		t.Errorf("expected only completions of the answers, got %d requests", len(requests))
+	}
+}
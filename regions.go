@@ -0,0 +1,197 @@
+package openaiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultProbeInterval = time.Minute
+	probeTimeout         = 10 * time.Second
+
+	// maxStickyConversations bounds the conversations whose region is
+	// remembered. The assignments are forgotten all at once past it, which
+	// only costs a prompt cache miss.
+	maxStickyConversations = 10000
+)
+
+type (
+	// Regions sends the requests of a client to the fastest healthy of the
+	// regional endpoints of a provider, as measured by periodic probes. The
+	// requests of a conversation, see WithConversationId, stick to the region
+	// first picked for it while it stays healthy, to keep hitting the same
+	// prompt cache. It is safe for concurrent use.
+	Regions struct {
+		// BaseURLs are the base URLs of the regional endpoints, in order of
+		// preference until they are probed.
+		BaseURLs []string
+		// Interval is the time between the probes of Start, a minute by
+		// default.
+		Interval time.Duration
+
+		mu     sync.Mutex
+		probes map[string]RegionProbe
+		sticky map[string]string
+	}
+
+	RegionProbe struct {
+		BaseURL string
+		Latency time.Duration
+		Healthy bool
+		Err     error
+		At      time.Time
+	}
+)
+
+// Start probes the regions with the client's credentials now and then every
+// Interval, in the background, until ctx is done.
+func (r *Regions) Start(ctx context.Context, client *OpenAI) {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = defaultProbeInterval
+	}
+	r.Probe(ctx, client)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.Probe(ctx, client)
+			}
+		}
+	}()
+}
+
+// Probe measures the latency of a models listing on every region,
+// concurrently, and returns the results.
+func (r *Regions) Probe(ctx context.Context, client *OpenAI) []RegionProbe {
+	probes := make([]RegionProbe, len(r.BaseURLs))
+	var wg sync.WaitGroup
+	for i, baseUrl := range r.BaseURLs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			probes[i] = probeRegion(ctx, client, baseUrl)
+		}()
+	}
+	wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.probes == nil {
+		r.probes = map[string]RegionProbe{}
+	}
+	for _, probe := range probes {
+		r.probes[probe.BaseURL] = probe
+	}
+	return probes
+}
+
+func probeRegion(ctx context.Context, client *OpenAI, baseUrl string) RegionProbe {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	probe := RegionProbe{BaseURL: baseUrl, At: time.Now()}
+	request, err := createAuthorizedRequest(http.MethodGet, baseUrl+modelsEndpoint, nil, client.keyFor(modelsEndpoint))
+	if err != nil {
+		probe.Err = err
+		return probe
+	}
+	response, err := client.client.Do(request.WithContext(ctx))
+	probe.Latency = time.Since(probe.At)
+	if err != nil {
+		probe.Err = err
+		return probe
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(response.Body)
+		probe.Err = NewOpenAIError(response.StatusCode, body)
+		return probe
+	}
+	probe.Healthy = true
+	return probe
+}
+
+// Probes returns the latest probe of every region probed so far.
+func (r *Regions) Probes() []RegionProbe {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	probes := make([]RegionProbe, 0, len(r.probes))
+	for _, baseUrl := range r.BaseURLs {
+		if probe, ok := r.probes[baseUrl]; ok {
+			probes = append(probes, probe)
+		}
+	}
+	return probes
+}
+
+// baseUrl returns the base URL of the region to send a request with ctx to,
+// or an empty string when there are no regions.
+func (r *Regions) baseUrl(ctx context.Context) string {
+	if r == nil || len(r.BaseURLs) == 0 {
+		return ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	conversation := ConversationId(ctx)
+	if region, ok := r.sticky[conversation]; ok && r.healthy(region) {
+		return region
+	}
+	region := r.fastest()
+	if conversation != "" {
+		if r.sticky == nil || len(r.sticky) >= maxStickyConversations {
+			r.sticky = map[string]string{}
+		}
+		r.sticky[conversation] = region
+	}
+	return region
+}
+
+// healthy reports whether the region passed its last probe, if any.
+func (r *Regions) healthy(baseUrl string) bool {
+	probe, ok := r.probes[baseUrl]
+	return !ok || probe.Healthy
+}
+
+// fastest returns the healthy region of the lowest latency, falling back to
+// the first region not known to be unhealthy, and to the first region.
+func (r *Regions) fastest() string {
+	fastest := ""
+	for _, baseUrl := range r.BaseURLs {
+		probe, ok := r.probes[baseUrl]
+		if ok && probe.Healthy && (fastest == "" || probe.Latency < r.probes[fastest].Latency) {
+			fastest = baseUrl
+		}
+	}
+	if fastest != "" {
+		return fastest
+	}
+	if i := slices.IndexFunc(r.BaseURLs, r.healthy); i >= 0 {
+		return r.BaseURLs[i]
+	}
+	return r.BaseURLs[0]
+}
+
+// trimBaseUrl returns the endpoint of a request URL sent to one of the
+// regions, and ok false for other URLs.
+func (r *Regions) trimBaseUrl(rawUrl string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	for _, baseUrl := range r.BaseURLs {
+		if endpoint, ok := strings.CutPrefix(rawUrl, baseUrl); ok {
+			return endpoint, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,86 @@
+package openaiclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegions(t *testing.T) {
+	var mu sync.Mutex
+	delays := map[string]time.Duration{"us.example.com": 20 * time.Millisecond, "eu.example.com": 0}
+	down := map[string]bool{"ap.example.com": true}
+	var completions []string
+
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			if req.URL.Path == completionsEndpont {
+				completions = append(completions, req.URL.Host)
+				mu.Unlock()
+				return fakeResponse(http.StatusOK, completionBody), nil
+			}
+			delay, unhealthy := delays[req.URL.Host], down[req.URL.Host]
+			mu.Unlock()
+
+			time.Sleep(delay)
+			if unhealthy {
+				return fakeResponse(http.StatusServiceUnavailable, `{"error":{"message":"down"}}`), nil
+			}
+			return fakeResponse(http.StatusOK, `{"data":[]}`), nil
+		},
+	}
+	client.Regions = &Regions{BaseURLs: []string{"https://ap.example.com", "https://us.example.com", "https://eu.example.com"}}
+
+	probes := client.Regions.Probe(context.Background(), client)
+	if len(probes) != 3 || probes[0].Healthy || probes[0].Err == nil || !probes[2].Healthy {
+		t.Fatalf("unexpected probes %+v", probes)
+	}
+
+	complete := func(ctx context.Context) {
+		t.Helper()
+		payload := &CompletionRequestPayload{Model: "test-model", Messages: []Message{{Role: MessageRoleUser, Content: "Hi"}}}
+		if _, err := client.GetCompletionContext(ctx, payload); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	conversation := WithConversationId(context.Background(), "conversation-1")
+	complete(conversation)
+
+	// The conversation sticks to its region while it stays healthy.
+	mu.Lock()
+	delays["us.example.com"], delays["eu.example.com"] = 0, 20*time.Millisecond
+	mu.Unlock()
+	client.Regions.Probe(context.Background(), client)
+	complete(conversation)
+	complete(context.Background())
+
+	mu.Lock()
+	down["eu.example.com"] = true
+	mu.Unlock()
+	client.Regions.Probe(context.Background(), client)
+	complete(conversation)
+
+	want := []string{"eu.example.com", "eu.example.com", "us.example.com", "us.example.com"}
+	if len(completions) != len(want) {
+		t.Fatalf("expected %v, got %v", want, completions)
+	}
+	for i := range want {
+		if completions[i] != want[i] {
+			t.Errorf("request %d: expected %s, got %s", i, want[i], completions[i])
+		}
+	}
+}
+
+func TestRegions_Unprobed(t *testing.T) {
+	regions := &Regions{BaseURLs: []string{"https://a.example.com", "https://b.example.com"}}
+	if baseUrl := regions.baseUrl(context.Background()); baseUrl != "https://a.example.com" {
+		t.Errorf("expected the first region before any probe, got %s", baseUrl)
+	}
+	if baseUrl := (*Regions)(nil).baseUrl(context.Background()); baseUrl != "" {
+		t.Errorf("expected no region, got %s", baseUrl)
+	}
+}
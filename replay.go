@@ -0,0 +1,228 @@
+package openaiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+)
+
+// ErrStopReplay can be returned by Replayer.Step to stop a replay at an
+// iteration, like a breakpoint.
+var ErrStopReplay = errors.New("replay stopped")
+
+type (
+	// ReplayStep is an iteration of a replayed run, passed to Replayer.Step
+	// once its response is known.
+	ReplayStep struct {
+		Iteration int
+		// Messages is the conversation sent in the iteration.
+		Messages []Message
+		Response *Message
+		// Live reports whether the response came from Replayer.Live rather
+		// than the recording.
+		Live bool
+	}
+
+	// Replayer re-executes a recorded run through the ReAct loop, answering
+	// the requests with the recorded responses and the tool calls with the
+	// recorded results, so every iteration can be inspected and the effect
+	// of a different tool output explored.
+	Replayer struct {
+		Trace *RunTrace
+		// ToolOutputs replaces the recorded results of the named tools.
+		ToolOutputs map[string]string
+		// Live, when set, answers the iterations that follow a replaced tool
+		// output instead of the recording, showing how the run diverges. It
+		// is sent the definitions in Tools, whose functions also run the tool
+		// calls the recording has no result left for.
+		Live  *OpenAI
+		Tools []ToolDefinition
+		// Step is called after every iteration. Returning an error, such as
+		// ErrStopReplay, stops the replay with that error.
+		Step func(ReplayStep) error
+
+		iteration   int
+		diverged    bool
+		generations []TraceObservation
+		results     map[string][]string
+	}
+)
+
+// NewReplayer returns a Replayer of the trace, e.g. one of BuildTraces.
+func NewReplayer(trace *RunTrace) *Replayer {
+	return &Replayer{Trace: trace}
+}
+
+// Run replays the run from its first request and returns the final message,
+// which differs from the recorded one only when Live answered part of it.
+func (r *Replayer) Run(ctx context.Context) (*Message, error) {
+	r.iteration, r.diverged = 0, false
+	r.generations, r.results = nil, map[string][]string{}
+	for _, observation := range r.Trace.Observations {
+		switch observation.Kind {
+		case ObservationGeneration:
+			r.generations = append(r.generations, observation)
+		case ObservationTool:
+			result, _ := observation.Output.(string)
+			r.results[observation.Name] = append(r.results[observation.Name], result)
+		}
+	}
+	if len(r.generations) == 0 {
+		return nil, NewInvalidRequestError("the trace has no recorded generations")
+	}
+
+	maxIterations := len(r.generations)
+	if r.Live != nil {
+		maxIterations = max(maxIterations, r.Live.MaxIterations)
+	}
+	client := &OpenAI{
+		baseUrl:       "http://replay",
+		client:        replayClient{r},
+		key:           "replay",
+		MaxIterations: maxIterations,
+	}
+	return client.GetCompletionContext(ctx, &CompletionRequestPayload{
+		Model:    r.generations[0].Model,
+		Messages: slices.Clone(r.Trace.Input),
+		Tools:    r.replayTools(),
+	})
+}
+
+// replayClient answers the completion requests of a replay.
+type replayClient struct{ *Replayer }
+
+func (r replayClient) Do(request *http.Request) (*http.Response, error) {
+	var sent CompletionRequestPayload
+	if err := json.NewDecoder(request.Body).Decode(&sent); err != nil {
+		return nil, fmt.Errorf("error decoding replayed request: %w", err)
+	}
+
+	iteration := r.iteration
+	r.iteration++
+	responseText, live, err := r.respond(request.Context(), iteration, &sent)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Step != nil {
+		var response CompletionResponse
+		if err := json.Unmarshal(responseText, &response); err != nil {
+			return nil, fmt.Errorf("error unmarshaling response body: %w", err)
+		}
+		step := ReplayStep{Iteration: iteration, Messages: sent.Messages, Live: live}
+		if len(response.Choices) > 0 {
+			step.Response = response.Choices[0].Message
+		}
+		if err := r.Step(step); err != nil {
+			return nil, err
+		}
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader(responseText)),
+	}, nil
+}
+
+func (r *Replayer) respond(ctx context.Context, iteration int, sent *CompletionRequestPayload) ([]byte, bool, error) {
+	if r.diverged && r.Live != nil {
+		responseText, err := r.Live.postCompletion(ctx, &CompletionRequestPayload{
+			Model:    sent.Model,
+			Messages: sent.Messages,
+			Tools:    r.Tools,
+		})
+		return responseText, true, err
+	}
+
+	if iteration >= len(r.generations) {
+		return nil, false, NewInvalidRequestError("the recorded run has no more responses")
+	}
+	generation := r.generations[iteration]
+	if generation.Error != "" {
+		return nil, false, fmt.Errorf("recorded error: %s", generation.Error)
+	}
+
+	message, err := recordedMessage(generation.Output)
+	if err != nil {
+		return nil, false, err
+	}
+	responseText, err := json.Marshal(CompletionResponse{
+		Model:   generation.Model,
+		Choices: []LLMChoice{{Message: message}},
+		Usage:   generation.Usage,
+	})
+	return responseText, false, err
+}
+
+// replayTools stands in for the tools of the recorded run and of Tools.
+func (r *Replayer) replayTools() []ToolDefinition {
+	var names []string
+	definitions := map[string]*FunctionDefinition{}
+	for _, tool := range r.Tools {
+		names = append(names, tool.Function.Name)
+		definitions[tool.Function.Name] = tool.Function
+	}
+	for name := range r.results {
+		if definitions[name] == nil {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	tools := make([]ToolDefinition, 0, len(names))
+	for _, name := range names {
+		definition := definitions[name]
+		stub := &FunctionDefinition{Name: name}
+		if definition != nil {
+			stub.Description, stub.Parameters = definition.Description, definition.Parameters
+		}
+		// The recorded results are replayed as they are, errors included.
+		stub.FnContext = func(ctx context.Context, arguments string) (string, error) {
+			return r.runTool(ctx, name, definition, arguments), nil
+		}
+		tools = append(tools, NewToolDefinition(stub))
+	}
+	return tools
+}
+
+func (r *Replayer) runTool(ctx context.Context, name string, definition *FunctionDefinition, arguments string) string {
+	recorded, ok := "", len(r.results[name]) > 0
+	if ok {
+		recorded, r.results[name] = r.results[name][0], r.results[name][1:]
+	}
+
+	if output, replaced := r.ToolOutputs[name]; replaced {
+		r.diverged = r.diverged || !ok || output != recorded
+		return output
+	}
+	if ok {
+		return recorded
+	}
+	if definition != nil && definition.runnable() {
+		return definition.call(ctx, arguments)
+	}
+	return toolError(fmt.Errorf("no recorded result for tool %s", name))
+}
+
+// recordedMessage returns the message of a generation, which is decoded from
+// JSON when the trace itself was.
+func recordedMessage(output any) (*Message, error) {
+	if message, ok := output.(*Message); ok && message != nil {
+		return message, nil
+	}
+	encoded, err := json.Marshal(output)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding recorded message: %w", err)
+	}
+	var message Message
+	if err := json.Unmarshal(encoded, &message); err != nil {
+		return nil, fmt.Errorf("error decoding recorded message: %w", err)
+	}
+	return &message, nil
+}
@@ -0,0 +1,86 @@
+package openaiclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func recordedTrace(t *testing.T) *RunTrace {
+	t.Helper()
+	var buffer bytes.Buffer
+	recordRun(t, context.Background(), createClient(t), &buffer)
+	events, err := ReadRunLog(&buffer)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return BuildTraces(events)[0]
+}
+
+func TestReplayer_Run(t *testing.T) {
+	var steps []ReplayStep
+	replayer := NewReplayer(recordedTrace(t))
+	replayer.Step = func(step ReplayStep) error {
+		steps = append(steps, step)
+		return nil
+	}
+
+	message, err := replayer.Run(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if message.Content != "done" {
+		t.Errorf("expected the recorded answer, got %q", message.Content)
+	}
+	if len(steps) != 2 || steps[0].Live || len(steps[0].Response.ToolCalls) != 1 {
+		t.Fatalf("expected the two recorded iterations, got %+v", steps)
+	}
+	if last := steps[1].Messages[len(steps[1].Messages)-1]; last.Role != MessageRoleTool || last.Content != "{}" {
+		t.Errorf("expected the recorded tool result in the second iteration, got %+v", last)
+	}
+}
+
+func TestReplayer_StopsAtStep(t *testing.T) {
+	replayer := NewReplayer(recordedTrace(t))
+	replayer.Step = func(step ReplayStep) error {
+		return ErrStopReplay
+	}
+
+	if _, err := replayer.Run(context.Background()); !errors.Is(err, ErrStopReplay) {
+		t.Errorf("expected ErrStopReplay, got %v", err)
+	}
+}
+
+func TestReplayer_DivergesOnToolOutput(t *testing.T) {
+	live := createClient(t)
+	live.client = &SequentialFakeClient{
+		Responses: []*http.Response{
+			fakeResponse(http.StatusOK, `{"model":"test-model","choices":[{"message":{"role":"assistant","content":"changed"}}]}`),
+		},
+	}
+
+	var steps []ReplayStep
+	replayer := NewReplayer(recordedTrace(t))
+	replayer.ToolOutputs = map[string]string{"echo": `{"text":"other"}`}
+	replayer.Live = live
+	replayer.Step = func(step ReplayStep) error {
+		steps = append(steps, step)
+		return nil
+	}
+
+	message, err := replayer.Run(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if message.Content != "changed" {
+		t.Errorf("expected the live answer, got %q", message.Content)
+	}
+	if len(steps) != 2 || steps[0].Live || !steps[1].Live {
+		t.Fatalf("expected a recorded then a live iteration, got %+v", steps)
+	}
+	if last := steps[1].Messages[len(steps[1].Messages)-1]; last.Content != `{"text":"other"}` {
+		t.Errorf("expected the replaced tool output, got %+v", last)
+	}
+}
@@ -0,0 +1,168 @@
+package openaiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	defaultReportChunkSize = 8000
+
+	reportMapInstructions = "You extract a report from a part of a longer text. Reply with a JSON object " +
+		"of this schema:\n\n%s\n\nOnly include the fields the text supports and leave the others out."
+
+	reportReduceInstructions = "You merge partial reports, each extracted from a numbered part of a longer " +
+		"text, into a single report. Reply with a JSON object with two properties: \"report\", the merged " +
+		"report, of this schema:\n\n%s\n\nand \"sources\", an object mapping every field of the report you " +
+		"filled to the numbers of the parts it was drawn from. Resolve conflicts between parts in favor of " +
+		"the most specific information."
+)
+
+type (
+	ReportPayload struct {
+		Model string
+		// Instructions describe the report to the model, e.g. its focus and
+		// audience. The schema of the report is always described.
+		Instructions string
+		// Sources are split into chunks of ChunkSize bytes, 8000 by default,
+		// that are extracted from one at a time.
+		Sources   []Document
+		ChunkSize int
+	}
+
+	// Report is a report of type T generated by GenerateReport.
+	Report[T any] struct {
+		Value T
+		// Provenance maps the top-level JSON fields of the report to the ids
+		// of the chunks they were drawn from.
+		Provenance map[string][]string
+	}
+)
+
+// GenerateReport fills a report of type T from the sources: every chunk of
+// the sources is extracted into a partial report in JSON mode, and the
+// partials that found anything are merged into one by the model, which also
+// reports which chunks each field comes from. T is described to the model
+// the way ToolFromFunc describes tool parameters.
+func GenerateReport[T any](ctx context.Context, client *OpenAI, payload *ReportPayload) (*Report[T], error) {
+	chunkSize := payload.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultReportChunkSize
+	}
+	var chunks []Chunk
+	for _, source := range payload.Sources {
+		chunks = append(chunks, source.Chunks(chunkSize, 0)...)
+	}
+	if len(chunks) == 0 {
+		return nil, NewInvalidRequestError("the report has no sources")
+	}
+
+	schema, err := json.Marshal(jsonSchemaFor(reflect.TypeFor[T]()))
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling report schema: %w", err)
+	}
+
+	type partial struct {
+		chunk  Chunk
+		report json.RawMessage
+		fields []string
+	}
+	var partials []partial
+	for _, chunk := range chunks {
+		report, err := client.completeReport(ctx, payload, fmt.Sprintf(reportMapInstructions, schema), chunk.Text)
+		if err != nil {
+			return nil, fmt.Errorf("error extracting report from %s: %w", chunk.Id, err)
+		}
+		if fields := filledFields(report); len(fields) > 0 {
+			partials = append(partials, partial{chunk, report, fields})
+		}
+	}
+
+	result := &Report[T]{Provenance: map[string][]string{}}
+	switch len(partials) {
+	case 0:
+		return result, nil
+	case 1:
+		if err := json.Unmarshal(partials[0].report, &result.Value); err != nil {
+			return nil, fmt.Errorf("error unmarshaling report: %w", err)
+		}
+		for _, field := range partials[0].fields {
+			result.Provenance[field] = []string{partials[0].chunk.Id}
+		}
+		return result, nil
+	}
+
+	var parts strings.Builder
+	for i, partial := range partials {
+		fmt.Fprintf(&parts, "[%d] %s\n", i+1, partial.report)
+	}
+	merged, err := client.completeReport(ctx, payload, fmt.Sprintf(reportReduceInstructions, schema), parts.String())
+	if err != nil {
+		return nil, fmt.Errorf("error merging reports: %w", err)
+	}
+	var reduced struct {
+		Report  json.RawMessage  `json:"report"`
+		Sources map[string][]int `json:"sources"`
+	}
+	if err := json.Unmarshal(merged, &reduced); err != nil {
+		return nil, fmt.Errorf("error unmarshaling merged report: %w", err)
+	}
+	if err := json.Unmarshal(reduced.Report, &result.Value); err != nil {
+		return nil, fmt.Errorf("error unmarshaling report: %w", err)
+	}
+	for field, numbers := range reduced.Sources {
+		for _, number := range numbers {
+			if number >= 1 && number <= len(partials) {
+				result.Provenance[field] = append(result.Provenance[field], partials[number-1].chunk.Id)
+			}
+		}
+	}
+	return result, nil
+}
+
+// completeReport answers the content in JSON mode and returns the JSON object
+// of the reply.
+func (o *OpenAI) completeReport(ctx context.Context, payload *ReportPayload, instructions, content string) (json.RawMessage, error) {
+	if payload.Instructions != "" {
+		instructions += "\n\n" + payload.Instructions
+	}
+	message, err := o.GetCompletionContext(ctx, &CompletionRequestPayload{
+		Model: payload.Model,
+		Messages: []Message{
+			{Role: MessageRoleSystem, Content: instructions},
+			{Role: MessageRoleUser, Content: content},
+		},
+		ExtraBody: map[string]any{"response_format": map[string]string{"type": "json_object"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var object json.RawMessage
+	if err := json.Unmarshal([]byte(unfenceJSON(message.Content)), &object); err != nil {
+		return nil, fmt.Errorf("error unmarshaling reply: %w", err)
+	}
+	return object, nil
+}
+
+// filledFields returns the fields of a JSON object that are neither null nor
+// empty.
+func filledFields(object json.RawMessage) []string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(object, &fields); err != nil {
+		return nil
+	}
+	var filled []string
+	for name, value := range fields {
+		switch string(bytes.TrimSpace(value)) {
+		case "null", `""`, "[]", "{}":
+		default:
+			filled = append(filled, name)
+		}
+	}
+	return filled
+}
@@ -0,0 +1,82 @@
+package openaiclient
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type incidentReport struct {
+	Summary  string   `json:"summary"`
+	Services []string `json:"services,omitempty"`
+	Duration string   `json:"duration,omitempty"`
+}
+
+func reportSources(t *testing.T, texts ...string) []Document {
+	t.Helper()
+	var sources []Document
+	for i, text := range texts {
+		document, err := LoadText(strings.NewReader(text), "log"+string(rune('a'+i)))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		sources = append(sources, *document)
+	}
+	return sources
+}
+
+func TestGenerateReport(t *testing.T) {
+	client := NewTestClient(
+		ScriptedTurn{Content: `{"summary":"Checkout failed","services":["payments"]}`},
+		ScriptedTurn{Content: "```json\n{}\n```"},
+		ScriptedTurn{Content: `{"summary":"Checkout was down","duration":"40m"}`},
+		ScriptedTurn{Content: `{"report":{"summary":"Checkout was down for 40m","services":["payments"],"duration":"40m"},"sources":{"summary":[1,2],"services":[1],"duration":[2,7]}}`},
+	)
+
+	report, err := GenerateReport[incidentReport](context.Background(), client.OpenAI, &ReportPayload{
+		Model:        "gpt-4o-mini",
+		Instructions: "Write for the on-call engineers.",
+		Sources:      reportSources(t, "payments errors", "unrelated", "recovered after 40 minutes"),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if report.Value.Summary != "Checkout was down for 40m" || report.Value.Duration != "40m" {
+		t.Errorf("expected the merged report, got %+v", report.Value)
+	}
+	if !slices.Equal(report.Provenance["summary"], []string{"loga#0", "logc#0"}) || !slices.Equal(report.Provenance["duration"], []string{"logc#0"}) {
+		t.Errorf("expected the chunk ids of the merged parts, got %v", report.Provenance)
+	}
+
+	sent := client.Completions()
+	if !strings.Contains(sent[0].Messages[0].Content, `"services"`) || !strings.HasSuffix(sent[0].Messages[0].Content, "on-call engineers.") {
+		t.Errorf("expected the schema and instructions in the prompt, got %q", sent[0].Messages[0].Content)
+	}
+	if merge := sent[3].Messages[1].Content; strings.Count(merge, "\n") != 2 || !strings.HasPrefix(merge, "[1] ") {
+		t.Errorf("expected only the two filled partials to be merged, got %q", merge)
+	}
+}
+
+func TestGenerateReport_SinglePartial(t *testing.T) {
+	client := NewTestClient(ScriptedTurn{Content: `{"summary":"Checkout failed","services":[]}`})
+
+	report, err := GenerateReport[incidentReport](context.Background(), client.OpenAI, &ReportPayload{
+		Sources: reportSources(t, "payments errors"),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if report.Value.Summary != "Checkout failed" || len(report.Provenance) != 1 || report.Provenance["summary"][0] != "loga#0" {
+		t.Errorf("expected the partial as the report, got %+v", report)
+	}
+	if client.Remaining() != 0 || len(client.Completions()) != 1 {
+		t.Error("expected no merge request")
+	}
+}
+
+func TestGenerateReport_NoSources(t *testing.T) {
+	if _, err := GenerateReport[incidentReport](context.Background(), NewTestClient().OpenAI, &ReportPayload{}); GetOpenAIErrorType(err) != ErrTypeInvalidRequest {
+		t.Errorf("expected an invalid request error, got %v", err)
+	}
+}
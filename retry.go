@@ -0,0 +1,65 @@
+package openaiclient
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+func shouldRetry(request *http.Request, statusCode int) bool {
+	if request.Context().Err() != nil {
+		return false
+	}
+	switch {
+	case statusCode == 0:
+		return true
+	case statusCode == http.StatusRequestTimeout,
+		statusCode == http.StatusConflict,
+		statusCode == http.StatusTooManyRequests:
+		return true
+	default:
+		return statusCode >= http.StatusInternalServerError
+	}
+}
+
+func (o *OpenAI) backoff(attempt int) time.Duration {
+	return o.RetryBackoff << attempt
+}
+
+// rewindRequest returns a copy of the request with a fresh body so it can be
+// sent again.
+func rewindRequest(request *http.Request) (*http.Request, error) {
+	retry := request.Clone(request.Context())
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	return retry, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,146 @@
+package openaiclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+const completionBody = `{"choices":[{"index":0,"message":{"role":"assistant","content":"Hello world"}}]}`
+
+func TestDoRequest_RetriesEmbeddings(t *testing.T) {
+	seqClient := &SequentialFakeClient{
+		Responses: []*http.Response{
+			fakeResponse(http.StatusInternalServerError, `{"message":"boom"}`),
+			fakeResponse(http.StatusOK, `{"data":[{"embedding":[0.1]}]}`),
+		},
+	}
+
+	client := createClient(t)
+	client.client = seqClient
+
+	if _, err := client.GetEmbedding(GetEmbeddingPayload{Model: "test-model", Input: "Hi"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if seqClient.CallCount != 2 {
+		t.Errorf("expected 2 calls, got %d", seqClient.CallCount)
+	}
+}
+
+func TestDoRequest_DoesNotRetryClientErrors(t *testing.T) {
+	seqClient := &SequentialFakeClient{
+		Responses: []*http.Response{
+			fakeResponse(http.StatusBadRequest, `{"message":"bad"}`),
+			fakeResponse(http.StatusOK, `{"data":[{"embedding":[0.1]}]}`),
+		},
+	}
+
+	client := createClient(t)
+	client.client = seqClient
+
+	if _, err := client.GetEmbedding(GetEmbeddingPayload{Model: "test-model", Input: "Hi"}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if seqClient.CallCount != 1 {
+		t.Errorf("expected 1 call, got %d", seqClient.CallCount)
+	}
+}
+
+func TestDoRequest_CompletionIdempotencyKey(t *testing.T) {
+	var keys []string
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			keys = append(keys, req.Header.Get(idempotencyKeyHeader))
+			if len(keys) == 1 {
+				return fakeResponse(http.StatusServiceUnavailable, `{"message":"unavailable"}`), nil
+			}
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	payload := &CompletionRequestPayload{
+		Model:    "test-model",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	}
+	if _, err := client.GetCompletion(payload); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 calls, got %d", len(keys))
+	}
+	if keys[0] == "" {
+		t.Errorf("expected idempotency key to be set")
+	}
+	if keys[0] != keys[1] {
+		t.Errorf("expected retries to reuse idempotency key, got %q and %q", keys[0], keys[1])
+	}
+}
+
+func TestDoRequest_StoredCompletionNotRetried(t *testing.T) {
+	store := true
+	newPayload := func() *CompletionRequestPayload {
+		return &CompletionRequestPayload{
+			Model:    "test-model",
+			Messages: []Message{{Role: "user", Content: "Hi"}},
+			Store:    &store,
+		}
+	}
+
+	seqClient := &SequentialFakeClient{
+		Responses: []*http.Response{
+			fakeResponse(http.StatusInternalServerError, `{"message":"boom"}`),
+			fakeResponse(http.StatusOK, completionBody),
+		},
+	}
+
+	client := createClient(t)
+	client.client = seqClient
+
+	if _, err := client.GetCompletion(newPayload()); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if seqClient.CallCount != 1 {
+		t.Errorf("expected 1 call, got %d", seqClient.CallCount)
+	}
+
+	seqClient = &SequentialFakeClient{
+		Responses: []*http.Response{
+			fakeResponse(http.StatusInternalServerError, `{"message":"boom"}`),
+			fakeResponse(http.StatusOK, completionBody),
+		},
+	}
+	client.client = seqClient
+	client.RetryUnsafe = true
+
+	if _, err := client.GetCompletion(newPayload()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if seqClient.CallCount != 2 {
+		t.Errorf("expected 2 calls, got %d", seqClient.CallCount)
+	}
+}
+
+func TestDoRequest_CanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	seqClient := &SequentialFakeClient{
+		Responses: []*http.Response{
+			fakeResponse(http.StatusInternalServerError, `{"message":"boom"}`),
+			fakeResponse(http.StatusOK, `{"data":[{"embedding":[0.1]}]}`),
+		},
+	}
+
+	client := createClient(t)
+	client.client = seqClient
+	cancel()
+
+	if _, err := client.GetEmbeddingContext(ctx, GetEmbeddingPayload{Model: "test-model", Input: "Hi"}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if seqClient.CallCount != 1 {
+		t.Errorf("expected 1 call, got %d", seqClient.CallCount)
+	}
+}
@@ -0,0 +1,67 @@
+package openaiclient
+
+import (
+	"context"
+	"fmt"
+	"maps"
+)
+
+type TaskHint string
+
+const (
+	TaskFast        TaskHint = "fast"
+	TaskReasoning   TaskHint = "reasoning"
+	TaskVision      TaskHint = "vision"
+	TaskLongContext TaskHint = "long-context"
+)
+
+type (
+	// Route is the model, and optionally the extra request parameters, used
+	// for a kind of task.
+	Route struct {
+		Model     string
+		ExtraBody map[string]any
+	}
+
+	// Router maps task hints to the routes serving them.
+	Router map[TaskHint]Route
+
+	taskKey struct{}
+)
+
+// WithTask returns a context carrying a task hint. Completions made with it
+// that do not set a model are routed by OpenAI.Router.
+func WithTask(ctx context.Context, task TaskHint) context.Context {
+	return context.WithValue(ctx, taskKey{}, task)
+}
+
+// Task returns the task hint carried by ctx, if any.
+func Task(ctx context.Context) TaskHint {
+	task, _ := ctx.Value(taskKey{}).(TaskHint)
+	return task
+}
+
+// route applies the route for the context's task hint to a payload without a
+// model. Parameters already set in ExtraBody take precedence over the route's.
+func (r Router) route(ctx context.Context, payload *CompletionRequestPayload) error {
+	task := Task(ctx)
+	if task == "" || payload.Model != "" {
+		return nil
+	}
+
+	route, ok := r[task]
+	if !ok {
+		return NewInvalidRequestError(fmt.Sprintf("no route for task %q", task))
+	}
+
+	payload.Model = route.Model
+	if len(route.ExtraBody) == 0 {
+		return nil
+	}
+	// The caller's map may be shared with other payloads, so the route's
+	// parameters are merged into a copy.
+	extraBody := maps.Clone(route.ExtraBody)
+	maps.Copy(extraBody, payload.ExtraBody)
+	payload.ExtraBody = extraBody
+	return nil
+}
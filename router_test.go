@@ -0,0 +1,65 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestGetCompletion_RoutesByTask(t *testing.T) {
+	var body map[string]any
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			data, _ := io.ReadAll(req.Body)
+			json.Unmarshal(data, &body)
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+	client.Router = Router{
+		TaskReasoning: {Model: "o3-mini", ExtraBody: map[string]any{"reasoning_effort": "high", "seed": 1}},
+		TaskFast:      {Model: "gpt-4o-mini"},
+	}
+
+	ctx := WithTask(context.Background(), TaskReasoning)
+	shared := map[string]any{"seed": 7}
+	payload := &CompletionRequestPayload{
+		Messages:  []Message{{Role: "user", Content: "Hi"}},
+		ExtraBody: shared,
+	}
+	if _, err := client.GetCompletionContext(ctx, payload); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if body["model"] != "o3-mini" || body["reasoning_effort"] != "high" {
+		t.Errorf("expected the reasoning route to be applied, got %v", body)
+	}
+	if body["seed"] != float64(7) {
+		t.Errorf("expected the payload's own parameters to win, got %v", body["seed"])
+	}
+	if len(shared) != 1 {
+		t.Errorf("expected the caller's ExtraBody not to be modified, got %v", shared)
+	}
+}
+
+func TestRouter_Route(t *testing.T) {
+	router := Router{TaskFast: {Model: "gpt-4o-mini"}}
+
+	payload := &CompletionRequestPayload{Model: "explicit-model"}
+	if err := router.route(WithTask(context.Background(), TaskFast), payload); err != nil || payload.Model != "explicit-model" {
+		t.Errorf("expected an explicit model to be kept, got %q and %v", payload.Model, err)
+	}
+
+	payload = &CompletionRequestPayload{}
+	if err := router.route(context.Background(), payload); err != nil || payload.Model != "" {
+		t.Errorf("expected no routing without a task, got %q and %v", payload.Model, err)
+	}
+
+	if err := router.route(WithTask(context.Background(), TaskVision), payload); GetOpenAIErrorType(err) != ErrTypeInvalidRequest {
+		t.Errorf("expected an error for a task without a route, got %v", err)
+	}
+}
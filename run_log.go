@@ -0,0 +1,81 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	RunEventRequest    = "request"
+	RunEventResponse   = "response"
+	RunEventToolCall   = "tool_call"
+	RunEventToolResult = "tool_result"
+	RunEventError      = "error"
+)
+
+type (
+	// RunEvent is a step of an agent run as written to a RunLog. Iterations
+	// count from zero; the fields that do not apply to the event type are
+	// left empty.
+	RunEvent struct {
+		Time          time.Time `json:"time"`
+		Type          string    `json:"type"`
+		CorrelationId string    `json:"correlation_id,omitempty"`
+		Iteration     int       `json:"iteration"`
+		// Model, Messages, Tools and PromptHash summarize a request, and
+		// Input holds the messages added since the previous response: the
+		// whole prompt on the first iteration.
+		Model      string    `json:"model,omitempty"`
+		Messages   int       `json:"messages,omitempty"`
+		Tools      int       `json:"tools,omitempty"`
+		PromptHash string    `json:"prompt_hash,omitempty"`
+		Input      []Message `json:"input,omitempty"`
+		// Message and Usage are the reply and token usage of a response.
+		Message *Message  `json:"message,omitempty"`
+		Usage   *LLMUsage `json:"usage,omitempty"`
+		// ToolName, ToolCallId, Arguments and Result describe a tool call
+		// and its result.
+		ToolName   string `json:"tool_name,omitempty"`
+		ToolCallId string `json:"tool_call_id,omitempty"`
+		Arguments  string `json:"arguments,omitempty"`
+		Result     string `json:"result,omitempty"`
+		// Duration is the latency of a response or the run time of a tool.
+		Duration time.Duration `json:"duration,omitempty"`
+		Error    string        `json:"error,omitempty"`
+	}
+
+	// RunLog writes the events of agent runs to an io.Writer, such as a file,
+	// as JSON lines. Message contents are written in full regardless of the
+	// client's LogRedaction. It is safe for concurrent runs.
+	RunLog struct {
+		mu      sync.Mutex
+		encoder *json.Encoder
+	}
+)
+
+// NewRunLog returns a RunLog appending to w.
+func NewRunLog(w io.Writer) *RunLog {
+	return &RunLog{encoder: json.NewEncoder(w)}
+}
+
+func (l *RunLog) record(ctx context.Context, event RunEvent) {
+	if l == nil {
+		return
+	}
+	event.Time = time.Now()
+	event.CorrelationId = CorrelationId(ctx)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.encoder.Encode(event); err != nil {
+		slog.Warn("error writing run log", slog.String("error", err.Error()), correlationAttr(ctx))
+	}
+}
+
+func (l *RunLog) recordError(ctx context.Context, iteration int, err error) {
+	l.record(ctx, RunEvent{Type: RunEventError, Iteration: iteration, Error: err.Error()})
+}
@@ -0,0 +1,93 @@
+package openaiclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestGetCompletion_RunLog(t *testing.T) {
+	seqClient := &SequentialFakeClient{
+		Responses: []*http.Response{
+			fakeResponse(http.StatusOK, `{"model":"test-model","choices":[{"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"echo","arguments":"{\"text\":\"hi\"}"}}]}}],"usage":{"total_tokens":12}}`),
+			fakeResponse(http.StatusOK, completionBody),
+		},
+	}
+
+	var buffer bytes.Buffer
+	client := createClient(t)
+	client.client = seqClient
+	client.RunLog = NewRunLog(&buffer)
+
+	payload := &CompletionRequestPayload{
+		Model:    "test-model",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+		Tools: []ToolDefinition{NewToolDefinition(&FunctionDefinition{
+			Name: "echo",
+			Fn:   func(args string) string { return args },
+		})},
+	}
+	ctx := WithCorrelationId(context.Background(), "run-1")
+	if _, err := client.GetCompletionContext(ctx, payload); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var events []RunEvent
+	scanner := bufio.NewScanner(&buffer)
+	for scanner.Scan() {
+		var event RunEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("expected a JSON line, got %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+
+	want := []string{RunEventRequest, RunEventResponse, RunEventToolCall, RunEventToolResult, RunEventRequest, RunEventResponse}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d: %+v", len(want), len(events), events)
+	}
+	for i, event := range events {
+		if event.Type != want[i] || event.CorrelationId != "run-1" {
+			t.Errorf("event %d: expected %s for run-1, got %s for %q", i, want[i], event.Type, event.CorrelationId)
+		}
+	}
+	if events[0].Messages != 1 || events[0].Tools != 1 {
+		t.Errorf("expected the request summary, got %+v", events[0])
+	}
+	if events[1].Usage == nil || events[1].Usage.TotalTokens != 12 {
+		t.Errorf("expected the response usage, got %+v", events[1].Usage)
+	}
+	if events[3].ToolName != "echo" || events[3].Result != `{"text":"hi"}` {
+		t.Errorf("expected the tool result, got %+v", events[3])
+	}
+	if events[4].Iteration != 1 || events[5].Message.Content == "" {
+		t.Errorf("expected the final response in the second iteration, got %+v", events[5])
+	}
+}
+
+func TestGetCompletion_RunLogRecordsErrors(t *testing.T) {
+	var buffer bytes.Buffer
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return fakeResponse(http.StatusBadRequest, `{"error":{"message":"bad request","type":"invalid_request_error"}}`), nil
+		},
+	}
+	client.RunLog = NewRunLog(&buffer)
+
+	if _, err := client.GetCompletion(&CompletionRequestPayload{Model: "test-model", Messages: []Message{{Role: "user", Content: "Hi"}}}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+	var event RunEvent
+	if err := json.Unmarshal(lines[len(lines)-1], &event); err != nil {
+		t.Fatalf("expected a JSON line, got %v", err)
+	}
+	if event.Type != RunEventError || event.Error == "" {
+		t.Errorf("expected an error event, got %+v", event)
+	}
+}
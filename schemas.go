@@ -1,5 +1,11 @@
 package openaiclient
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
 type MessageRole string
 
 var (
@@ -10,6 +16,16 @@ var (
 	MessageRoleTool      MessageRole = "tool"
 )
 
+// FinishReason is why the model stopped generating a choice.
+type FinishReason string
+
+const (
+	FinishReasonStop          FinishReason = "stop"
+	FinishReasonLength        FinishReason = "length"
+	FinishReasonToolCalls     FinishReason = "tool_calls"
+	FinishReasonContentFilter FinishReason = "content_filter"
+)
+
 type (
 	JsonSchemaProperties map[string]*JsonSchema
 	JsonSchema           struct {
@@ -18,6 +34,7 @@ type (
 		Properties  JsonSchemaProperties `json:"properties,omitempty"`
 		Required    []string             `json:"required,omitempty"`
 		Items       *JsonSchema          `json:"items,omitempty"`
+		Enum        []string             `json:"enum,omitempty"`
 	}
 
 	ToolResult struct {
@@ -27,6 +44,11 @@ type (
 
 	LLMTool = func(string) string
 
+	// LLMToolContext is a tool that receives the context of the completion
+	// request, to respect its cancellation, and can fail. Errors are sent
+	// to the model as a ToolResult.
+	LLMToolContext = func(ctx context.Context, arguments string) (string, error)
+
 	FunctionCall struct {
 		Name      string `json:"name"`
 		Arguments string `json:"arguments"`
@@ -43,6 +65,9 @@ type (
 		Description string      `json:"description,omitempty"`
 		Parameters  *JsonSchema `json:"parameters,omitempty"`
 		Fn          LLMTool     `json:"-"`
+		// FnContext, when set, is called instead of Fn with the context of the
+		// completion request.
+		FnContext LLMToolContext `json:"-"`
 	}
 
 	ToolDefinition struct {
@@ -56,11 +81,31 @@ type (
 		ToolCalls  []ToolCall  `json:"tool_calls,omitempty"`
 		Name       string      `json:"name,omitempty"`
 		ToolCallId string      `json:"tool_call_id,omitempty"`
+		// ReasoningContent is the reasoning some OpenAI-compatible providers,
+		// such as DeepSeek, return alongside the content. It is never sent
+		// back, as those providers reject it in the conversation history.
+		ReasoningContent string `json:"reasoning_content,omitempty"`
 	}
 
 	GetEmbeddingPayload struct {
 		Model string `json:"model"`
-		Input string `json:"input"`
+		// Input is the text to embed. Inputs embeds several texts, and Tokens
+		// several pre-tokenized inputs, in one request instead. Only one of
+		// them can be set.
+		Input  string   `json:"input"`
+		Inputs []string `json:"-"`
+		Tokens [][]int  `json:"-"`
+		// Dimensions shortens the embeddings of the models that support it,
+		// such as text-embedding-3-small.
+		Dimensions *int `json:"dimensions,omitempty"`
+		// EncodingFormat is "float", the default, or EmbeddingEncodingBase64.
+		EncodingFormat string `json:"encoding_format,omitempty"`
+		// User identifies the end user for abuse monitoring. It is filled from
+		// WithEndUser when empty.
+		User string `json:"user,omitempty"`
+		// ExtraBody is merged into the serialized request, for parameters the
+		// library does not model yet.
+		ExtraBody map[string]any `json:"-"`
 	}
 
 	CompletionRequestPayload struct {
@@ -68,23 +113,76 @@ type (
 		Messages    []Message        `json:"messages"`
 		NewMessages []Message        `json:"-"`
 		Tools       []ToolDefinition `json:"tools,omitempty"`
-		ToolChoice  any              `json:"tool_choice,omitempty"`
+		ToolChoice  *ToolChoice      `json:"tool_choice,omitempty"`
+		// The sampling parameters are left to the API defaults when unset.
+		Temperature *float64 `json:"temperature,omitempty"`
+		TopP        *float64 `json:"top_p,omitempty"`
+		// MaxTokens is the deprecated predecessor of MaxCompletionTokens,
+		// which reasoning models require as it also bounds reasoning tokens.
+		MaxTokens           *int     `json:"max_tokens,omitempty"`
+		MaxCompletionTokens *int     `json:"max_completion_tokens,omitempty"`
+		Stop                []string `json:"stop,omitempty"`
+		FrequencyPenalty    *float64 `json:"frequency_penalty,omitempty"`
+		PresencePenalty     *float64 `json:"presence_penalty,omitempty"`
+		Seed                *int     `json:"seed,omitempty"`
+		// N is the number of choices to generate. Only the first one is used
+		// by GetCompletion.
+		N     *int  `json:"n,omitempty"`
+		Store *bool `json:"store,omitempty"`
+		// Metadata tags stored completions. The prompt hash is added to it
+		// under PromptHashMetadataKey.
+		Metadata map[string]string `json:"metadata,omitempty"`
+		// SafetyIdentifier identifies the end user for abuse monitoring, ideally
+		// as a hash of their id or email. It is filled from WithEndUser when
+		// neither it nor the deprecated User is set.
+		SafetyIdentifier string `json:"safety_identifier,omitempty"`
+		// User is the deprecated predecessor of SafetyIdentifier.
+		User string `json:"user,omitempty"`
+		// ExtraBody is merged into the serialized request, for backend
+		// specific fields such as vLLM's guided_regex or guided_grammar.
+		ExtraBody map[string]any `json:"-"`
+	}
+
+	PromptTokensDetails struct {
+		CachedTokens int `json:"cached_tokens"`
+		AudioTokens  int `json:"audio_tokens"`
+	}
+
+	CompletionTokensDetails struct {
+		ReasoningTokens          int `json:"reasoning_tokens"`
+		AudioTokens              int `json:"audio_tokens"`
+		AcceptedPredictionTokens int `json:"accepted_prediction_tokens"`
+		RejectedPredictionTokens int `json:"rejected_prediction_tokens"`
 	}
 
 	LLMUsage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
+		PromptTokens            int                      `json:"prompt_tokens"`
+		CompletionTokens        int                      `json:"completion_tokens"`
+		TotalTokens             int                      `json:"total_tokens"`
+		PromptTokensDetails     *PromptTokensDetails     `json:"prompt_tokens_details,omitempty"`
+		CompletionTokensDetails *CompletionTokensDetails `json:"completion_tokens_details,omitempty"`
 	}
 
 	LLMChoice struct {
-		Index   int      `json:"index"`
-		Message *Message `json:"message"`
+		Index        int          `json:"index"`
+		Message      *Message     `json:"message"`
+		FinishReason FinishReason `json:"finish_reason,omitempty"`
 	}
 
 	CompletionResponse struct {
-		Choices []LLMChoice `json:"choices"`
-		Usage   *LLMUsage   `json:"usage"`
+		Id                string      `json:"id"`
+		Object            string      `json:"object"`
+		Created           int64       `json:"created"`
+		Model             string      `json:"model"`
+		SystemFingerprint string      `json:"system_fingerprint,omitempty"`
+		Choices           []LLMChoice `json:"choices"`
+		Usage             *LLMUsage   `json:"usage"`
+		// TotalUsage is the usage summed over every request of the run,
+		// including the ones answered with tool calls. It is set by
+		// GetCompletionResponse when the responses report usage.
+		TotalUsage *LLMUsage `json:"-"`
+		// Raw is the full response body when OpenAI.KeepRawResponses is set.
+		Raw json.RawMessage `json:"-"`
 	}
 
 	EmbeddingObject struct {
@@ -98,6 +196,20 @@ type (
 		Object string            `json:"object"`
 		Data   []EmbeddingObject `json:"data"`
 		Usage  *LLMUsage         `json:"usage"`
+		// Raw is the full response body when OpenAI.KeepRawResponses is set.
+		Raw json.RawMessage `json:"-"`
+	}
+
+	Model struct {
+		Id      string `json:"id"`
+		Object  string `json:"object"`
+		Created int64  `json:"created"`
+		OwnedBy string `json:"owned_by"`
+	}
+
+	ListModelsResponse struct {
+		Object string  `json:"object"`
+		Data   []Model `json:"data"`
 	}
 )
 
@@ -109,6 +221,27 @@ func (c *CompletionRequestPayload) toolsMap() map[string]*FunctionDefinition {
 	return toolsMap
 }
 
+// call runs the tool with the context of the completion request.
+func (f *FunctionDefinition) call(ctx context.Context, arguments string) string {
+	switch {
+	case f.FnContext != nil:
+		result, err := f.FnContext(ctx, arguments)
+		if err != nil {
+			return toolError(err)
+		}
+		return result
+	case f.Fn != nil:
+		return f.Fn(arguments)
+	default:
+		return toolError(fmt.Errorf("tool %s has no function", f.Name))
+	}
+}
+
+// runnable reports whether the tool has a function to call.
+func (f *FunctionDefinition) runnable() bool {
+	return f.FnContext != nil || f.Fn != nil
+}
+
 // NewToolDefinition creates a new ToolDefinition with the given FunctionDefinition
 // by automatically populating the "Type" field with "function".
 func NewToolDefinition(functionDefinition *FunctionDefinition) ToolDefinition {
@@ -118,7 +251,31 @@ func NewToolDefinition(functionDefinition *FunctionDefinition) ToolDefinition {
 	}
 }
 
+// stored reports whether the completion is persisted by the API, which makes
+// repeating the request unsafe.
+func (c *CompletionRequestPayload) stored() bool {
+	return c.Store != nil && *c.Store
+}
+
 func (c *CompletionRequestPayload) AddMessages(messages ...Message) {
 	c.Messages = append(c.Messages, messages...)
 	c.NewMessages = append(c.NewMessages, messages...)
 }
+
+// inputCount returns the number of inputs the payload embeds.
+func (p GetEmbeddingPayload) inputCount() int {
+	switch {
+	case p.Inputs != nil:
+		return len(p.Inputs)
+	case p.Tokens != nil:
+		return len(p.Tokens)
+	default:
+		return 1
+	}
+}
+
+// Ptr returns a pointer to v, to set the optional fields of payloads, e.g.
+// Temperature: openaiclient.Ptr(0.2).
+func Ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,121 @@
+package openaiclient
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+const defaultShadowTimeout = time.Minute
+
+type (
+	// Shadow duplicates a fraction of the completions of a client to another
+	// model or provider in the background, so a migration target can be
+	// compared against production traffic. The shadow never affects the
+	// primary response: it is sent after the primary run succeeds, and its
+	// errors are only recorded.
+	//
+	// Tools are never run for the shadow, so it answers the request of the
+	// first iteration only, and is compared against the primary reply to
+	// that same request.
+	Shadow struct {
+		// Client receives the shadow requests. When nil, the primary client
+		// does, which is enough to compare models of the same provider,
+		// without charging its Usage or falling back to its
+		// FallbackModels.
+		Client *OpenAI
+		// Model replaces the model of the shadow requests when set.
+		Model string
+		// Fraction is the share of completions, from 0 to 1, that are
+		// shadowed.
+		Fraction float64
+		// Timeout bounds a shadow request, which outlives the primary
+		// request's context but not Close. It defaults to a minute.
+		Timeout time.Duration
+		// Record receives the outcome of every shadowed completion. It is
+		// called from the shadow's goroutine.
+		Record func(ShadowComparison)
+	}
+
+	ShadowComparison struct {
+		CorrelationId string
+		Input         []Message
+		PrimaryModel  string
+		Primary       *Message
+		ShadowModel   string
+		Shadow        *Message
+		// Latency is the duration of the shadow request.
+		Latency time.Duration
+		Err     error
+	}
+)
+
+// shadowPayload returns the copy of the payload to shadow, or nil when the
+// completion is not sampled.
+func (s *Shadow) shadowPayload(payload *CompletionRequestPayload) *CompletionRequestPayload {
+	if s == nil || s.Fraction <= 0 || rand.Float64() >= s.Fraction {
+		return nil
+	}
+	model := s.Model
+	if model == "" {
+		model = payload.Model
+	}
+	return withModel(payload, model)
+}
+
+// send runs the shadow request in the background and records it next to the
+// primary reply to the same messages.
+func (s *Shadow) send(ctx context.Context, primary *OpenAI, payload *CompletionRequestPayload, primaryRun *CompletionRequestPayload) {
+	comparison := ShadowComparison{
+		CorrelationId: CorrelationId(ctx),
+		Input:         payload.Messages,
+		PrimaryModel:  primaryRun.Model,
+		ShadowModel:   payload.Model,
+	}
+	if len(primaryRun.Messages) > len(payload.Messages) {
+		reply := primaryRun.Messages[len(payload.Messages)]
+		comparison.Primary = &reply
+	}
+
+	client := s.Client
+	if client == nil {
+		// The shadow is an experiment: it is kept out of the primary's
+		// budget and fallback chain.
+		shadowClient := *primary
+		shadowClient.Usage = nil
+		shadowClient.FallbackModels = nil
+		client = &shadowClient
+	}
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = defaultShadowTimeout
+	}
+
+	done, ok := primary.lifecycle.beginBackground()
+	if !ok {
+		return
+	}
+	go func() {
+		defer done()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+
+		start := time.Now()
+		response, err := client.getCompletion(ctx, payload)
+		comparison.Latency = time.Since(start)
+		if err != nil {
+			primary.warn(ctx, WarningShadowFailed, "shadow request failed", slog.String("model", payload.Model), slog.Any("error", err))
+			comparison.Err = err
+		} else {
+			comparison.Shadow = response.Choices[0].Message
+			if response.Model != "" {
+				comparison.ShadowModel = response.Model
+			}
+		}
+
+		if s.Record != nil {
+			s.Record(comparison)
+		}
+	}()
+}
@@ -0,0 +1,122 @@
+package openaiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetCompletion_Shadow(t *testing.T) {
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			if strings.Contains(string(body), `"model":"shadow-model"`) {
+				return fakeResponse(http.StatusOK, `{"model":"shadow-model","choices":[{"message":{"role":"assistant","content":"shadow"}}]}`), nil
+			}
+			return fakeResponse(http.StatusOK, `{"model":"test-model","choices":[{"message":{"role":"assistant","content":"primary"}}]}`), nil
+		},
+	}
+	comparisons := make(chan ShadowComparison, 1)
+	client.Shadow = &Shadow{
+		Model:    "shadow-model",
+		Fraction: 1,
+		Record:   func(c ShadowComparison) { comparisons <- c },
+	}
+
+	ctx, cancel := context.WithCancel(WithCorrelationId(context.Background(), "run-1"))
+	message, err := client.GetCompletionContext(ctx, &CompletionRequestPayload{
+		Model:    "test-model",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+	})
+	cancel()
+	if err != nil || message.Content != "primary" {
+		t.Fatalf("expected the primary reply, got %+v, %v", message, err)
+	}
+
+	select {
+	case comparison := <-comparisons:
+		if comparison.Err != nil || comparison.Shadow.Content != "shadow" || comparison.Primary.Content != "primary" {
+			t.Errorf("expected both replies, got %+v", comparison)
+		}
+		if comparison.CorrelationId != "run-1" || comparison.ShadowModel != "shadow-model" || len(comparison.Input) != 1 {
+			t.Errorf("unexpected comparison %+v", comparison)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the shadow to be recorded")
+	}
+}
+
+func TestGetCompletion_ShadowNotSampled(t *testing.T) {
+	calls := 0
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			calls++
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+	client.Shadow = &Shadow{Model: "shadow-model", Fraction: 0}
+
+	if _, err := client.GetCompletion(&CompletionRequestPayload{Model: "test-model", Messages: []Message{{Role: "user", Content: "Hi"}}}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected only the primary request, got %d", calls)
+	}
+}
+
+func TestGetCompletion_ShadowIsolatedFromPrimary(t *testing.T) {
+	release := make(chan struct{})
+	var models []string
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			if strings.Contains(string(body), `"model":"shadow-model"`) {
+				models = append(models, "shadow-model")
+				<-release
+				return fakeResponse(http.StatusNotFound, `{"error":{"type":"invalid_request_error","code":"model_not_found","message":"no such model"}}`), nil
+			}
+			if strings.Contains(string(body), `"model":"fallback-model"`) {
+				models = append(models, "fallback-model")
+			}
+			return fakeResponse(http.StatusOK, usageCompletionBody), nil
+		},
+	}
+	client.Usage = &UsageTracker{}
+	client.FallbackModels = []string{"fallback-model"}
+	comparisons := make(chan ShadowComparison, 1)
+	client.Shadow = &Shadow{
+		Model:    "shadow-model",
+		Fraction: 1,
+		Record:   func(c ShadowComparison) { comparisons <- c },
+	}
+
+	if _, err := client.GetCompletion(&CompletionRequestPayload{Model: "test-model", Messages: []Message{{Role: "user", Content: "Hi"}}}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := client.Close(ctx); err == nil {
+		t.Error("expected Close to wait for the shadow request")
+	}
+	close(release)
+
+	if comparison := <-comparisons; comparison.Err == nil {
+		t.Errorf("expected the shadow error, got %+v", comparison)
+	}
+	if err := client.Close(context.Background()); err != nil {
+		t.Errorf("expected Close to succeed once the shadow finished, got %v", err)
+	}
+	if len(models) != 1 {
+		t.Errorf("expected the shadow not to fall back, got requests for %v", models)
+	}
+	if total := client.Usage.Total(); total.TotalTokens != 10 {
+		t.Errorf("expected only the primary usage, got %+v", total)
+	}
+}
@@ -0,0 +1,190 @@
+package openaiclient
+
+import (
+	"fmt"
+	"sync"
+)
+
+const ErrCodeTokenBudgetExceeded = "token_budget_exceeded"
+
+type (
+	// UsageTracker accumulates the token usage of the completions made by a
+	// client and, when TokenLimit is set, rejects completions once the limit
+	// has been reached.
+	UsageTracker struct {
+		TokenLimit int
+
+		mu      sync.Mutex
+		total   LLMUsage
+		byModel map[string]LLMUsage
+	}
+
+	// TenantConfig configures the client a ClientPool hands out for a tenant.
+	TenantConfig struct {
+		DefaultModel string
+		// TokenLimit caps the total tokens the tenant's completions may use.
+		TokenLimit int
+	}
+
+	// ClientPool hands out a client per tenant. The clients share the HTTP
+	// transport and settings of the base client, but each one paces its own
+	// rate limits and tracks its own usage and budget.
+	ClientPool struct {
+		base *OpenAI
+
+		mu      sync.Mutex
+		configs map[string]TenantConfig
+		clients map[string]*OpenAI
+		// usage outlives the clients, which Configure replaces, so a
+		// tenant's usage counts against its budget across configurations.
+		usage map[string]*UsageTracker
+	}
+)
+
+// Total returns the usage accumulated so far.
+func (u *UsageTracker) Total() LLMUsage {
+	if u == nil {
+		return LLMUsage{}
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.total
+}
+
+// ByModel returns the usage accumulated so far for each model.
+func (u *UsageTracker) ByModel() map[string]LLMUsage {
+	if u == nil {
+		return nil
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	byModel := make(map[string]LLMUsage, len(u.byModel))
+	for model, usage := range u.byModel {
+		byModel[model] = usage
+	}
+	return byModel
+}
+
+func (u *UsageTracker) add(model string, usage *LLMUsage) {
+	if u == nil || usage == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.byModel == nil {
+		u.byModel = map[string]LLMUsage{}
+	}
+	u.total = addUsage(u.total, *usage)
+	u.byModel[model] = addUsage(u.byModel[model], *usage)
+}
+
+func (u *UsageTracker) checkBudget() error {
+	if u == nil {
+		return nil
+	}
+	u.mu.Lock()
+	limit, total := u.TokenLimit, u.total.TotalTokens
+	u.mu.Unlock()
+	if limit <= 0 {
+		return nil
+	}
+	if total >= limit {
+		return &OpenAIError{
+			Type:    ErrTypeInvalidRequest,
+			Message: fmt.Sprintf("token budget of %d exhausted (%d used)", limit, total),
+			Code:    ErrCodeTokenBudgetExceeded,
+		}
+	}
+	return nil
+}
+
+// setTokenLimit changes the limit of a tracker that may be in use.
+func (u *UsageTracker) setTokenLimit(limit int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.TokenLimit = limit
+}
+
+// addUsage sums two usages, token details included. The details stay nil
+// when neither usage has them.
+func addUsage(a, b LLMUsage) LLMUsage {
+	sum := LLMUsage{
+		PromptTokens:     a.PromptTokens + b.PromptTokens,
+		CompletionTokens: a.CompletionTokens + b.CompletionTokens,
+		TotalTokens:      a.TotalTokens + b.TotalTokens,
+	}
+	if a.PromptTokensDetails != nil || b.PromptTokensDetails != nil {
+		x, y := derefOrZero(a.PromptTokensDetails), derefOrZero(b.PromptTokensDetails)
+		sum.PromptTokensDetails = &PromptTokensDetails{
+			CachedTokens: x.CachedTokens + y.CachedTokens,
+			AudioTokens:  x.AudioTokens + y.AudioTokens,
+		}
+	}
+	if a.CompletionTokensDetails != nil || b.CompletionTokensDetails != nil {
+		x, y := derefOrZero(a.CompletionTokensDetails), derefOrZero(b.CompletionTokensDetails)
+		sum.CompletionTokensDetails = &CompletionTokensDetails{
+			ReasoningTokens:          x.ReasoningTokens + y.ReasoningTokens,
+			AudioTokens:              x.AudioTokens + y.AudioTokens,
+			AcceptedPredictionTokens: x.AcceptedPredictionTokens + y.AcceptedPredictionTokens,
+			RejectedPredictionTokens: x.RejectedPredictionTokens + y.RejectedPredictionTokens,
+		}
+	}
+	return sum
+}
+
+func derefOrZero[T any](v *T) T {
+	if v == nil {
+		var zero T
+		return zero
+	}
+	return *v
+}
+
+func NewClientPool(base *OpenAI) *ClientPool {
+	return &ClientPool{
+		base:    base,
+		configs: map[string]TenantConfig{},
+		clients: map[string]*OpenAI{},
+		usage:   map[string]*UsageTracker{},
+	}
+}
+
+// Configure sets the configuration of a tenant. It applies to the clients
+// handed out afterwards, so it should be called before the tenant's first
+// request. The tenant's usage so far is kept, and counts against its new
+// TokenLimit.
+func (p *ClientPool) Configure(tenant string, config TenantConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.configs[tenant] = config
+	delete(p.clients, tenant)
+}
+
+// Client returns the client of the tenant, creating it on first use.
+func (p *ClientPool) Client(tenant string) *OpenAI {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if client, ok := p.clients[tenant]; ok {
+		return client
+	}
+
+	config := p.configs[tenant]
+	client := *p.base
+	client.pacer = &pacer{}
+	usage, ok := p.usage[tenant]
+	if !ok {
+		usage = &UsageTracker{}
+		p.usage[tenant] = usage
+	}
+	usage.setTokenLimit(config.TokenLimit)
+	client.Usage = usage
+	if config.DefaultModel != "" {
+		client.DefaultModel = config.DefaultModel
+	}
+
+	p.clients[tenant] = &client
+	return &client
+}
@@ -0,0 +1,117 @@
+package openaiclient
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+const usageCompletionBody = `{"choices":[{"message":{"role":"assistant","content":"Hello world"}}],"usage":{"prompt_tokens":6,"completion_tokens":4,"total_tokens":10}}`
+
+func TestClientPool_IsolatesTenants(t *testing.T) {
+	var models []string
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			var payload CompletionRequestPayload
+			body, _ := io.ReadAll(req.Body)
+			json.Unmarshal(body, &payload)
+			models = append(models, payload.Model)
+			return fakeResponse(http.StatusOK, usageCompletionBody), nil
+		},
+	}
+
+	base := createClient(t)
+	base.client = fakeClient
+	pool := NewClientPool(base)
+	pool.Configure("acme", TenantConfig{DefaultModel: "acme-model", TokenLimit: 15})
+
+	acme := pool.Client("acme")
+	if pool.Client("acme") != acme {
+		t.Error("expected the tenant's client to be reused")
+	}
+	other := pool.Client("other")
+
+	newPayload := func() *CompletionRequestPayload {
+		return &CompletionRequestPayload{Messages: []Message{{Role: "user", Content: "Hi"}}}
+	}
+	for range 2 {
+		if _, err := acme.GetCompletion(newPayload()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if _, err := other.GetCompletion(newPayload()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err := acme.GetCompletion(newPayload())
+	var apiErr *OpenAIError
+	if !errors.As(err, &apiErr) || apiErr.Code != ErrCodeTokenBudgetExceeded {
+		t.Fatalf("expected token budget error, got %v", err)
+	}
+
+	if len(models) != 3 || models[0] != "acme-model" || models[2] == "acme-model" {
+		t.Errorf("unexpected models %v", models)
+	}
+	if total := acme.Usage.Total(); total.TotalTokens != 20 || total.PromptTokens != 12 {
+		t.Errorf("unexpected acme usage %+v", total)
+	}
+	if total := other.Usage.Total(); total.TotalTokens != 10 {
+		t.Errorf("unexpected other usage %+v", total)
+	}
+	if byModel := acme.Usage.ByModel(); byModel["acme-model"].CompletionTokens != 8 {
+		t.Errorf("unexpected usage by model %+v", byModel)
+	}
+	if base.Usage != nil || acme.pacer == base.pacer {
+		t.Error("expected the tenant clients not to share state with the base client")
+	}
+
+	pool.Configure("acme", TenantConfig{DefaultModel: "acme-model", TokenLimit: 25})
+	reconfigured := pool.Client("acme")
+	if reconfigured.Usage.Total().TotalTokens != 20 {
+		t.Errorf("expected the usage to survive the new configuration, got %+v", reconfigured.Usage.Total())
+	}
+	if _, err := reconfigured.GetCompletion(newPayload()); err != nil {
+		t.Fatalf("expected the raised budget to allow a request, got %v", err)
+	}
+	if _, err := reconfigured.GetCompletion(newPayload()); GetOpenAIErrorType(err) != ErrTypeInvalidRequest {
+		t.Errorf("expected the raised budget to be exhausted, got %v", err)
+	}
+}
+
+func TestUsageTracker_Nil(t *testing.T) {
+	var tracker *UsageTracker
+	tracker.add("model", &LLMUsage{TotalTokens: 1})
+
+	if tracker.Total() != (LLMUsage{}) || tracker.checkBudget() != nil {
+		t.Error("expected a nil tracker to track nothing")
+	}
+}
+
+func TestUsageTracker_TokenDetails(t *testing.T) {
+	base := createClient(t)
+	base.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			return fakeResponse(http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":"Hi"}}],"usage":{"prompt_tokens":6,"completion_tokens":4,"total_tokens":10,"prompt_tokens_details":{"cached_tokens":3},"completion_tokens_details":{"reasoning_tokens":2}}}`), nil
+		},
+	}
+	acme := NewClientPool(base).Client("acme")
+
+	for range 2 {
+		if _, err := acme.GetCompletion(&CompletionRequestPayload{Model: "gpt-4o-mini", Messages: []Message{{Role: "user", Content: "Hi"}}}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	total := acme.Usage.Total()
+	if total.PromptTokensDetails == nil || total.PromptTokensDetails.CachedTokens != 6 {
+		t.Errorf("expected the cached tokens of the tenant, got %+v", total.PromptTokensDetails)
+	}
+	if total.CompletionTokensDetails == nil || total.CompletionTokensDetails.ReasoningTokens != 4 {
+		t.Errorf("expected the reasoning tokens of the tenant, got %+v", total.CompletionTokensDetails)
+	}
+	if byModel := acme.Usage.ByModel()["gpt-4o-mini"]; byModel.PromptTokensDetails == nil || byModel.PromptTokensDetails.CachedTokens != 6 {
+		t.Errorf("expected the cached tokens by model, got %+v", byModel.PromptTokensDetails)
+	}
+}
@@ -0,0 +1,172 @@
+package openaiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrNoScriptedTurn is returned by a TestClient asked for more responses than
+// it was scripted with.
+var ErrNoScriptedTurn = errors.New("no scripted turn left")
+
+type (
+	// ScriptedTurn is a response of a TestClient. A turn answers a completion
+	// with an assistant message of Content and ToolCalls, or an embedding
+	// request with Embedding, or with Embeddings for a batch of inputs.
+	ScriptedTurn struct {
+		Content    string
+		ToolCalls  []ToolCall
+		Embedding  []float64
+		Embeddings [][]float64
+		Usage      *LLMUsage
+		// StatusCode, when set to an error status, answers with an API error
+		// of Content as its message and ErrorCode as its code, e.g.
+		// http.StatusTooManyRequests to exercise rate limit handling.
+		StatusCode int
+		ErrorCode  string
+		// Err fails the request before it reaches the API, like a network
+		// error.
+		Err error
+	}
+
+	// TestClient is an OpenAI client that answers with scripted turns, in
+	// order, instead of calling an API, for unit testing code that depends on
+	// *OpenAI. Tools run as usual, so a turn with tool calls is followed by
+	// the turn answering their results. It is safe for concurrent use.
+	TestClient struct {
+		*OpenAI
+
+		mu          sync.Mutex
+		turns       []ScriptedTurn
+		completions []CompletionRequestPayload
+		embeddings  []GetEmbeddingPayload
+	}
+)
+
+// NewTestClient returns a client that answers with the turns, in order. Its
+// settings are those of New, without retries.
+func NewTestClient(turns ...ScriptedTurn) *TestClient {
+	client := &TestClient{turns: turns}
+	client.OpenAI = &OpenAI{
+		baseUrl:       "http://test",
+		client:        scriptedTransport{client},
+		key:           "test",
+		MaxIterations: 5,
+		Features:      DefaultFeatures,
+		ModelRegistry: DefaultModelRegistry,
+		pacer:         &pacer{},
+		lifecycle:     &lifecycle{},
+	}
+	return client
+}
+
+// Completions returns the completion requests received so far.
+func (c *TestClient) Completions() []CompletionRequestPayload {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]CompletionRequestPayload(nil), c.completions...)
+}
+
+// Embeddings returns the embedding requests received so far.
+func (c *TestClient) Embeddings() []GetEmbeddingPayload {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]GetEmbeddingPayload(nil), c.embeddings...)
+}
+
+// Remaining returns the number of turns not answered yet, to check that the
+// code under test made every expected request.
+func (c *TestClient) Remaining() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.turns)
+}
+
+// scriptedTransport answers the requests of a TestClient.
+type scriptedTransport struct{ *TestClient }
+
+func (t scriptedTransport) Do(request *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	switch request.URL.Path {
+	case completionsEndpont:
+		var payload CompletionRequestPayload
+		json.Unmarshal(body, &payload)
+		t.completions = append(t.completions, payload)
+	case embeddingsEndpoint:
+		var payload GetEmbeddingPayload
+		json.Unmarshal(body, &payload)
+		var input struct {
+			Input json.RawMessage `json:"input"`
+		}
+		json.Unmarshal(body, &input)
+		if json.Unmarshal(input.Input, &payload.Inputs) != nil {
+			json.Unmarshal(input.Input, &payload.Tokens)
+		}
+		t.embeddings = append(t.embeddings, payload)
+	}
+	if len(t.turns) == 0 {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("%w for %s", ErrNoScriptedTurn, request.URL.Path)
+	}
+	turn := t.turns[0]
+	t.turns = t.turns[1:]
+	t.mu.Unlock()
+
+	if turn.Err != nil {
+		return nil, turn.Err
+	}
+	status := http.StatusOK
+	var response any
+	switch {
+	case turn.StatusCode != 0 && turn.StatusCode != http.StatusOK:
+		status = turn.StatusCode
+		response = map[string]any{"error": OpenAIError{Type: typeForStatus(status), Message: turn.Content, Code: turn.ErrorCode}}
+	case request.URL.Path == embeddingsEndpoint:
+		embeddings := turn.Embeddings
+		if embeddings == nil {
+			embeddings = [][]float64{turn.Embedding}
+		}
+		data := make([]EmbeddingObject, len(embeddings))
+		for i, embedding := range embeddings {
+			data[i] = EmbeddingObject{Object: "embedding", Index: i, Embedding: embedding}
+		}
+		response = GetEmbeddingResponse{Object: "list", Data: data, Usage: turn.Usage}
+	default:
+		finishReason := FinishReasonStop
+		if len(turn.ToolCalls) > 0 {
+			finishReason = FinishReasonToolCalls
+		}
+		response = CompletionResponse{
+			Object: "chat.completion",
+			Choices: []LLMChoice{{
+				Message: &Message{
+					Role:      MessageRoleAssistant,
+					Content:   turn.Content,
+					ToolCalls: turn.ToolCalls,
+				},
+				FinishReason: finishReason,
+			}},
+			Usage: turn.Usage,
+		}
+	}
+
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader(encoded)),
+	}, nil
+}
@@ -0,0 +1,84 @@
+package openaiclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestNewTestClient(t *testing.T) {
+	client := NewTestClient(
+		ScriptedTurn{ToolCalls: []ToolCall{{Id: "call_1", Type: "function", Function: FunctionCall{Name: "lookup", Arguments: `{}`}}}},
+		ScriptedTurn{Content: "It is sunny."},
+		ScriptedTurn{Embedding: []float64{0.1, 0.2}},
+	)
+
+	calls := 0
+	message, err := client.GetCompletionContext(context.Background(), &CompletionRequestPayload{
+		Model:    "gpt-4o-mini",
+		Messages: []Message{{Role: MessageRoleUser, Content: "Weather?"}},
+		Tools: []ToolDefinition{NewToolDefinition(&FunctionDefinition{
+			Name: "lookup",
+			Fn: func(string) string {
+				calls++
+				return "sunny"
+			},
+		})},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if message.Content != "It is sunny." || calls != 1 {
+		t.Errorf("expected the scripted answer after the tool call, got %q and %d calls", message.Content, calls)
+	}
+	if completions := client.Completions(); len(completions) != 2 || completions[1].Messages[2].Content != "sunny" {
+		t.Errorf("expected the tool result in the second request, got %+v", completions)
+	}
+
+	embedding, err := client.GetEmbeddingContext(context.Background(), GetEmbeddingPayload{Model: "text-embedding-3-small", Input: "hi"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(embedding) != 2 || client.Embeddings()[0].Input != "hi" || client.Remaining() != 0 {
+		t.Errorf("unexpected embedding %v", embedding)
+	}
+
+	if _, err := client.GetEmbeddingContext(context.Background(), GetEmbeddingPayload{Input: "again"}); !errors.Is(err, ErrNoScriptedTurn) {
+		t.Errorf("expected ErrNoScriptedTurn, got %v", err)
+	}
+}
+
+func TestNewTestClient_BatchEmbeddings(t *testing.T) {
+	client := NewTestClient(ScriptedTurn{Embeddings: [][]float64{{1, 0}, {0, 1}}})
+
+	var embedder EmbeddingClient = client
+	embeddings, err := embedder.GetEmbeddings(context.Background(), GetEmbeddingPayload{Inputs: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(embeddings) != 2 || embeddings[0][0] != 1 || embeddings[1][1] != 1 {
+		t.Errorf("expected the scripted vectors in order, got %v", embeddings)
+	}
+	if inputs := client.Embeddings()[0].Inputs; len(inputs) != 2 || inputs[1] != "b" {
+		t.Errorf("expected the batch inputs to be recorded, got %v", inputs)
+	}
+}
+
+func TestNewTestClient_Errors(t *testing.T) {
+	network := errors.New("connection reset")
+	client := NewTestClient(
+		ScriptedTurn{StatusCode: http.StatusTooManyRequests, Content: "slow down"},
+		ScriptedTurn{Err: network},
+	)
+	payload := &CompletionRequestPayload{Messages: []Message{{Role: MessageRoleUser, Content: "Hi"}}}
+
+	_, err := client.GetCompletionContext(context.Background(), payload)
+	var apiErr *OpenAIError
+	if !errors.As(err, &apiErr) || apiErr.Type != ErrTypeRateLimit || apiErr.Message != "slow down" || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected a rate limit error, got %v", err)
+	}
+	if _, err := client.GetCompletionContext(context.Background(), payload); !errors.Is(err, network) {
+		t.Errorf("expected the scripted error, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package openaiclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ToolChoiceMode is how the model may use the tools of a request.
+type ToolChoiceMode string
+
+const (
+	// ToolChoiceAuto lets the model choose between answering and calling
+	// tools, the default when tools are sent.
+	ToolChoiceAuto     ToolChoiceMode = "auto"
+	ToolChoiceNone     ToolChoiceMode = "none"
+	ToolChoiceRequired ToolChoiceMode = "required"
+)
+
+// ToolChoice is either a mode or the name of a function the model must
+// call. It is serialized to the matching shape of the API's tool_choice.
+type ToolChoice struct {
+	Mode     ToolChoiceMode
+	Function string
+}
+
+// NewToolChoice returns a choice of the given mode.
+func NewToolChoice(mode ToolChoiceMode) *ToolChoice {
+	return &ToolChoice{Mode: mode}
+}
+
+// NewFunctionToolChoice returns a choice forcing a call to the named
+// function.
+func NewFunctionToolChoice(name string) *ToolChoice {
+	return &ToolChoice{Function: name}
+}
+
+type functionToolChoice struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name string `json:"name"`
+	} `json:"function"`
+}
+
+func (c ToolChoice) MarshalJSON() ([]byte, error) {
+	switch {
+	case c.Function != "" && c.Mode != "":
+		return nil, errors.New("tool choice cannot set both a mode and a function")
+	case c.Function != "":
+		choice := functionToolChoice{Type: "function"}
+		choice.Function.Name = c.Function
+		return json.Marshal(choice)
+	case c.Mode == ToolChoiceAuto, c.Mode == ToolChoiceNone, c.Mode == ToolChoiceRequired:
+		return json.Marshal(string(c.Mode))
+	default:
+		return nil, fmt.Errorf("invalid tool choice mode %q", c.Mode)
+	}
+}
+
+func (c *ToolChoice) UnmarshalJSON(data []byte) error {
+	var mode string
+	if err := json.Unmarshal(data, &mode); err == nil {
+		*c = ToolChoice{Mode: ToolChoiceMode(mode)}
+		return nil
+	}
+
+	var choice functionToolChoice
+	if err := json.Unmarshal(data, &choice); err != nil {
+		return err
+	}
+	*c = ToolChoice{Function: choice.Function.Name}
+	return nil
+}
@@ -0,0 +1,49 @@
+package openaiclient
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestToolChoice_JSON(t *testing.T) {
+	tests := []struct {
+		choice *ToolChoice
+		want   string
+	}{
+		{NewToolChoice(ToolChoiceRequired), `"required"`},
+		{NewFunctionToolChoice("get_weather"), `{"type":"function","function":{"name":"get_weather"}}`},
+	}
+	for _, test := range tests {
+		data, err := json.Marshal(test.choice)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if string(data) != test.want {
+			t.Errorf("expected %s, got %s", test.want, data)
+		}
+
+		var decoded ToolChoice
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if decoded != *test.choice {
+			t.Errorf("expected %+v after a round trip, got %+v", *test.choice, decoded)
+		}
+	}
+}
+
+func TestToolChoice_InvalidShapes(t *testing.T) {
+	for _, choice := range []*ToolChoice{{}, {Mode: "always"}, {Mode: ToolChoiceAuto, Function: "echo"}} {
+		if _, err := json.Marshal(choice); err == nil {
+			t.Errorf("expected an error for %+v", choice)
+		}
+	}
+
+	data, err := json.Marshal(&CompletionRequestPayload{Messages: []Message{}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(data) != `{"messages":[]}` {
+		t.Errorf("expected no tool_choice when unset, got %s", data)
+	}
+}
@@ -0,0 +1,121 @@
+package openaiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const emulatedToolsPrompt = `You can call the following tools:
+%s
+To call a tool, reply with only a JSON object of the form {"tool": "<tool name>", "arguments": {...}} and nothing else. ` +
+	`The result of the tool will be sent back to you. When you have the final answer, reply normally, without JSON.`
+
+// emulatedToolCall is the action format models are instructed to follow when
+// tool calling is emulated.
+type emulatedToolCall struct {
+	Tool      string          `json:"tool"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// emulatesTools reports whether tool calling is emulated for the payload.
+func (o *OpenAI) emulatesTools(payload *CompletionRequestPayload) bool {
+	return o.EmulateTools && len(payload.Tools) > 0
+}
+
+// emulatedPayload describes the tools of the payload in a system message
+// instead, and rewrites the tool calls and results of earlier iterations as
+// plain text.
+func emulatedPayload(payload *CompletionRequestPayload) *CompletionRequestPayload {
+	emulated := *payload
+	emulated.Tools = nil
+	emulated.ToolChoice = nil
+	emulated.NewMessages = nil
+	emulated.Messages = make([]Message, 0, len(payload.Messages)+1)
+	emulated.Messages = append(emulated.Messages, Message{
+		Role:    MessageRoleSystem,
+		Content: fmt.Sprintf(emulatedToolsPrompt, describeTools(payload.Tools)),
+	})
+
+	names := map[string]string{}
+	for _, message := range payload.Messages {
+		switch {
+		case len(message.ToolCalls) > 0:
+			if message.Content != "" {
+				emulated.Messages = append(emulated.Messages, Message{Role: MessageRoleAssistant, Content: message.Content})
+			}
+			for _, call := range message.ToolCalls {
+				names[call.Id] = call.Function.Name
+				// Arguments that are not valid JSON are sent as a string, so
+				// the model still sees what it called the tool with and the
+				// action always marshals.
+				arguments := json.RawMessage(orEmptyObject(call.Function.Arguments))
+				if !json.Valid(arguments) {
+					arguments, _ = json.Marshal(call.Function.Arguments)
+				}
+				action, _ := json.Marshal(emulatedToolCall{Tool: call.Function.Name, Arguments: arguments})
+				emulated.Messages = append(emulated.Messages, Message{Role: MessageRoleAssistant, Content: string(action)})
+			}
+		case message.Role == MessageRoleTool:
+			emulated.Messages = append(emulated.Messages, Message{
+				Role:    MessageRoleUser,
+				Content: fmt.Sprintf("Result of tool %s:\n%s", names[message.ToolCallId], message.Content),
+			})
+		default:
+			emulated.Messages = append(emulated.Messages, message)
+		}
+	}
+	return &emulated
+}
+
+func describeTools(tools []ToolDefinition) string {
+	var description strings.Builder
+	for _, tool := range tools {
+		fmt.Fprintf(&description, "- %s", tool.Function.Name)
+		if tool.Function.Description != "" {
+			fmt.Fprintf(&description, ": %s", tool.Function.Description)
+		}
+		if tool.Function.Parameters != nil {
+			parameters, _ := json.Marshal(tool.Function.Parameters)
+			fmt.Fprintf(&description, "\n  arguments schema: %s", parameters)
+		}
+		description.WriteString("\n")
+	}
+	return description.String()
+}
+
+// parseEmulatedToolCall turns a reply following the emulated action format
+// into a tool call. Other replies are left as the final answer.
+func parseEmulatedToolCall(message *Message, tools map[string]*FunctionDefinition) {
+	var call emulatedToolCall
+	if err := json.Unmarshal([]byte(unfenceJSON(message.Content)), &call); err != nil || call.Tool == "" {
+		return
+	}
+	if _, ok := tools[call.Tool]; !ok {
+		return
+	}
+
+	message.Content = ""
+	message.ToolCalls = []ToolCall{{
+		Id:   "call_" + newIdempotencyKey()[:24],
+		Type: "function",
+		Function: FunctionCall{
+			Name:      call.Tool,
+			Arguments: orEmptyObject(string(call.Arguments)),
+		},
+	}}
+}
+
+// unfenceJSON returns the JSON of a reply, without the Markdown code fence
+// models often wrap it in.
+func unfenceJSON(content string) string {
+	content = strings.TrimPrefix(strings.TrimSpace(content), "```json")
+	return strings.Trim(strings.TrimSpace(content), "`")
+}
+
+func orEmptyObject(arguments string) string {
+	if strings.TrimSpace(arguments) == "" || arguments == "null" {
+		return "{}"
+	}
+	return arguments
+}
@@ -0,0 +1,118 @@
+package openaiclient
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGetCompletion_EmulatedTools(t *testing.T) {
+	var requests []map[string]any
+	var messages [][]Message
+	replies := []string{
+		"```json\n{\"tool\": \"get_weather\", \"arguments\": {\"city\": \"Lisbon\"}}\n```",
+		"It is sunny in Lisbon.",
+	}
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			var request map[string]any
+			json.Unmarshal(body, &request)
+			requests = append(requests, request)
+			var payload CompletionRequestPayload
+			json.Unmarshal(body, &payload)
+			messages = append(messages, payload.Messages)
+
+			content, _ := json.Marshal(replies[len(requests)-1])
+			return fakeResponse(http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":`+string(content)+`}}]}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+	client.EmulateTools = true
+
+	var city string
+	tool, err := ToolFromFunc("get_weather", "Get the weather for a city", func(args struct {
+		City string `json:"city"`
+	}) string {
+		city = args.City
+		return "sunny"
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	payload := &CompletionRequestPayload{
+		Model:    "o1-mini",
+		Messages: []Message{{Role: MessageRoleUser, Content: "What's the weather in Lisbon?"}},
+		Tools:    []ToolDefinition{tool},
+	}
+	message, err := client.GetCompletion(payload)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if message.Content != "It is sunny in Lisbon." || city != "Lisbon" {
+		t.Errorf("unexpected result %q, tool called with %q", message.Content, city)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	if _, ok := requests[0]["tools"]; ok {
+		t.Error("expected tools not to be sent")
+	}
+	if prompt := messages[0][0]; prompt.Role != MessageRoleSystem || !strings.Contains(prompt.Content, "- get_weather: Get the weather for a city") {
+		t.Errorf("unexpected tools prompt %+v", prompt)
+	}
+
+	second := messages[1]
+	if len(second) != 4 {
+		t.Fatalf("expected 4 messages, got %+v", second)
+	}
+	if second[2].Role != MessageRoleAssistant || second[2].Content != `{"tool":"get_weather","arguments":{"city":"Lisbon"}}` {
+		t.Errorf("unexpected rewritten tool call %+v", second[2])
+	}
+	if second[3].Role != MessageRoleUser || second[3].Content != "Result of tool get_weather:\nsunny" {
+		t.Errorf("unexpected rewritten tool result %+v", second[3])
+	}
+
+	if call := payload.Messages[1].ToolCalls; len(call) != 1 || call[0].Function.Arguments != `{"city": "Lisbon"}` {
+		t.Errorf("expected the history to keep a native tool call, got %+v", payload.Messages[1])
+	}
+}
+
+func TestParseEmulatedToolCall_FinalAnswer(t *testing.T) {
+	tools := map[string]*FunctionDefinition{"get_weather": {Name: "get_weather"}}
+
+	for _, content := range []string{"Plain answer", `{"answer": 42}`, `{"tool": "unknown_tool"}`} {
+		message := &Message{Role: MessageRoleAssistant, Content: content}
+		parseEmulatedToolCall(message, tools)
+		if message.Content != content || len(message.ToolCalls) != 0 {
+			t.Errorf("expected %q to be left as the final answer, got %+v", content, message)
+		}
+	}
+}
+
+func TestEmulatedPayload_History(t *testing.T) {
+	emulated := emulatedPayload(&CompletionRequestPayload{
+		Messages: []Message{
+			{Role: MessageRoleUser, Content: "Weather?"},
+			{Role: MessageRoleAssistant, Content: "Let me check.", ToolCalls: []ToolCall{
+				{Id: "call_1", Type: "function", Function: FunctionCall{Name: "get_weather", Arguments: `{"city": "Lis`}},
+			}},
+			{Role: MessageRoleTool, Content: "error", ToolCallId: "call_1"},
+		},
+		Tools: []ToolDefinition{NewToolDefinition(&FunctionDefinition{Name: "get_weather"})},
+	})
+
+	if len(emulated.Messages) != 5 || emulated.Messages[2].Content != "Let me check." {
+		t.Fatalf("expected the assistant content to be kept, got %+v", emulated.Messages)
+	}
+	var action emulatedToolCall
+	if err := json.Unmarshal([]byte(emulated.Messages[3].Content), &action); err != nil || string(action.Arguments) != `"{\"city\": \"Lis"` {
+		t.Errorf("expected the invalid arguments as a string, got %s", emulated.Messages[3].Content)
+	}
+}
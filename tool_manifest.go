@@ -0,0 +1,241 @@
+package openaiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const defaultToolTimeout = 30 * time.Second
+
+type (
+	// ToolManifest declares tools in a JSON file so they can be added without
+	// recompiling. Each tool is executed either by an HTTP request or by a
+	// local command.
+	ToolManifest struct {
+		Tools []ToolSpec `json:"tools"`
+	}
+
+	ToolSpec struct {
+		Name        string           `json:"name"`
+		Description string           `json:"description,omitempty"`
+		Parameters  *JsonSchema      `json:"parameters,omitempty"`
+		HTTP        *HTTPToolSpec    `json:"http,omitempty"`
+		Command     *CommandToolSpec `json:"command,omitempty"`
+	}
+
+	// HTTPToolSpec calls an endpoint with the tool arguments: as query
+	// parameters for GET requests and as a JSON body otherwise. Environment
+	// variables in the URL and headers are expanded, so secrets can stay out
+	// of the manifest.
+	HTTPToolSpec struct {
+		Method  string            `json:"method,omitempty"`
+		Url     string            `json:"url"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Timeout Duration          `json:"timeout,omitempty"`
+	}
+
+	// CommandToolSpec runs a local command with the tool arguments as JSON on
+	// stdin and returns its stdout.
+	CommandToolSpec struct {
+		Path    string   `json:"path"`
+		Args    []string `json:"args,omitempty"`
+		Dir     string   `json:"dir,omitempty"`
+		Timeout Duration `json:"timeout,omitempty"`
+	}
+
+	// Duration is a time.Duration read from strings such as "10s".
+	Duration time.Duration
+)
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string such as \"10s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// LoadTools reads a JSON tool manifest, either {"tools": [...]} or a bare
+// array of tools, and returns tool definitions ready to be used in a payload.
+func LoadTools(path string) ([]ToolDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tool manifest: %w", err)
+	}
+
+	var manifest ToolManifest
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(data, &manifest.Tools)
+	} else {
+		err = json.Unmarshal(data, &manifest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling tool manifest %s: %w", path, err)
+	}
+
+	return manifest.Definitions()
+}
+
+func (m *ToolManifest) Definitions() ([]ToolDefinition, error) {
+	tools := make([]ToolDefinition, 0, len(m.Tools))
+	for i := range m.Tools {
+		spec := &m.Tools[i]
+		fn, err := spec.executor()
+		if err != nil {
+			return nil, fmt.Errorf("tool %q: %w", spec.Name, err)
+		}
+		tools = append(tools, NewToolDefinition(&FunctionDefinition{
+			Name:        spec.Name,
+			Description: spec.Description,
+			Parameters:  spec.Parameters,
+			FnContext:   fn,
+		}))
+	}
+	return tools, nil
+}
+
+func (s *ToolSpec) executor() (LLMToolContext, error) {
+	switch {
+	case s.Name == "":
+		return nil, fmt.Errorf("missing name")
+	case s.HTTP != nil && s.Command != nil:
+		return nil, fmt.Errorf("only one of http and command can be set")
+	case s.HTTP != nil:
+		if s.HTTP.Url == "" {
+			return nil, fmt.Errorf("missing http url")
+		}
+		return s.HTTP.call, nil
+	case s.Command != nil:
+		if s.Command.Path == "" {
+			return nil, fmt.Errorf("missing command path")
+		}
+		return s.Command.run, nil
+	default:
+		return nil, fmt.Errorf("one of http or command must be set")
+	}
+}
+
+func (s *HTTPToolSpec) call(ctx context.Context, arguments string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeoutOrDefault(s.Timeout))
+	defer cancel()
+
+	method := strings.ToUpper(s.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	endpoint := os.ExpandEnv(s.Url)
+	var body io.Reader
+	if method == http.MethodGet {
+		query, err := argumentsQuery(arguments)
+		if err != nil {
+			return "", err
+		}
+		if len(query) > 0 {
+			separator := "?"
+			if strings.Contains(endpoint, "?") {
+				separator = "&"
+			}
+			endpoint += separator + query.Encode()
+		}
+	} else {
+		body = strings.NewReader(arguments)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return "", err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range s.Headers {
+		request.Header.Set(key, os.ExpandEnv(value))
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	responseText, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("request failed with status %d: %s", response.StatusCode, responseText)
+	}
+	return string(responseText), nil
+}
+
+func (s *CommandToolSpec) run(ctx context.Context, arguments string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeoutOrDefault(s.Timeout))
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.Path, s.Args...)
+	cmd.Dir = s.Dir
+	cmd.Stdin = strings.NewReader(arguments)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() > 0 {
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+func argumentsQuery(arguments string) (url.Values, error) {
+	query := url.Values{}
+	if strings.TrimSpace(arguments) == "" {
+		return query, nil
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal([]byte(arguments), &values); err != nil {
+		return nil, fmt.Errorf("error unmarshaling arguments: %w", err)
+	}
+	for key, value := range values {
+		if s, ok := value.(string); ok {
+			query.Set(key, s)
+			continue
+		}
+		encoded, _ := json.Marshal(value)
+		query.Set(key, string(encoded))
+	}
+	return query, nil
+}
+
+func toolError(err error) string {
+	result, _ := json.Marshal(ToolResult{Error: err.Error()})
+	return string(result)
+}
+
+func timeoutOrDefault(d Duration) time.Duration {
+	if d <= 0 {
+		return defaultToolTimeout
+	}
+	return time.Duration(d)
+}
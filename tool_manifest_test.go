@@ -0,0 +1,139 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeManifest(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tools.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return path
+}
+
+func TestLoadTools_HTTP(t *testing.T) {
+	t.Setenv("WEATHER_API_KEY", "secret")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" {
+			t.Errorf("expected expanded api key header, got '%s'", r.Header.Get("X-Api-Key"))
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"city":"` + r.URL.Query().Get("city") + `","temperature":21}`))
+		default:
+			body, _ := io.ReadAll(r.Body)
+			w.Write(body)
+		}
+	}))
+	defer server.Close()
+
+	path := writeManifest(t, `{
+		"tools": [
+			{
+				"name": "get_weather",
+				"description": "Get the weather for a city",
+				"parameters": {"type": "object", "properties": {"city": {"type": "string"}}, "required": ["city"]},
+				"http": {"method": "GET", "url": "`+server.URL+`/weather", "headers": {"X-Api-Key": "${WEATHER_API_KEY}"}, "timeout": "5s"}
+			},
+			{
+				"name": "echo",
+				"http": {"url": "`+server.URL+`/echo", "headers": {"X-Api-Key": "$WEATHER_API_KEY"}}
+			}
+		]
+	}`)
+
+	tools, err := LoadTools(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(tools) != 2 {
+		t.Fatalf("expected 2 tools, got %d", len(tools))
+	}
+
+	weather := tools[0].Function
+	if weather.Name != "get_weather" || weather.Parameters.Properties["city"].Type != "string" {
+		t.Errorf("unexpected definition %+v", weather)
+	}
+	if got := weather.call(context.Background(), `{"city":"Lisbon"}`); got != `{"city":"Lisbon","temperature":21}` {
+		t.Errorf("unexpected weather result %q", got)
+	}
+	if got := tools[1].Function.call(context.Background(), `{"text":"hi"}`); got != `{"text":"hi"}` {
+		t.Errorf("unexpected echo result %q", got)
+	}
+}
+
+func TestLoadTools_Command(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat is not available")
+	}
+
+	path := writeManifest(t, `[{"name": "echo", "command": {"path": "cat"}}, {"name": "fail", "command": {"path": "false"}}]`)
+
+	tools, err := LoadTools(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := tools[0].Function.call(context.Background(), `{"text":"hi"}`); got != `{"text":"hi"}` {
+		t.Errorf("unexpected echo result %q", got)
+	}
+
+	var result ToolResult
+	if err := json.Unmarshal([]byte(tools[1].Function.call(context.Background(), `{}`)), &result); err != nil || result.Error == "" {
+		t.Errorf("expected tool error result, got %+v (%v)", result, err)
+	}
+}
+
+func TestLoadTools_InvalidManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{name: "no executor", manifest: `[{"name": "noop"}]`, wantErr: "one of http or command"},
+		{name: "missing name", manifest: `[{"command": {"path": "cat"}}]`, wantErr: "missing name"},
+		{name: "invalid timeout", manifest: `[{"name": "x", "command": {"path": "cat", "timeout": 5}}]`, wantErr: "duration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadTools(writeManifest(t, tt.manifest))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadTools_HTTPUsesRequestContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	path := writeManifest(t, `{"tools": [{"name": "echo", "http": {"url": "`+server.URL+`"}}]}`)
+	tools, err := LoadTools(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var result ToolResult
+	json.Unmarshal([]byte(tools[0].Function.call(ctx, `{}`)), &result)
+	if !strings.Contains(result.Error, "context canceled") {
+		t.Errorf("expected canceled request, got %+v", result)
+	}
+}
@@ -0,0 +1,80 @@
+package openaiclient
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// ToolPruning sends only the tools most relevant to the last user message
+// when a request has more than TopK of them, which saves prompt tokens and
+// helps the model pick the right tool from large registries. Relevance is
+// the similarity of the embeddings of the message and of each tool's name
+// and description. It is safe for concurrent use.
+type ToolPruning struct {
+	// Model embeds the tools and the user message.
+	Model string
+	// TopK is the number of tools sent.
+	TopK int
+	// AlwaysInclude names tools sent regardless of their relevance, in
+	// addition to the TopK. The function forced by the payload's ToolChoice
+	// always is.
+	AlwaysInclude []string
+
+	// embeddings caches the embeddings of the tools, by name and
+	// description.
+	embeddings embeddingCache
+}
+
+// prune replaces the tools of the payload for the duration of a run, and
+// returns the function restoring them, so the caller's payload keeps every
+// tool for its next turns.
+func (p *ToolPruning) prune(ctx context.Context, client *OpenAI, payload *CompletionRequestPayload) (func(), error) {
+	if p == nil || p.TopK <= 0 || len(payload.Tools) <= p.TopK {
+		return func() {}, nil
+	}
+	query := lastUserContent(payload.Messages)
+	if query == "" {
+		return func() {}, nil
+	}
+
+	target, err := client.GetEmbeddingContext(ctx, GetEmbeddingPayload{Model: p.Model, Input: query})
+	if err != nil {
+		return nil, fmt.Errorf("error embedding message for tool pruning: %w", err)
+	}
+
+	type scored struct {
+		tool       ToolDefinition
+		similarity float64
+	}
+	var kept, candidates []scored
+	var texts []string
+	for _, tool := range payload.Tools {
+		if slices.Contains(p.AlwaysInclude, tool.Function.Name) ||
+			(payload.ToolChoice != nil && payload.ToolChoice.Function == tool.Function.Name) {
+			kept = append(kept, scored{tool: tool})
+			continue
+		}
+		candidates = append(candidates, scored{tool: tool})
+		texts = append(texts, tool.Function.Name+": "+tool.Function.Description)
+	}
+	embeddings, err := p.embeddings.get(ctx, client, p.Model, texts)
+	if err != nil {
+		return nil, fmt.Errorf("error embedding tools for pruning: %w", err)
+	}
+	for i := range candidates {
+		candidates[i].similarity = CosineSimilarity(target, embeddings[i])
+	}
+	slices.SortStableFunc(candidates, func(a, b scored) int {
+		return cmp.Compare(b.similarity, a.similarity)
+	})
+	kept = append(kept, candidates[:min(p.TopK, len(candidates))]...)
+
+	tools := payload.Tools
+	payload.Tools = make([]ToolDefinition, 0, len(kept))
+	for _, tool := range kept {
+		payload.Tools = append(payload.Tools, tool.tool)
+	}
+	return func() { payload.Tools = tools }, nil
+}
@@ -0,0 +1,84 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestToolPruning(t *testing.T) {
+	vectors := map[string]string{
+		"Book a flight to Lisbon":                   "[1,0,0]",
+		"search_flights: Search flights by route":   "[0.9,0.1,0]",
+		"book_hotel: Book a hotel room":             "[0.5,0.5,0]",
+		"get_weather: Get the weather for a city":   "[0,1,0]",
+		"convert_currency: Convert between amounts": "[0,0,1]",
+	}
+	var sent []CompletionRequestPayload
+	embeddings := 0
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			if req.URL.Path == embeddingsEndpoint {
+				embeddings++
+				var payload struct {
+					Input json.RawMessage `json:"input"`
+				}
+				json.Unmarshal(body, &payload)
+				var inputs []string
+				if json.Unmarshal(payload.Input, &inputs) != nil {
+					inputs = []string{""}
+					json.Unmarshal(payload.Input, &inputs[0])
+				}
+				var data []string
+				for i, input := range inputs {
+					data = append(data, fmt.Sprintf(`{"index":%d,"embedding":%s}`, i, vectors[input]))
+				}
+				return fakeResponse(http.StatusOK, fmt.Sprintf(`{"data":[%s]}`, strings.Join(data, ","))), nil
+			}
+			var payload CompletionRequestPayload
+			json.Unmarshal(body, &payload)
+			sent = append(sent, payload)
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+	client.ToolPruning = &ToolPruning{Model: "embedding-model", TopK: 2, AlwaysInclude: []string{"convert_currency"}}
+
+	tool := func(name, description string) ToolDefinition {
+		return NewToolDefinition(&FunctionDefinition{Name: name, Description: description, Fn: func(string) string { return "" }})
+	}
+	payload := &CompletionRequestPayload{
+		Model:    "test-model",
+		Messages: []Message{{Role: MessageRoleUser, Content: "Book a flight to Lisbon"}},
+		Tools: []ToolDefinition{
+			tool("get_weather", "Get the weather for a city"),
+			tool("book_hotel", "Book a hotel room"),
+			tool("convert_currency", "Convert between amounts"),
+			tool("search_flights", "Search flights by route"),
+		},
+	}
+	for range 2 {
+		if _, err := client.GetCompletionContext(context.Background(), payload); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	var names []string
+	for _, tool := range sent[0].Tools {
+		names = append(names, tool.Function.Name)
+	}
+	if fmt.Sprint(names) != "[convert_currency search_flights book_hotel]" {
+		t.Errorf("expected the always included and the two most relevant tools, got %v", names)
+	}
+	if len(payload.Tools) != 4 {
+		t.Errorf("expected the caller's tools to be restored, got %d", len(payload.Tools))
+	}
+	if embeddings != 3 {
+		t.Errorf("expected the tools to be embedded once in a batch, got %d embedding requests", embeddings)
+	}
+}
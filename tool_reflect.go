@@ -0,0 +1,254 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	errorType         = reflect.TypeFor[error]()
+	contextType       = reflect.TypeFor[context.Context]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// ToolFromFunc builds a tool from fn, a function taking a single struct (or
+// pointer to struct) argument, optionally preceded by a context.Context that
+// receives the context of the completion request. The struct's fields
+// describe the parameters: names come from json tags, descriptions from
+// `description:"..."` tags and allowed values from `enum:"a,b"` tags; fields
+// without omitempty are required. fn may return a string, any value to be
+// marshaled as JSON, or either of those followed by an error. Returned errors
+// are sent to the model as a ToolResult.
+func ToolFromFunc(name, description string, fn any) (ToolDefinition, error) {
+	fnValue := reflect.ValueOf(fn)
+	fnType := fnValue.Type()
+	if fnType.Kind() != reflect.Func {
+		return ToolDefinition{}, fmt.Errorf("tool %q: expected a function, got %s", name, fnType)
+	}
+	withContext := fnType.NumIn() == 2 && fnType.In(0) == contextType
+	if fnType.NumIn() != 1 && !withContext {
+		return ToolDefinition{}, fmt.Errorf("tool %q: function must take a single struct argument", name)
+	}
+	argType := fnType.In(fnType.NumIn() - 1)
+	if indirectType(argType).Kind() != reflect.Struct {
+		return ToolDefinition{}, fmt.Errorf("tool %q: function must take a single struct argument", name)
+	}
+	if err := checkToolResults(fnType); err != nil {
+		return ToolDefinition{}, fmt.Errorf("tool %q: %w", name, err)
+	}
+
+	call := func(ctx context.Context, arguments string) (string, error) {
+		arg := reflect.New(indirectType(argType))
+		if strings.TrimSpace(arguments) != "" {
+			if err := json.Unmarshal([]byte(arguments), arg.Interface()); err != nil {
+				return "", fmt.Errorf("error unmarshaling arguments: %w", err)
+			}
+		}
+		if argType.Kind() != reflect.Pointer {
+			arg = arg.Elem()
+		}
+
+		args := []reflect.Value{arg}
+		if withContext {
+			args = []reflect.Value{reflect.ValueOf(&ctx).Elem(), arg}
+		}
+		return toolOutput(fnValue.Call(args))
+	}
+
+	return NewToolDefinition(&FunctionDefinition{
+		Name:        name,
+		Description: description,
+		Parameters:  jsonSchemaFor(indirectType(argType)),
+		FnContext:   call,
+	}), nil
+}
+
+// NewTypedTool builds a tool from fn, whose argument T is a struct described
+// and unmarshaled the way ToolFromFunc does it, but checked at compile time.
+// Errors returned by fn or met unmarshaling the arguments are sent to the
+// model as a ToolResult. It panics when T is not a struct or pointer to
+// struct, as the parameters of a tool must be an object.
+func NewTypedTool[T any](name, description string, fn func(T) (string, error)) ToolDefinition {
+	argType := reflect.TypeFor[T]()
+	if indirectType(argType).Kind() != reflect.Struct {
+		panic(fmt.Sprintf("tool %q: argument type %s is not a struct", name, argType))
+	}
+
+	return NewToolDefinition(&FunctionDefinition{
+		Name:        name,
+		Description: description,
+		Parameters:  jsonSchemaFor(argType),
+		FnContext: func(ctx context.Context, arguments string) (string, error) {
+			var args T
+			if strings.TrimSpace(arguments) != "" {
+				if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+					return "", fmt.Errorf("error unmarshaling arguments: %w", err)
+				}
+			}
+			return fn(args)
+		},
+	})
+}
+
+func checkToolResults(fnType reflect.Type) error {
+	switch fnType.NumOut() {
+	case 1:
+		return nil
+	case 2:
+		if fnType.Out(1) != errorType {
+			return fmt.Errorf("second return value must be an error")
+		}
+		return nil
+	default:
+		return fmt.Errorf("function must return a value, optionally followed by an error")
+	}
+}
+
+func toolOutput(results []reflect.Value) (string, error) {
+	if len(results) == 2 && !results[1].IsNil() {
+		return "", results[1].Interface().(error)
+	}
+	if results[0].Type() == errorType {
+		if results[0].IsNil() {
+			return "", nil
+		}
+		return "", results[0].Interface().(error)
+	}
+
+	if results[0].Kind() == reflect.String {
+		return results[0].String(), nil
+	}
+	output, err := json.Marshal(results[0].Interface())
+	if err != nil {
+		return "", fmt.Errorf("error marshaling result: %w", err)
+	}
+	return string(output), nil
+}
+
+// schemaCache holds the schema of every type described so far. The cached
+// schemas are never handed out, so callers cannot modify them.
+var schemaCache sync.Map
+
+// jsonSchemaFor describes t as a JSON schema, following the field naming
+// rules of encoding/json. Recursive types are described as plain objects
+// where they refer to themselves. Each type is described once per process,
+// so tools built on every request only pay for a copy of the schema.
+func jsonSchemaFor(t reflect.Type) *JsonSchema {
+	if cached, ok := schemaCache.Load(t); ok {
+		return cached.(*JsonSchema).clone()
+	}
+	schema := schemaFor(t, map[reflect.Type]bool{})
+	schemaCache.Store(t, schema)
+	return schema.clone()
+}
+
+func schemaFor(t reflect.Type, seen map[reflect.Type]bool) *JsonSchema {
+	t = indirectType(t)
+
+	// Types marshaling themselves have a shape reflection cannot see. Text
+	// marshalers, such as time.Time, are strings; other JSON marshalers are
+	// left unconstrained.
+	switch {
+	case implements(t, textMarshalerType):
+		return &JsonSchema{Type: "string"}
+	case implements(t, jsonMarshalerType):
+		return &JsonSchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &JsonSchema{Type: "string"}
+	case reflect.Bool:
+		return &JsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JsonSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &JsonSchema{Type: "array", Items: schemaFor(t.Elem(), seen)}
+	case reflect.Map:
+		return &JsonSchema{Type: "object"}
+	case reflect.Struct:
+		if seen[t] {
+			return &JsonSchema{Type: "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		schema := &JsonSchema{Type: "object", Properties: JsonSchemaProperties{}}
+		addStructFields(schema, t, seen)
+		return schema
+	default:
+		return &JsonSchema{}
+	}
+}
+
+func addStructFields(schema *JsonSchema, t reflect.Type, seen map[reflect.Type]bool) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		if field.Anonymous && name == "" && indirectType(field.Type).Kind() == reflect.Struct {
+			addStructFields(schema, indirectType(field.Type), seen)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := schemaFor(field.Type, seen)
+		property.Description = field.Tag.Get("description")
+		if enum := field.Tag.Get("enum"); enum != "" {
+			property.Enum = strings.Split(enum, ",")
+		}
+		schema.Properties[name] = property
+
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// implements reports whether t or a pointer to t implements the interface.
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// clone returns a deep copy of the schema.
+func (s *JsonSchema) clone() *JsonSchema {
+	if s == nil {
+		return nil
+	}
+	copied := *s
+	copied.Required = slices.Clone(s.Required)
+	copied.Enum = slices.Clone(s.Enum)
+	copied.Items = s.Items.clone()
+	if s.Properties != nil {
+		copied.Properties = make(JsonSchemaProperties, len(s.Properties))
+		for name, property := range s.Properties {
+			copied.Properties[name] = property.clone()
+		}
+	}
+	return &copied
+}
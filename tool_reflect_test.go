@@ -0,0 +1,237 @@
+package openaiclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type weatherArgs struct {
+	City    string   `json:"city" description:"City name"`
+	Unit    string   `json:"unit,omitempty" enum:"celsius,fahrenheit"`
+	Days    int      `json:"days,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	ignored string
+}
+
+type treeNode struct {
+	Name     string     `json:"name"`
+	Children []treeNode `json:"children,omitempty"`
+}
+
+func TestToolFromFunc_Schema(t *testing.T) {
+	tool, err := ToolFromFunc("get_weather", "Get the weather", func(args weatherArgs) (string, error) {
+		return "", nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	schema := tool.Function.Parameters
+	if schema.Type != "object" || len(schema.Properties) != 4 {
+		t.Fatalf("unexpected schema %+v", schema)
+	}
+	if city := schema.Properties["city"]; city.Type != "string" || city.Description != "City name" {
+		t.Errorf("unexpected city property %+v", city)
+	}
+	if unit := schema.Properties["unit"]; !slices.Equal(unit.Enum, []string{"celsius", "fahrenheit"}) {
+		t.Errorf("unexpected unit enum %v", unit.Enum)
+	}
+	if days := schema.Properties["days"]; days.Type != "integer" {
+		t.Errorf("expected integer days, got %q", days.Type)
+	}
+	if tags := schema.Properties["tags"]; tags.Type != "array" || tags.Items.Type != "string" {
+		t.Errorf("unexpected tags property %+v", tags)
+	}
+	if !slices.Equal(schema.Required, []string{"city"}) {
+		t.Errorf("expected only city to be required, got %v", schema.Required)
+	}
+}
+
+func TestToolFromFunc_RecursiveType(t *testing.T) {
+	tool, err := ToolFromFunc("tree", "", func(node *treeNode) string { return node.Name })
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	children := tool.Function.Parameters.Properties["children"]
+	if children.Type != "array" || children.Items.Type != "object" || children.Items.Properties != nil {
+		t.Errorf("unexpected recursive property %+v", children)
+	}
+	if got := tool.Function.call(context.Background(), `{"name":"root"}`); got != "root" {
+		t.Errorf("expected 'root', got %q", got)
+	}
+}
+
+func TestToolFromFunc_Call(t *testing.T) {
+	tool, err := ToolFromFunc("get_weather", "", func(args weatherArgs) (map[string]any, error) {
+		if args.City == "" {
+			return nil, errors.New("city is required")
+		}
+		return map[string]any{"city": args.City, "days": args.Days}, nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got := tool.Function.call(context.Background(), `{"city":"Lisbon","days":3}`); got != `{"city":"Lisbon","days":3}` {
+		t.Errorf("unexpected result %q", got)
+	}
+
+	var result ToolResult
+	json.Unmarshal([]byte(tool.Function.call(context.Background(), `{}`)), &result)
+	if result.Error != "city is required" {
+		t.Errorf("expected tool error, got %+v", result)
+	}
+
+	json.Unmarshal([]byte(tool.Function.call(context.Background(), `not json`)), &result)
+	if result.Error == "" {
+		t.Errorf("expected unmarshal error, got %+v", result)
+	}
+}
+
+func TestToolFromFunc_Marshalers(t *testing.T) {
+	type status string
+	type args struct {
+		Since time.Time       `json:"since"`
+		Raw   json.RawMessage `json:"raw"`
+	}
+	tool, err := ToolFromFunc("status", "", func(args args) status { return "ok" })
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	schema := tool.Function.Parameters
+	if since := schema.Properties["since"]; since.Type != "string" {
+		t.Errorf("expected a text marshaler to be a string, got %+v", since)
+	}
+	if raw := schema.Properties["raw"]; raw.Type != "" {
+		t.Errorf("expected a JSON marshaler to be unconstrained, got %+v", raw)
+	}
+	if got := tool.Function.call(context.Background(), `{}`); got != "ok" {
+		t.Errorf("expected a named string result unquoted, got %q", got)
+	}
+}
+
+func TestNewTypedTool(t *testing.T) {
+	tool := NewTypedTool("get_weather", "Get the weather", func(args weatherArgs) (string, error) {
+		if args.City == "" {
+			return "", errors.New("city is required")
+		}
+		return fmt.Sprintf("%s for %d days", args.City, args.Days), nil
+	})
+
+	expected := jsonSchemaFor(reflect.TypeFor[weatherArgs]())
+	if !reflect.DeepEqual(tool.Function.Parameters, expected) {
+		t.Errorf("expected the schema of the arguments, got %+v", tool.Function.Parameters)
+	}
+	if got := tool.Function.call(context.Background(), `{"city":"Lisbon","days":3}`); got != "Lisbon for 3 days" {
+		t.Errorf("unexpected result %q", got)
+	}
+
+	var result ToolResult
+	json.Unmarshal([]byte(tool.Function.call(context.Background(), `{}`)), &result)
+	if result.Error != "city is required" {
+		t.Errorf("expected tool error, got %+v", result)
+	}
+
+	json.Unmarshal([]byte(tool.Function.call(context.Background(), `{"days":"three"}`)), &result)
+	if !strings.Contains(result.Error, "error unmarshaling arguments") {
+		t.Errorf("expected unmarshal error, got %+v", result)
+	}
+}
+
+func TestNewTypedTool_NonStructArgument(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected a panic for a non struct argument")
+		}
+	}()
+	NewTypedTool("echo", "", func(s string) (string, error) { return s, nil })
+}
+
+func TestToolFromFunc_InvalidFunctions(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   any
+	}{
+		{name: "not a function", fn: "nope"},
+		{name: "no arguments", fn: func() string { return "" }},
+		{name: "non struct argument", fn: func(s string) string { return s }},
+		{name: "no results", fn: func(args weatherArgs) {}},
+		{name: "non error second result", fn: func(args weatherArgs) (string, string) { return "", "" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ToolFromFunc("tool", "", tt.fn); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestToolFromFunc_Context(t *testing.T) {
+	tool, err := ToolFromFunc("whoami", "", func(ctx context.Context, args struct{}) string {
+		return UserId(ctx)
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got := tool.Function.call(WithUserId(context.Background(), "user-1"), `{}`); got != "user-1" {
+		t.Errorf("expected 'user-1', got %q", got)
+	}
+	if got := tool.Function.call(context.Background(), `{}`); got != "" {
+		t.Errorf("expected no user without a request context, got %q", got)
+	}
+}
+
+func TestJsonSchemaFor_CachedSchemaIsCopied(t *testing.T) {
+	schema := jsonSchemaFor(reflect.TypeFor[weatherArgs]())
+	schema.Properties["city"].Description = "changed"
+	schema.Required = append(schema.Required[:0], "changed")
+
+	again := jsonSchemaFor(reflect.TypeFor[weatherArgs]())
+	if again.Properties["city"].Description != "City name" || again.Required[0] != "city" {
+		t.Errorf("expected the cached schema to be left untouched, got %+v", again)
+	}
+}
+
+// toolArgTypes returns distinct argument types for a payload of dozens of
+// tools.
+func toolArgTypes() []reflect.Type {
+	types := make([]reflect.Type, 36)
+	for i := range types {
+		types[i] = reflect.StructOf([]reflect.StructField{
+			{Name: "Query", Type: reflect.TypeFor[string](), Tag: reflect.StructTag(fmt.Sprintf(`json:"query_%d" description:"What to look up"`, i))},
+			{Name: "Limit", Type: reflect.TypeFor[int](), Tag: `json:"limit,omitempty"`},
+			{Name: "Filters", Type: reflect.TypeFor[[]weatherArgs](), Tag: `json:"filters,omitempty"`},
+		})
+	}
+	return types
+}
+
+func BenchmarkToolSchemas_Cached(b *testing.B) {
+	types := toolArgTypes()
+	for b.Loop() {
+		for _, t := range types {
+			jsonSchemaFor(t)
+		}
+	}
+}
+
+func BenchmarkToolSchemas_Uncached(b *testing.B) {
+	types := toolArgTypes()
+	for b.Loop() {
+		for _, t := range types {
+			schemaFor(t, map[reflect.Type]bool{})
+		}
+	}
+}
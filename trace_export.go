@@ -0,0 +1,344 @@
+package openaiclient
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	ObservationGeneration = "generation"
+	ObservationTool       = "tool"
+)
+
+type (
+	// RunTrace is an agent run rebuilt from its RunLog events: the model
+	// generations and tool calls of every iteration, with their latencies and
+	// usage.
+	RunTrace struct {
+		Id            string
+		CorrelationId string
+		PromptHash    string
+		Start         time.Time
+		End           time.Time
+		Input         []Message
+		Output        *Message
+		Usage         LLMUsage
+		Error         string
+		Observations  []TraceObservation
+	}
+
+	// TraceObservation is a model generation or a tool call of a RunTrace.
+	TraceObservation struct {
+		Id        string
+		Kind      string
+		Name      string
+		Iteration int
+		Model     string
+		Start     time.Time
+		End       time.Time
+		Input     any
+		Output    any
+		Usage     *LLMUsage
+		Error     string
+
+		toolCallId string
+	}
+
+	// LangfuseEvent is an event of the Langfuse ingestion API. A trace is sent
+	// as a batch of them: {"batch": events}.
+	LangfuseEvent struct {
+		Id        string    `json:"id"`
+		Timestamp time.Time `json:"timestamp"`
+		Type      string    `json:"type"`
+		Body      any       `json:"body"`
+	}
+
+	langfuseTrace struct {
+		Id        string    `json:"id"`
+		Name      string    `json:"name"`
+		Timestamp time.Time `json:"timestamp"`
+		Version   string    `json:"version,omitempty"`
+		Input     any       `json:"input,omitempty"`
+		Output    any       `json:"output,omitempty"`
+		Metadata  any       `json:"metadata,omitempty"`
+	}
+
+	langfuseObservation struct {
+		Id            string         `json:"id"`
+		TraceId       string         `json:"traceId"`
+		Name          string         `json:"name"`
+		StartTime     time.Time      `json:"startTime"`
+		EndTime       *time.Time     `json:"endTime,omitempty"`
+		Model         string         `json:"model,omitempty"`
+		Input         any            `json:"input,omitempty"`
+		Output        any            `json:"output,omitempty"`
+		Usage         *langfuseUsage `json:"usage,omitempty"`
+		Level         string         `json:"level,omitempty"`
+		StatusMessage string         `json:"statusMessage,omitempty"`
+	}
+
+	langfuseUsage struct {
+		Input  int    `json:"input"`
+		Output int    `json:"output"`
+		Total  int    `json:"total"`
+		Unit   string `json:"unit"`
+	}
+
+	// LangSmithRun is a run of the LangSmith runs API. A trace is a chain run
+	// with an llm or tool child run per observation.
+	LangSmithRun struct {
+		Id          string         `json:"id"`
+		TraceId     string         `json:"trace_id"`
+		ParentRunId string         `json:"parent_run_id,omitempty"`
+		DottedOrder string         `json:"dotted_order"`
+		Name        string         `json:"name"`
+		RunType     string         `json:"run_type"`
+		StartTime   time.Time      `json:"start_time"`
+		EndTime     *time.Time     `json:"end_time,omitempty"`
+		Inputs      map[string]any `json:"inputs"`
+		Outputs     map[string]any `json:"outputs,omitempty"`
+		Error       string         `json:"error,omitempty"`
+		Extra       map[string]any `json:"extra,omitempty"`
+	}
+)
+
+// ReadRunLog decodes the events written by a RunLog.
+func ReadRunLog(r io.Reader) ([]RunEvent, error) {
+	var events []RunEvent
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64<<20)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event RunEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("error decoding run event %d: %w", len(events)+1, err)
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
+
+// BuildTraces groups run events into a trace per run. Events are matched to
+// their run by correlation id, and a request of the first iteration starts a
+// new run, so runs sharing a correlation id get a trace each.
+func BuildTraces(events []RunEvent) []*RunTrace {
+	var traces []*RunTrace
+	current := map[string]*RunTrace{}
+	runs := map[string]int{}
+
+	for _, event := range events {
+		trace := current[event.CorrelationId]
+		if trace == nil || (event.Type == RunEventRequest && event.Iteration == 0) {
+			runs[event.CorrelationId]++
+			trace = &RunTrace{
+				Id:            traceUUID(fmt.Sprintf("%s/%d", event.CorrelationId, runs[event.CorrelationId])),
+				CorrelationId: event.CorrelationId,
+				Start:         event.Time,
+			}
+			current[event.CorrelationId] = trace
+			traces = append(traces, trace)
+		}
+		trace.add(event)
+	}
+	return traces
+}
+
+func (t *RunTrace) add(event RunEvent) {
+	t.End = event.Time
+
+	switch event.Type {
+	case RunEventRequest:
+		if event.Iteration == 0 {
+			t.Input = event.Input
+			t.PromptHash = event.PromptHash
+		}
+		t.Observations = append(t.Observations, TraceObservation{
+			Id:        traceUUID(fmt.Sprintf("%s/generation/%d", t.Id, event.Iteration)),
+			Kind:      ObservationGeneration,
+			Name:      "completion",
+			Iteration: event.Iteration,
+			Model:     event.Model,
+			Start:     event.Time,
+			Input:     event.Input,
+		})
+	case RunEventResponse:
+		observation := t.open(ObservationGeneration, "")
+		if observation == nil {
+			return
+		}
+		observation.End = event.Time
+		observation.Output = event.Message
+		observation.Usage = event.Usage
+		if event.Model != "" {
+			observation.Model = event.Model
+		}
+		if event.Usage != nil {
+			t.Usage = addUsage(t.Usage, *event.Usage)
+		}
+		if event.Message != nil && len(event.Message.ToolCalls) == 0 {
+			t.Output = event.Message
+		}
+	case RunEventToolCall:
+		t.Observations = append(t.Observations, TraceObservation{
+			Id:        traceUUID(fmt.Sprintf("%s/tool/%d/%s", t.Id, event.Iteration, event.ToolCallId)),
+			Kind:      ObservationTool,
+			Name:      event.ToolName,
+			Iteration: event.Iteration,
+			Start:     event.Time,
+			Input:     event.Arguments,
+
+			toolCallId: event.ToolCallId,
+		})
+	case RunEventToolResult:
+		if observation := t.open(ObservationTool, event.ToolCallId); observation != nil {
+			observation.End = event.Time
+			observation.Output = event.Result
+		}
+	case RunEventError:
+		t.Error = event.Error
+		if observation := t.open(ObservationGeneration, ""); observation != nil {
+			observation.End = event.Time
+			observation.Error = event.Error
+		}
+	}
+}
+
+// open returns the last observation of the kind that has not ended yet.
+func (t *RunTrace) open(kind, toolCallId string) *TraceObservation {
+	for i := len(t.Observations) - 1; i >= 0; i-- {
+		observation := &t.Observations[i]
+		if observation.Kind != kind || !observation.End.IsZero() {
+			continue
+		}
+		if observation.toolCallId == toolCallId {
+			return observation
+		}
+	}
+	return nil
+}
+
+// Langfuse converts the trace into Langfuse ingestion events.
+func (t *RunTrace) Langfuse() []LangfuseEvent {
+	events := []LangfuseEvent{{
+		Id:        traceUUID(t.Id + "/trace-create"),
+		Timestamp: t.Start,
+		Type:      "trace-create",
+		Body: langfuseTrace{
+			Id:        t.Id,
+			Name:      "completion",
+			Timestamp: t.Start,
+			Version:   t.PromptHash,
+			Input:     t.Input,
+			Output:    t.Output,
+			Metadata:  map[string]any{"correlationId": t.CorrelationId, "promptHash": t.PromptHash},
+		},
+	}}
+
+	for _, observation := range t.Observations {
+		body := langfuseObservation{
+			Id:        observation.Id,
+			TraceId:   t.Id,
+			Name:      observation.Name,
+			StartTime: observation.Start,
+			EndTime:   endTime(observation.End),
+			Model:     observation.Model,
+			Input:     observation.Input,
+			Output:    observation.Output,
+		}
+		if observation.Usage != nil {
+			body.Usage = &langfuseUsage{
+				Input:  observation.Usage.PromptTokens,
+				Output: observation.Usage.CompletionTokens,
+				Total:  observation.Usage.TotalTokens,
+				Unit:   "TOKENS",
+			}
+		}
+		if observation.Error != "" {
+			body.Level, body.StatusMessage = "ERROR", observation.Error
+		}
+
+		eventType := "generation-create"
+		if observation.Kind == ObservationTool {
+			eventType = "span-create"
+		}
+		events = append(events, LangfuseEvent{
+			Id:        traceUUID(observation.Id + "/" + eventType),
+			Timestamp: observation.Start,
+			Type:      eventType,
+			Body:      body,
+		})
+	}
+	return events
+}
+
+// LangSmith converts the trace into LangSmith runs, the root run first.
+func (t *RunTrace) LangSmith() []LangSmithRun {
+	rootOrder := dottedOrder(t.Start, t.Id)
+	runs := []LangSmithRun{{
+		Id:          t.Id,
+		TraceId:     t.Id,
+		DottedOrder: rootOrder,
+		Name:        "completion",
+		RunType:     "chain",
+		StartTime:   t.Start,
+		EndTime:     endTime(t.End),
+		Inputs:      map[string]any{"messages": t.Input},
+		Outputs:     map[string]any{"output": t.Output},
+		Error:       t.Error,
+		Extra:       map[string]any{"metadata": map[string]any{"correlation_id": t.CorrelationId, "prompt_hash": t.PromptHash}},
+	}}
+
+	for _, observation := range t.Observations {
+		run := LangSmithRun{
+			Id:          observation.Id,
+			TraceId:     t.Id,
+			ParentRunId: t.Id,
+			DottedOrder: rootOrder + "." + dottedOrder(observation.Start, observation.Id),
+			Name:        observation.Name,
+			RunType:     "tool",
+			StartTime:   observation.Start,
+			EndTime:     endTime(observation.End),
+			Inputs:      map[string]any{"input": observation.Input},
+			Outputs:     map[string]any{"output": observation.Output},
+			Error:       observation.Error,
+		}
+		if observation.Kind == ObservationGeneration {
+			run.RunType = "llm"
+			run.Inputs = map[string]any{"messages": observation.Input}
+			run.Extra = map[string]any{"invocation_params": map[string]any{"model": observation.Model}}
+			if observation.Usage != nil {
+				run.Outputs["usage"] = observation.Usage
+			}
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+func endTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// dottedOrder is the position of a run in its LangSmith trace.
+func dottedOrder(start time.Time, id string) string {
+	start = start.UTC()
+	return fmt.Sprintf("%s%06dZ%s", start.Format("20060102T150405"), start.Nanosecond()/1000, id)
+}
+
+// traceUUID derives a stable UUID from name, as the platforms require UUID
+// ids and the exports of a run must not create duplicates when resent.
+func traceUUID(name string) string {
+	sum := sha1.Sum([]byte(name))
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
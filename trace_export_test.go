@@ -0,0 +1,119 @@
+package openaiclient
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func recordRun(t *testing.T, ctx context.Context, client *OpenAI, buffer *bytes.Buffer) {
+	t.Helper()
+	client.client = &SequentialFakeClient{
+		Responses: []*http.Response{
+			fakeResponse(http.StatusOK, `{"model":"test-model","choices":[{"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"echo","arguments":"{}"}}]}}],"usage":{"prompt_tokens":10,"completion_tokens":2,"total_tokens":12}}`),
+			fakeResponse(http.StatusOK, `{"model":"test-model","choices":[{"message":{"role":"assistant","content":"done"}}],"usage":{"prompt_tokens":15,"completion_tokens":1,"total_tokens":16}}`),
+		},
+	}
+	client.RunLog = NewRunLog(buffer)
+
+	_, err := client.GetCompletionContext(ctx, &CompletionRequestPayload{
+		Model:    "test-model",
+		Messages: []Message{{Role: "user", Content: "Hi"}},
+		Tools: []ToolDefinition{NewToolDefinition(&FunctionDefinition{
+			Name: "echo",
+			Fn:   func(args string) string { return args },
+		})},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestBuildTraces(t *testing.T) {
+	var buffer bytes.Buffer
+	client := createClient(t)
+	ctx := WithCorrelationId(context.Background(), "run-1")
+	recordRun(t, ctx, client, &buffer)
+	recordRun(t, ctx, client, &buffer)
+
+	events, err := ReadRunLog(&buffer)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	traces := BuildTraces(events)
+	if len(traces) != 2 || traces[0].Id == traces[1].Id {
+		t.Fatalf("expected a trace per run, got %d", len(traces))
+	}
+
+	trace := traces[0]
+	if trace.CorrelationId != "run-1" || len(trace.Input) != 1 || trace.Output == nil || trace.Output.Content != "done" {
+		t.Errorf("expected the run's input and output, got %+v", trace)
+	}
+	if trace.Usage.TotalTokens != 28 {
+		t.Errorf("expected the usage of both generations, got %d", trace.Usage.TotalTokens)
+	}
+
+	kinds := []string{ObservationGeneration, ObservationTool, ObservationGeneration}
+	if len(trace.Observations) != len(kinds) {
+		t.Fatalf("expected %d observations, got %+v", len(kinds), trace.Observations)
+	}
+	for i, observation := range trace.Observations {
+		if observation.Kind != kinds[i] || observation.End.Before(observation.Start) || observation.End.IsZero() {
+			t.Errorf("observation %d: expected an ended %s, got %+v", i, kinds[i], observation)
+		}
+	}
+	if trace.Observations[1].Name != "echo" || trace.Observations[1].Output != "{}" {
+		t.Errorf("expected the tool call, got %+v", trace.Observations[1])
+	}
+}
+
+func TestRunTrace_Exports(t *testing.T) {
+	var buffer bytes.Buffer
+	recordRun(t, WithCorrelationId(context.Background(), "run-1"), createClient(t), &buffer)
+	events, _ := ReadRunLog(&buffer)
+	trace := BuildTraces(events)[0]
+
+	langfuse := trace.Langfuse()
+	types := []string{"trace-create", "generation-create", "span-create", "generation-create"}
+	if len(langfuse) != len(types) {
+		t.Fatalf("expected %d Langfuse events, got %d", len(types), len(langfuse))
+	}
+	for i, event := range langfuse {
+		if event.Type != types[i] {
+			t.Errorf("event %d: expected %s, got %s", i, types[i], event.Type)
+		}
+	}
+	if body := langfuse[1].Body.(langfuseObservation); body.TraceId != trace.Id || body.Usage == nil || body.Usage.Total != 12 {
+		t.Errorf("expected the generation with its usage, got %+v", body)
+	}
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	runs := trace.LangSmith()
+	if len(runs) != 4 || runs[0].RunType != "chain" || runs[1].RunType != "llm" || runs[2].RunType != "tool" {
+		t.Fatalf("expected a chain run with llm and tool children, got %+v", runs)
+	}
+	for _, run := range runs {
+		if !uuid.MatchString(run.Id) {
+			t.Errorf("expected a UUID run id, got %q", run.Id)
+		}
+	}
+	if runs[1].ParentRunId != runs[0].Id || !regexp.MustCompile(`^\d{8}T\d{12}Z`+runs[0].Id+`\.\d{8}T\d{12}Z`+runs[1].Id+`$`).MatchString(runs[1].DottedOrder) {
+		t.Errorf("expected the generation under the root run, got %+v", runs[1])
+	}
+}
+
+func TestBuildTraces_PromptHash(t *testing.T) {
+	var buffer bytes.Buffer
+	recordRun(t, context.Background(), createClient(t), &buffer)
+	events, _ := ReadRunLog(&buffer)
+	trace := BuildTraces(events)[0]
+
+	if trace.PromptHash == "" || trace.PromptHash != events[0].PromptHash {
+		t.Fatalf("expected the prompt hash of the first request, got %q", trace.PromptHash)
+	}
+	if body := trace.Langfuse()[0].Body.(langfuseTrace); body.Version != trace.PromptHash {
+		t.Errorf("expected the prompt hash as the trace version, got %q", body.Version)
+	}
+}
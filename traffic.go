@@ -0,0 +1,108 @@
+package openaiclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+type (
+	// TrafficKey groups the traffic of a TrafficTracker. Model is empty for
+	// requests that do not name one, and Tenant when the request context
+	// carries no tenant, see WithTenant.
+	TrafficKey struct {
+		Model  string
+		Tenant string
+	}
+
+	// Traffic is the number of HTTP requests sent and the size of their
+	// bodies. Retries and hedged requests count as requests of their own.
+	Traffic struct {
+		Requests      int
+		RequestBytes  int64
+		ResponseBytes int64
+	}
+
+	// TrafficTracker accumulates the bytes a client sends and receives, for
+	// bandwidth and egress cost accounting. It is safe for concurrent use
+	// and can be shared by several clients, such as those of a ClientPool.
+	TrafficTracker struct {
+		mu    sync.Mutex
+		byKey map[TrafficKey]Traffic
+	}
+
+	requestModelKey struct{}
+)
+
+// Total returns the traffic accumulated so far.
+func (t *TrafficTracker) Total() Traffic {
+	var total Traffic
+	for _, traffic := range t.ByKey() {
+		total = addTraffic(total, traffic)
+	}
+	return total
+}
+
+// ByKey returns the traffic accumulated so far for each model and tenant.
+func (t *TrafficTracker) ByKey() map[TrafficKey]Traffic {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	byKey := make(map[TrafficKey]Traffic, len(t.byKey))
+	for key, traffic := range t.byKey {
+		byKey[key] = traffic
+	}
+	return byKey
+}
+
+func (t *TrafficTracker) add(request *http.Request, responseBytes int) {
+	if t == nil {
+		return
+	}
+	ctx := request.Context()
+	key := TrafficKey{Model: requestModel(ctx), Tenant: Tenant(ctx)}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.byKey == nil {
+		t.byKey = map[TrafficKey]Traffic{}
+	}
+	t.byKey[key] = addTraffic(t.byKey[key], Traffic{
+		Requests:      1,
+		RequestBytes:  max(request.ContentLength, 0),
+		ResponseBytes: int64(responseBytes),
+	})
+}
+
+// withRequestModel tags the context of a request with the model named by
+// its body, if any.
+func withRequestModel(ctx context.Context, body any) context.Context {
+	var model string
+	switch body := body.(type) {
+	case *CompletionRequestPayload:
+		model = body.Model
+	case GetEmbeddingPayload:
+		model = body.Model
+	}
+	if model == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestModelKey{}, model)
+}
+
+// requestModel returns the model a request was tagged with, if any.
+func requestModel(ctx context.Context) string {
+	model, _ := ctx.Value(requestModelKey{}).(string)
+	return model
+}
+
+func addTraffic(a, b Traffic) Traffic {
+	return Traffic{
+		Requests:      a.Requests + b.Requests,
+		RequestBytes:  a.RequestBytes + b.RequestBytes,
+		ResponseBytes: a.ResponseBytes + b.ResponseBytes,
+	}
+}
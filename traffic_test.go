@@ -0,0 +1,45 @@
+package openaiclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestTrafficTracker(t *testing.T) {
+	var sent int64
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			sent += int64(len(body))
+			if req.URL.Path == embeddingsEndpoint {
+				return fakeResponse(http.StatusOK, `{"data":[{"embedding":[0.1]}]}`), nil
+			}
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+	client.Traffic = &TrafficTracker{}
+
+	ctx := WithTenant(context.Background(), "acme")
+	if _, err := client.GetCompletionContext(ctx, &CompletionRequestPayload{Model: "test-model", Messages: []Message{{Role: "user", Content: "Hi"}}}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.GetEmbeddingContext(context.Background(), GetEmbeddingPayload{Model: "embedding-model", Input: "Hi"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	byKey := client.Traffic.ByKey()
+	completion := byKey[TrafficKey{Model: "test-model", Tenant: "acme"}]
+	if completion.Requests != 1 || completion.ResponseBytes != int64(len(completionBody)) {
+		t.Errorf("expected the completion traffic of the tenant, got %+v", completion)
+	}
+	embedding := byKey[TrafficKey{Model: "embedding-model"}]
+	if embedding.Requests != 1 || embedding.ResponseBytes != int64(len(`{"data":[{"embedding":[0.1]}]}`)) {
+		t.Errorf("expected the embedding traffic, got %+v", embedding)
+	}
+	if total := client.Traffic.Total(); total.Requests != 2 || total.RequestBytes != sent {
+		t.Errorf("expected %d request bytes in total, got %+v", sent, total)
+	}
+}
@@ -0,0 +1,134 @@
+package openaiclient
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	BucketWidthMinute = "1m"
+	BucketWidthHour   = "1h"
+	BucketWidthDay    = "1d"
+)
+
+type (
+	// UsageParams filters the organization usage and costs endpoints. Not every
+	// filter is supported by every endpoint; see the OpenAI API reference.
+	UsageParams struct {
+		StartTime   time.Time
+		EndTime     time.Time
+		BucketWidth string
+		ProjectIds  []string
+		UserIds     []string
+		ApiKeyIds   []string
+		Models      []string
+		Batch       *bool
+		GroupBy     []string
+		Limit       int
+		Page        string
+	}
+
+	UsageBucket[T any] struct {
+		Object    string `json:"object"`
+		StartTime int64  `json:"start_time"`
+		EndTime   int64  `json:"end_time"`
+		Results   []T    `json:"results"`
+	}
+
+	UsagePage[T any] struct {
+		Object   string           `json:"object"`
+		Data     []UsageBucket[T] `json:"data"`
+		HasMore  bool             `json:"has_more"`
+		NextPage string           `json:"next_page"`
+	}
+
+	CompletionsUsageResult struct {
+		Object            string `json:"object"`
+		InputTokens       int    `json:"input_tokens"`
+		OutputTokens      int    `json:"output_tokens"`
+		InputCachedTokens int    `json:"input_cached_tokens"`
+		InputAudioTokens  int    `json:"input_audio_tokens"`
+		OutputAudioTokens int    `json:"output_audio_tokens"`
+		NumModelRequests  int    `json:"num_model_requests"`
+		ProjectId         string `json:"project_id,omitempty"`
+		UserId            string `json:"user_id,omitempty"`
+		ApiKeyId          string `json:"api_key_id,omitempty"`
+		Model             string `json:"model,omitempty"`
+		Batch             *bool  `json:"batch,omitempty"`
+	}
+
+	CostAmount struct {
+		Value    float64 `json:"value"`
+		Currency string  `json:"currency"`
+	}
+
+	CostsResult struct {
+		Object    string     `json:"object"`
+		Amount    CostAmount `json:"amount"`
+		LineItem  string     `json:"line_item,omitempty"`
+		ProjectId string     `json:"project_id,omitempty"`
+	}
+)
+
+// GetCompletionsUsage returns one page of time-bucketed completion usage.
+// Pass the returned NextPage as params.Page to fetch the following page.
+func (o *OpenAI) GetCompletionsUsage(ctx context.Context, params *UsageParams) (*UsagePage[CompletionsUsageResult], error) {
+	var page UsagePage[CompletionsUsageResult]
+	if err := o.get(ctx, completionsUsageEndpoint, params.values(), &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetCosts returns one page of time-bucketed organization costs. Pass the
+// returned NextPage as params.Page to fetch the following page.
+func (o *OpenAI) GetCosts(ctx context.Context, params *UsageParams) (*UsagePage[CostsResult], error) {
+	var page UsagePage[CostsResult]
+	if err := o.get(ctx, costsEndpoint, params.values(), &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (p *UsageParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if !p.StartTime.IsZero() {
+		values.Set("start_time", strconv.FormatInt(p.StartTime.Unix(), 10))
+	}
+	if !p.EndTime.IsZero() {
+		values.Set("end_time", strconv.FormatInt(p.EndTime.Unix(), 10))
+	}
+	if p.BucketWidth != "" {
+		values.Set("bucket_width", p.BucketWidth)
+	}
+	for _, id := range p.ProjectIds {
+		values.Add("project_ids", id)
+	}
+	for _, id := range p.UserIds {
+		values.Add("user_ids", id)
+	}
+	for _, id := range p.ApiKeyIds {
+		values.Add("api_key_ids", id)
+	}
+	for _, model := range p.Models {
+		values.Add("models", model)
+	}
+	if p.Batch != nil {
+		values.Set("batch", strconv.FormatBool(*p.Batch))
+	}
+	for _, group := range p.GroupBy {
+		values.Add("group_by", group)
+	}
+	if p.Limit > 0 {
+		values.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Page != "" {
+		values.Set("page", p.Page)
+	}
+	return values
+}
@@ -0,0 +1,106 @@
+package openaiclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGetCompletionsUsage(t *testing.T) {
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != completionsUsageEndpoint {
+				t.Errorf("expected path %s, got %s", completionsUsageEndpoint, req.URL.Path)
+			}
+			query := req.URL.Query()
+			if query.Get("start_time") != "1730419200" {
+				t.Errorf("expected start_time '1730419200', got '%s'", query.Get("start_time"))
+			}
+			if got := query["models"]; len(got) != 2 {
+				t.Errorf("expected 2 models filters, got %v", got)
+			}
+			if query.Get("page") != "page_1" {
+				t.Errorf("expected page 'page_1', got '%s'", query.Get("page"))
+			}
+			return fakeResponse(200, `{
+				"object": "page",
+				"data": [{
+					"object": "bucket",
+					"start_time": 1730419200,
+					"end_time": 1730505600,
+					"results": [{
+						"object": "organization.usage.completions.result",
+						"input_tokens": 1000,
+						"output_tokens": 500,
+						"input_cached_tokens": 800,
+						"num_model_requests": 5,
+						"model": "gpt-4o-mini"
+					}]
+				}],
+				"has_more": true,
+				"next_page": "page_2"
+			}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	page, err := client.GetCompletionsUsage(context.Background(), &UsageParams{
+		StartTime:   time.Unix(1730419200, 0),
+		BucketWidth: BucketWidthDay,
+		Models:      []string{"gpt-4o", "gpt-4o-mini"},
+		GroupBy:     []string{"model"},
+		Page:        "page_1",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !page.HasMore || page.NextPage != "page_2" {
+		t.Errorf("expected next page 'page_2', got has_more=%v next_page=%q", page.HasMore, page.NextPage)
+	}
+	if len(page.Data) != 1 || len(page.Data[0].Results) != 1 {
+		t.Fatalf("expected 1 bucket with 1 result, got %+v", page.Data)
+	}
+	result := page.Data[0].Results[0]
+	if result.InputTokens != 1000 || result.InputCachedTokens != 800 || result.Model != "gpt-4o-mini" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestGetCosts(t *testing.T) {
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != costsEndpoint {
+				t.Errorf("expected path %s, got %s", costsEndpoint, req.URL.Path)
+			}
+			return fakeResponse(200, `{
+				"object": "page",
+				"data": [{
+					"object": "bucket",
+					"start_time": 1730419200,
+					"end_time": 1730505600,
+					"results": [{
+						"object": "organization.costs.result",
+						"amount": {"value": 0.06, "currency": "usd"},
+						"project_id": "proj_abc"
+					}]
+				}],
+				"has_more": false
+			}`), nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+
+	page, err := client.GetCosts(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	amount := page.Data[0].Results[0].Amount
+	if amount.Value != 0.06 || amount.Currency != "usd" {
+		t.Errorf("unexpected amount %+v", amount)
+	}
+}
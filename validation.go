@@ -0,0 +1,124 @@
+package openaiclient
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	maxToolNameLength = 64
+	maxStopSequences  = 4
+)
+
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ValidationError lists every problem found in a payload by Validate.
+type ValidationError struct {
+	Violations []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid payload: %s", strings.Join(e.Violations, "; "))
+}
+
+// Validate checks the payload locally for mistakes the API would reject,
+// returning a *ValidationError with all violations found, or nil.
+func (c *CompletionRequestPayload) Validate() error {
+	var violations []string
+	addViolation := func(format string, args ...any) {
+		violations = append(violations, fmt.Sprintf(format, args...))
+	}
+
+	if len(c.Messages) == 0 {
+		addViolation("messages must not be empty")
+	}
+
+	pendingToolCalls := map[string]bool{}
+	for i, message := range c.Messages {
+		switch message.Role {
+		case MessageRoleAssistant:
+			pendingToolCalls = map[string]bool{}
+			for _, toolCall := range message.ToolCalls {
+				if toolCall.Id == "" {
+					addViolation("messages[%d]: tool call without id", i)
+				}
+				pendingToolCalls[toolCall.Id] = true
+			}
+		case MessageRoleTool:
+			if message.ToolCallId == "" {
+				addViolation("messages[%d]: tool message without tool_call_id", i)
+			} else if !pendingToolCalls[message.ToolCallId] {
+				addViolation("messages[%d]: tool message for %q does not follow an assistant message with a matching tool call", i, message.ToolCallId)
+			}
+		case MessageRoleUser, MessageRoleSystem, MessageRoleDeveloper:
+			pendingToolCalls = map[string]bool{}
+		default:
+			addViolation("messages[%d]: unknown role %q", i, message.Role)
+		}
+	}
+
+	toolNames := map[string]bool{}
+	for i, tool := range c.Tools {
+		if tool.Type != "function" {
+			addViolation("tools[%d]: unsupported type %q", i, tool.Type)
+		}
+		if tool.Function == nil {
+			addViolation("tools[%d]: missing function definition", i)
+			continue
+		}
+
+		name := tool.Function.Name
+		switch {
+		case !toolNamePattern.MatchString(name):
+			addViolation("tools[%d]: name %q must match %s", i, name, toolNamePattern)
+		case len(name) > maxToolNameLength:
+			addViolation("tools[%d]: name %q is longer than %d characters", i, name, maxToolNameLength)
+		case toolNames[name]:
+			addViolation("tools[%d]: duplicate name %q", i, name)
+		}
+		toolNames[name] = true
+	}
+
+	if choice := c.ToolChoice; choice != nil {
+		switch {
+		case choice.Function != "" && choice.Mode != "":
+			addViolation("tool_choice: cannot set both a mode and a function")
+		case choice.Function != "" && !toolNames[choice.Function]:
+			addViolation("tool_choice: function %q is not among the tools", choice.Function)
+		case choice.Mode == ToolChoiceRequired && len(c.Tools) == 0:
+			addViolation("tool_choice: %q requires tools", choice.Mode)
+		case choice.Function == "" && choice.Mode != ToolChoiceAuto && choice.Mode != ToolChoiceNone && choice.Mode != ToolChoiceRequired:
+			addViolation("tool_choice: unknown mode %q", choice.Mode)
+		}
+	}
+
+	checkRange := func(name string, value *float64, low, high float64) {
+		if value != nil && (*value < low || *value > high) {
+			addViolation("%s: %v is not between %v and %v", name, *value, low, high)
+		}
+	}
+	checkRange("temperature", c.Temperature, 0, 2)
+	checkRange("top_p", c.TopP, 0, 1)
+	checkRange("frequency_penalty", c.FrequencyPenalty, -2, 2)
+	checkRange("presence_penalty", c.PresencePenalty, -2, 2)
+	if c.MaxTokens != nil && c.MaxCompletionTokens != nil {
+		addViolation("max_tokens: cannot be set with max_completion_tokens")
+	}
+	checkPositive := func(name string, value *int) {
+		if value != nil && *value < 1 {
+			addViolation("%s: must be at least 1, got %d", name, *value)
+		}
+	}
+	checkPositive("max_tokens", c.MaxTokens)
+	checkPositive("max_completion_tokens", c.MaxCompletionTokens)
+	checkPositive("n", c.N)
+	if len(c.Stop) > maxStopSequences {
+		addViolation("stop: at most %d sequences are allowed, got %d", maxStopSequences, len(c.Stop))
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package openaiclient
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		payload        *CompletionRequestPayload
+		wantViolations []string
+	}{
+		{
+			name: "valid payload",
+			payload: &CompletionRequestPayload{
+				Messages: []Message{
+					{Role: MessageRoleUser, Content: "Hi"},
+					{Role: MessageRoleAssistant, ToolCalls: []ToolCall{{Id: "call_1"}, {Id: "call_2"}}},
+					{Role: MessageRoleTool, ToolCallId: "call_1"},
+					{Role: MessageRoleTool, ToolCallId: "call_2"},
+				},
+				Tools: []ToolDefinition{NewToolDefinition(&FunctionDefinition{Name: "get_weather"})},
+			},
+		},
+		{
+			name:           "empty messages",
+			payload:        &CompletionRequestPayload{},
+			wantViolations: []string{"messages must not be empty"},
+		},
+		{
+			name: "orphaned tool message",
+			payload: &CompletionRequestPayload{
+				Messages: []Message{
+					{Role: MessageRoleAssistant, ToolCalls: []ToolCall{{Id: "call_1"}}},
+					{Role: MessageRoleUser, Content: "Hi"},
+					{Role: MessageRoleTool, ToolCallId: "call_1"},
+				},
+			},
+			wantViolations: []string{"messages[2]: tool message for \"call_1\""},
+		},
+		{
+			name: "multiple violations",
+			payload: &CompletionRequestPayload{
+				Messages: []Message{
+					{Role: "robot", Content: "Hi"},
+					{Role: MessageRoleTool},
+				},
+				Tools: []ToolDefinition{
+					NewToolDefinition(&FunctionDefinition{Name: "get weather"}),
+					NewToolDefinition(&FunctionDefinition{Name: "echo"}),
+					NewToolDefinition(&FunctionDefinition{Name: "echo"}),
+					{Type: "function"},
+				},
+			},
+			wantViolations: []string{
+				"messages[0]: unknown role",
+				"messages[1]: tool message without tool_call_id",
+				"tools[0]: name \"get weather\" must match",
+				"tools[2]: duplicate name",
+				"tools[3]: missing function definition",
+			},
+		},
+		{
+			name: "tool choice of a missing function",
+			payload: &CompletionRequestPayload{
+				Messages:   []Message{{Role: MessageRoleUser, Content: "Hi"}},
+				Tools:      []ToolDefinition{NewToolDefinition(&FunctionDefinition{Name: "echo"})},
+				ToolChoice: NewFunctionToolChoice("get_weather"),
+			},
+			wantViolations: []string{"tool_choice: function \"get_weather\" is not among the tools"},
+		},
+		{
+			name: "required tool choice without tools",
+			payload: &CompletionRequestPayload{
+				Messages:   []Message{{Role: MessageRoleUser, Content: "Hi"}},
+				ToolChoice: NewToolChoice(ToolChoiceRequired),
+			},
+			wantViolations: []string{"tool_choice: \"required\" requires tools"},
+		},
+		{
+			name: "sampling parameters out of range",
+			payload: &CompletionRequestPayload{
+				Messages:            []Message{{Role: MessageRoleUser, Content: "Hi"}},
+				Temperature:         Ptr(2.5),
+				TopP:                Ptr(0.9),
+				PresencePenalty:     Ptr(-3.0),
+				MaxTokens:           Ptr(100),
+				MaxCompletionTokens: Ptr(0),
+				Stop:                []string{"a", "b", "c", "d", "e"},
+			},
+			wantViolations: []string{
+				"temperature: 2.5 is not between 0 and 2",
+				"presence_penalty: -3 is not between -2 and 2",
+				"max_tokens: cannot be set with max_completion_tokens",
+				"max_completion_tokens: must be at least 1",
+				"stop: at most 4 sequences",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.payload.Validate()
+			if len(tt.wantViolations) == 0 {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected *ValidationError, got %T", err)
+			}
+			if len(validationErr.Violations) != len(tt.wantViolations) {
+				t.Fatalf("got violations %q, want %d", validationErr.Violations, len(tt.wantViolations))
+			}
+			for i, want := range tt.wantViolations {
+				if !strings.HasPrefix(validationErr.Violations[i], want) {
+					t.Errorf("got violation %q, want prefix %q", validationErr.Violations[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetCompletion_ValidatePayloads(t *testing.T) {
+	fakeClient := &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			t.Fatal("expected request not to be sent")
+			return nil, nil
+		},
+	}
+
+	client := createClient(t)
+	client.client = fakeClient
+	client.ValidatePayloads = true
+
+	if _, err := client.GetCompletion(&CompletionRequestPayload{Model: "test-model"}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,103 @@
+package openaiclient
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// EmbeddingEncodingBase64 asks the embeddings endpoint for little-endian
+// float32 vectors in base64, about a quarter of the size of the default JSON
+// numbers. The vectors are decoded into EmbeddingObject.Embedding as usual.
+const EmbeddingEncodingBase64 = "base64"
+
+type (
+	// VectorFormat controls how vectors are written as JSON numbers. The zero
+	// value writes them at full float64 precision, like encoding/json.
+	VectorFormat struct {
+		// Float32 rounds the values to float32 first, which is the precision
+		// the embedding models produce.
+		Float32 bool
+		// Precision, when positive, limits the values to that many significant
+		// digits.
+		Precision int
+	}
+
+	// Vector is a vector written to JSON in a VectorFormat, for payloads that
+	// carry many embeddings.
+	Vector struct {
+		Values []float64
+		Format VectorFormat
+	}
+)
+
+// MarshalJSON writes the values as a JSON array in the vector's format.
+func (v Vector) MarshalJSON() ([]byte, error) {
+	return v.Format.AppendJSON(nil, v.Values)
+}
+
+// AppendJSON appends values to dst as a JSON array in the format.
+func (f VectorFormat) AppendJSON(dst []byte, values []float64) ([]byte, error) {
+	bitSize, precision := 64, -1
+	if f.Float32 {
+		bitSize = 32
+	}
+	if f.Precision > 0 {
+		precision = f.Precision
+	}
+
+	dst = append(dst, '[')
+	for i, value := range values {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, fmt.Errorf("unsupported vector value %v at index %d", value, i)
+		}
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = strconv.AppendFloat(dst, value, 'g', precision, bitSize)
+		// Shorten exponents like e-07 to e-7, as encoding/json does.
+		if n := len(dst); n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return append(dst, ']'), nil
+}
+
+// UnmarshalJSON accepts the embedding as an array of numbers or, for
+// EmbeddingEncodingBase64, as base64 encoded float32 values.
+func (e *EmbeddingObject) UnmarshalJSON(data []byte) error {
+	type embeddingObject EmbeddingObject
+	var decoded struct {
+		embeddingObject
+		Embedding json.RawMessage `json:"embedding"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*e = EmbeddingObject(decoded.embeddingObject)
+
+	if len(decoded.Embedding) == 0 || decoded.Embedding[0] != '"' {
+		return json.Unmarshal(decoded.Embedding, &e.Embedding)
+	}
+
+	var encoded string
+	if err := json.Unmarshal(decoded.Embedding, &encoded); err != nil {
+		return err
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("error decoding base64 embedding: %w", err)
+	}
+	if len(raw)%4 != 0 {
+		return fmt.Errorf("base64 embedding of %d bytes is not a float32 array", len(raw))
+	}
+	e.Embedding = make([]float64, len(raw)/4)
+	for i := range e.Embedding {
+		e.Embedding[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(raw[4*i:])))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package openaiclient
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestVector_MarshalJSON(t *testing.T) {
+	values := []float64{0.123456789, -1.5, 2e-7}
+	cases := []struct {
+		format VectorFormat
+		want   string
+	}{
+		{VectorFormat{}, `[0.123456789,-1.5,2e-7]`},
+		{VectorFormat{Float32: true}, `[0.12345679,-1.5,2e-7]`},
+		{VectorFormat{Precision: 3}, `[0.123,-1.5,2e-7]`},
+	}
+	for _, c := range cases {
+		data, err := json.Marshal(map[string]any{"v": Vector{Values: values, Format: c.format}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if want := `{"v":` + c.want + `}`; string(data) != want {
+			t.Errorf("%+v: expected %s, got %s", c.format, want, data)
+		}
+	}
+
+	if _, err := json.Marshal(Vector{Values: []float64{math.NaN()}}); err == nil {
+		t.Error("expected an error for NaN")
+	}
+}
+
+func TestEmbeddingObject_UnmarshalBase64(t *testing.T) {
+	raw := make([]byte, 8)
+	binary.LittleEndian.PutUint32(raw, math.Float32bits(0.5))
+	binary.LittleEndian.PutUint32(raw[4:], math.Float32bits(-2))
+	body := fmt.Sprintf(`{"object":"embedding","index":1,"embedding":%q}`, base64.StdEncoding.EncodeToString(raw))
+
+	var object EmbeddingObject
+	if err := json.Unmarshal([]byte(body), &object); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if object.Index != 1 || len(object.Embedding) != 2 || object.Embedding[0] != 0.5 || object.Embedding[1] != -2 {
+		t.Errorf("unexpected embedding %+v", object)
+	}
+
+	if err := json.Unmarshal([]byte(`{"embedding":[0.25]}`), &object); err != nil || object.Embedding[0] != 0.25 {
+		t.Errorf("expected float embeddings to decode, got %+v, %v", object, err)
+	}
+	if err := json.Unmarshal([]byte(`{"embedding":"AAA="}`), &object); err == nil {
+		t.Error("expected an error for a truncated float32 array")
+	}
+}
+
+// benchmarkVectors is a batch of about 1.5M values: a thousand vectors of
+// text-embedding-3-small's size.
+func benchmarkVectors() [][]float64 {
+	vectors := make([][]float64, 1000)
+	for i := range vectors {
+		vectors[i] = make([]float64, 1536)
+		for j := range vectors[i] {
+			vectors[i][j] = rand.Float64()*2 - 1
+		}
+	}
+	return vectors
+}
+
+func benchmarkVectorFormat(b *testing.B, format *VectorFormat) {
+	vectors := benchmarkVectors()
+	batch := make([]any, len(vectors))
+	for i, vector := range vectors {
+		batch[i] = vector
+		if format != nil {
+			batch[i] = Vector{Values: vector, Format: *format}
+		}
+	}
+
+	b.ResetTimer()
+	for b.Loop() {
+		data, err := json.Marshal(batch)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(int64(len(data)))
+	}
+}
+
+func BenchmarkMarshalVectors_Default(b *testing.B) {
+	benchmarkVectorFormat(b, nil)
+}
+
+func BenchmarkMarshalVectors_Float32(b *testing.B) {
+	benchmarkVectorFormat(b, &VectorFormat{Float32: true})
+}
+
+func BenchmarkMarshalVectors_Precision4(b *testing.B) {
+	benchmarkVectorFormat(b, &VectorFormat{Float32: true, Precision: 4})
+}
@@ -0,0 +1,171 @@
+package openaiclient
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+)
+
+type (
+	// SearchResult is an entry of an Index matching a query. Score is the
+	// cosine similarity of the entry's vector to the query.
+	SearchResult struct {
+		Id       string
+		Score    float64
+		Metadata map[string]string
+	}
+
+	// Index is an in-memory vector index searched by cosine similarity, for
+	// retrieval over embedded documents. Vectors are stored normalized as
+	// float32, the precision of the embedding models. It can be saved to a
+	// file and memory-mapped back with LoadIndex. It is safe for concurrent
+	// use.
+	Index struct {
+		mu         sync.RWMutex
+		dimensions int
+		ids        []string
+		vectors    []float32
+		metadata   []map[string]string
+		release    func() error
+		hnsw       *hnswGraph
+	}
+)
+
+// NewIndex returns an empty index. Its dimensions are set by the first
+// vector added.
+func NewIndex() *Index {
+	return &Index{}
+}
+
+// Len returns the number of entries of the index.
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.ids)
+}
+
+// Add adds an entry. Ids are not required to be unique.
+func (x *Index) Add(id string, vector []float64, metadata map[string]string) error {
+	if len(vector) == 0 {
+		return NewInvalidRequestError("cannot index an empty vector")
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.dimensions == 0 {
+		x.dimensions = len(vector)
+	}
+	if len(vector) != x.dimensions {
+		return NewInvalidRequestError(fmt.Sprintf("vector has %d dimensions, the index %d", len(vector), x.dimensions))
+	}
+
+	x.ids = append(x.ids, id)
+	x.vectors = append(x.vectors, normalized(vector)...)
+	x.metadata = append(x.metadata, metadata)
+	if x.hnsw != nil {
+		x.hnsw.insert(x, len(x.ids)-1)
+	}
+	return nil
+}
+
+// Search returns the topK entries most similar to query that match all the
+// filters, the most similar first. The search is exact unless EnableHNSW was
+// called.
+func (x *Index) Search(query []float64, topK int, filters ...MetadataFilter) ([]SearchResult, error) {
+	if topK < 0 {
+		return nil, NewInvalidRequestError(fmt.Sprintf("topK must not be negative, got %d", topK))
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if len(x.ids) == 0 {
+		return nil, nil
+	}
+	if len(query) != x.dimensions {
+		return nil, NewInvalidRequestError(fmt.Sprintf("query has %d dimensions, the index %d", len(query), x.dimensions))
+	}
+
+	target := normalized(query)
+	accept := func(i int) bool { return matchesAll(x.metadata[i], filters) }
+	if x.hnsw != nil {
+		var results []SearchResult
+		for _, candidate := range x.hnsw.search(x, target, topK, accept) {
+			results = append(results, SearchResult{Id: x.ids[candidate.id], Score: candidate.similarity, Metadata: x.metadata[candidate.id]})
+		}
+		return results, nil
+	}
+
+	results := make([]SearchResult, 0, len(x.ids))
+	for i := range x.ids {
+		if !accept(i) {
+			continue
+		}
+		results = append(results, SearchResult{Id: x.ids[i], Score: dot(target, x.vector(i)), Metadata: x.metadata[i]})
+	}
+	slices.SortStableFunc(results, func(a, b SearchResult) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	return results[:min(topK, len(results))], nil
+}
+
+// Close releases the file mapped by LoadIndex. The index must not be used
+// afterwards.
+func (x *Index) Close() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.release == nil {
+		return nil
+	}
+	err := x.release()
+	x.release, x.ids, x.vectors, x.metadata, x.hnsw = nil, nil, nil, nil, nil
+	return err
+}
+
+func (x *Index) vector(i int) []float32 {
+	return x.vectors[i*x.dimensions : (i+1)*x.dimensions]
+}
+
+// CosineSimilarity returns the cosine similarity of a and b, or 0 when their
+// lengths differ or either is zero.
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var product, normA, normB float64
+	for i := range a {
+		product += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return product / math.Sqrt(normA*normB)
+}
+
+func normalized(vector []float64) []float32 {
+	var norm float64
+	for _, value := range vector {
+		norm += value * value
+	}
+	norm = math.Sqrt(norm)
+
+	result := make([]float32, len(vector))
+	if norm == 0 {
+		return result
+	}
+	for i, value := range vector {
+		result[i] = float32(value / norm)
+	}
+	return result
+}
+
+func dot(a, b []float32) float64 {
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return float64(sum)
+}
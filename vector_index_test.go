@@ -0,0 +1,31 @@
+package openaiclient
+
+import "testing"
+
+func TestIndex_Search(t *testing.T) {
+	index := NewIndex()
+	index.Add("x", []float64{1, 0}, map[string]string{"kind": "axis"})
+	index.Add("y", []float64{0, 2}, nil)
+	index.Add("xy", []float64{1, 1}, nil)
+
+	results, err := index.Search([]float64{2, 0.1}, 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(results) != 2 || results[0].Id != "x" || results[1].Id != "xy" || results[0].Metadata["kind"] != "axis" {
+		t.Errorf("expected x then xy, got %+v", results)
+	}
+	if results[0].Score < 0.99 || results[0].Score > 1 {
+		t.Errorf("expected a cosine similarity close to 1, got %v", results[0].Score)
+	}
+
+	if err := index.Add("z", []float64{1, 2, 3}, nil); err == nil {
+		t.Error("expected an error for mismatched dimensions")
+	}
+	if _, err := index.Search([]float64{1}, 1); err == nil {
+		t.Error("expected an error for a query of the wrong dimensions")
+	}
+	if _, err := index.Search([]float64{1, 0}, -1); GetOpenAIErrorType(err) != ErrTypeInvalidRequest {
+		t.Errorf("expected an error for a negative topK, got %v", err)
+	}
+}
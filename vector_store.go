@@ -0,0 +1,177 @@
+package openaiclient
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"unsafe"
+)
+
+// indexMagic starts the files written by Index.Save. The header is the
+// magic, the dimensions and the entry count, followed by the float32 vectors
+// and then the id and JSON metadata of every entry, each prefixed by its
+// length as a uvarint. All numbers are little-endian.
+const (
+	indexMagic      = "OAIIDX01"
+	indexHeaderSize = len(indexMagic) + 4 + 4 + 8
+)
+
+var errCorruptIndex = errors.New("corrupt index file")
+
+// Save writes the index to path in a compact binary format that LoadIndex
+// maps into memory. The file is replaced atomically.
+func (x *Index) Save(path string) error {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("error creating index file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if err := x.write(bufio.NewWriterSize(file, 1<<20)); err != nil {
+		return fmt.Errorf("error writing index file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error writing index file: %w", err)
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func (x *Index) write(w *bufio.Writer) error {
+	header := make([]byte, indexHeaderSize)
+	copy(header, indexMagic)
+	binary.LittleEndian.PutUint32(header[len(indexMagic):], uint32(x.dimensions))
+	binary.LittleEndian.PutUint64(header[len(indexMagic)+8:], uint64(len(x.ids)))
+	w.Write(header)
+
+	buffer := make([]byte, 4)
+	for _, value := range x.vectors {
+		binary.LittleEndian.PutUint32(buffer, math.Float32bits(value))
+		w.Write(buffer)
+	}
+
+	for i, id := range x.ids {
+		var metadata []byte
+		if len(x.metadata[i]) > 0 {
+			var err error
+			if metadata, err = json.Marshal(x.metadata[i]); err != nil {
+				return err
+			}
+		}
+		w.Write(binary.AppendUvarint(nil, uint64(len(id))))
+		w.WriteString(id)
+		w.Write(binary.AppendUvarint(nil, uint64(len(metadata))))
+		w.Write(metadata)
+	}
+	return w.Flush()
+}
+
+// LoadIndex memory-maps an index written by Save. The vectors are read from
+// the mapping rather than copied, so even large indexes load quickly and
+// are paged in by the OS as they are searched. Close releases the mapping.
+// Entries added afterwards are kept in memory only until the next Save.
+func LoadIndex(path string) (*Index, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening index file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("error opening index file: %w", err)
+	}
+	if info.Size() < int64(indexHeaderSize) {
+		return nil, errCorruptIndex
+	}
+	data, release, err := mapFile(file, int(info.Size()))
+	if err != nil {
+		return nil, fmt.Errorf("error mapping index file: %w", err)
+	}
+
+	index, err := decodeIndex(data)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	index.release = release
+	return index, nil
+}
+
+func decodeIndex(data []byte) (*Index, error) {
+	if string(data[:len(indexMagic)]) != indexMagic {
+		return nil, errCorruptIndex
+	}
+	dimensions := int(binary.LittleEndian.Uint32(data[len(indexMagic):]))
+	count := binary.LittleEndian.Uint64(data[len(indexMagic)+8:])
+
+	// The count is checked against the remaining bytes by division, as the
+	// size of the vectors could overflow for a corrupt count.
+	remaining := uint64(len(data) - indexHeaderSize)
+	if count > remaining || (dimensions > 0 && count > remaining/(4*uint64(dimensions))) {
+		return nil, errCorruptIndex
+	}
+	vectorsSize := uint64(dimensions) * count * 4
+	index := &Index{
+		dimensions: dimensions,
+		vectors:    float32s(data[indexHeaderSize : indexHeaderSize+int(vectorsSize)]),
+		ids:        make([]string, 0, count),
+		metadata:   make([]map[string]string, 0, count),
+	}
+
+	rest := data[indexHeaderSize+int(vectorsSize):]
+	next := func() ([]byte, bool) {
+		size, n := binary.Uvarint(rest)
+		if n <= 0 || size > uint64(len(rest)-n) {
+			return nil, false
+		}
+		field := rest[n : n+int(size)]
+		rest = rest[n+int(size):]
+		return field, true
+	}
+	for range count {
+		id, ok := next()
+		if !ok {
+			return nil, errCorruptIndex
+		}
+		encoded, ok := next()
+		if !ok {
+			return nil, errCorruptIndex
+		}
+
+		var metadata map[string]string
+		if len(encoded) > 0 {
+			if err := json.Unmarshal(encoded, &metadata); err != nil {
+				return nil, fmt.Errorf("%w: %w", errCorruptIndex, err)
+			}
+		}
+		index.ids = append(index.ids, string(id))
+		index.metadata = append(index.metadata, metadata)
+	}
+	return index, nil
+}
+
+// float32s views little-endian float32 data as a slice without copying it
+// when the host is little-endian, and decodes it otherwise.
+func float32s(data []byte) []float32 {
+	if len(data) == 0 {
+		return nil
+	}
+	if binary.NativeEndian.Uint16([]byte{1, 0}) == 1 && uintptr(unsafe.Pointer(&data[0]))%4 == 0 {
+		return unsafe.Slice((*float32)(unsafe.Pointer(&data[0])), len(data)/4)
+	}
+
+	values := make([]float32, len(data)/4)
+	for i := range values {
+		values[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return values
+}
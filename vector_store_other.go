@@ -0,0 +1,17 @@
+//go:build !unix
+
+package openaiclient
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile reads the file into memory on platforms without mmap support.
+func mapFile(file *os.File, size int) ([]byte, func() error, error) {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(file, data); err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
@@ -0,0 +1,52 @@
+package openaiclient
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIndex_SaveAndLoad(t *testing.T) {
+	index := NewIndex()
+	index.Add("first", []float64{0.1, 0.2, 0.3}, map[string]string{"source": "a.md"})
+	index.Add("second", []float64{-0.3, 0.2, 0.1}, nil)
+
+	path := filepath.Join(t.TempDir(), "index.bin")
+	if err := index.Save(path); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	loaded, err := LoadIndex(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer loaded.Close()
+
+	if loaded.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", loaded.Len())
+	}
+	want, _ := index.Search([]float64{0.1, 0.2, 0.3}, 2)
+	got, _ := loaded.Search([]float64{0.1, 0.2, 0.3}, 2)
+	for i := range want {
+		if got[i].Id != want[i].Id || got[i].Score != want[i].Score || got[i].Metadata["source"] != want[i].Metadata["source"] {
+			t.Errorf("result %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+
+	if err := loaded.Add("third", []float64{1, 0, 0}, nil); err != nil || loaded.Len() != 3 {
+		t.Errorf("expected the loaded index to accept entries, got %v", err)
+	}
+}
+
+func TestLoadIndex_Corrupt(t *testing.T) {
+	for name, data := range map[string]string{
+		"truncated":       "OAIIDX01\x02\x00\x00\x00\x00\x00\x00\x00\xff\x00\x00\x00\x00\x00\x00\x00",
+		"huge dimensions": "OAIIDX01\xff\xff\xff\xff\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00",
+	} {
+		path := filepath.Join(t.TempDir(), "index.bin")
+		os.WriteFile(path, []byte(data), 0o600)
+		if _, err := LoadIndex(path); err == nil {
+			t.Errorf("expected an error for a %s index", name)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package openaiclient
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCodeModelNotAllowed is the code of the errors answered to virtual keys
+// requesting a model outside their allowlist.
+const ErrCodeModelNotAllowed = "model_not_allowed"
+
+const virtualKeyPrefix = "sk-virtual-"
+
+// allowedModelsKey carries the model allowlist of a virtual key to the
+// completions made for it.
+type allowedModelsKey struct{}
+
+type (
+	// VirtualKey configures a key issued by a gateway, see VirtualKeys.
+	VirtualKey struct {
+		// Name identifies the holder of the key, e.g. a team.
+		Name string
+		// APIKey is the upstream key the requests are sent with. When empty,
+		// the gateway client's key is used.
+		APIKey string
+		// Models is the allowlist of models the key may request. When empty,
+		// every model is allowed. It also applies to the models picked by the
+		// client's Router, and fallback models outside of it are skipped.
+		Models []string
+		// RequestsPerMinute caps the requests of the key. Zero means no cap.
+		RequestsPerMinute int
+		// TokenLimit caps the total tokens of the key's completions. Zero
+		// means no cap.
+		TokenLimit int
+	}
+
+	// VirtualKeys are the keys ProxyHandler requires when set on the client,
+	// so several teams can share the upstream credentials without holding
+	// them. Every key gets its own client, which tracks its own usage and
+	// budget the way ClientPool does for tenants: the Usage of the gateway
+	// client does not see the requests of virtual keys. It is safe for
+	// concurrent use.
+	VirtualKeys struct {
+		mu sync.Mutex
+		// keys are indexed by the SHA-256 of their secret, which is never
+		// stored.
+		keys map[[sha256.Size]byte]*virtualKey
+	}
+
+	virtualKey struct {
+		config VirtualKey
+		usage  *UsageTracker
+		client *OpenAI
+
+		windowStart time.Time
+		requests    int
+	}
+)
+
+// NewVirtualKeys returns a set of virtual keys without any key issued.
+func NewVirtualKeys() *VirtualKeys {
+	return &VirtualKeys{keys: map[[sha256.Size]byte]*virtualKey{}}
+}
+
+// Issue registers a key and returns its secret, which callers send as their
+// bearer token.
+func (v *VirtualKeys) Issue(key VirtualKey) string {
+	secret := virtualKeyPrefix + rand.Text()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.keys[sha256.Sum256([]byte(secret))] = &virtualKey{
+		config: key,
+		usage:  &UsageTracker{TokenLimit: key.TokenLimit},
+	}
+	return secret
+}
+
+// Revoke removes the key of the secret. Requests already authorized are
+// completed.
+func (v *VirtualKeys) Revoke(secret string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.keys, sha256.Sum256([]byte(secret)))
+}
+
+// Usage returns the usage tracker of the key of the secret, or nil for
+// unknown secrets.
+func (v *VirtualKeys) Usage(secret string) *UsageTracker {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if key, ok := v.keys[sha256.Sum256([]byte(secret))]; ok {
+		return key.usage
+	}
+	return nil
+}
+
+// authorize returns the client to serve a request for model with, derived
+// from base, and the configuration of the request's key after checking the
+// key, its allowlist and its rate limit. Requests without a model are checked
+// when their model is picked, see modelAllowed.
+func (v *VirtualKeys) authorize(base *OpenAI, r *http.Request, model string) (*OpenAI, VirtualKey, error) {
+	secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return nil, VirtualKey{}, &OpenAIError{Type: ErrTypeAuthentication, Message: "missing bearer key", StatusCode: http.StatusUnauthorized}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	key, ok := v.keys[sha256.Sum256([]byte(secret))]
+	if !ok {
+		return nil, VirtualKey{}, &OpenAIError{Type: ErrTypeAuthentication, Message: "invalid key", StatusCode: http.StatusUnauthorized}
+	}
+
+	if key.client == nil {
+		client := *base
+		client.pacer = &pacer{}
+		client.Usage = key.usage
+		if key.config.APIKey != "" {
+			client.key = key.config.APIKey
+		}
+		key.client = &client
+	}
+
+	if model != "" && len(key.config.Models) > 0 && !slices.Contains(key.config.Models, model) {
+		return nil, VirtualKey{}, modelNotAllowedError(model)
+	}
+
+	if limit := key.config.RequestsPerMinute; limit > 0 {
+		if now := time.Now(); now.Sub(key.windowStart) >= time.Minute {
+			key.windowStart, key.requests = now, 0
+		}
+		if key.requests >= limit {
+			return nil, VirtualKey{}, &OpenAIError{
+				Type:       ErrTypeRateLimit,
+				Message:    fmt.Sprintf("the key %s is limited to %d requests per minute", key.config.Name, limit),
+				StatusCode: http.StatusTooManyRequests,
+			}
+		}
+		key.requests++
+	}
+	return key.client, key.config, nil
+}
+
+// withAllowedModels restricts the completions of ctx to models, unless it is
+// empty.
+func withAllowedModels(ctx context.Context, models []string) context.Context {
+	if len(models) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, allowedModelsKey{}, models)
+}
+
+// modelAllowed reports whether the allowlist of ctx, if any, has model.
+func modelAllowed(ctx context.Context, model string) bool {
+	models, ok := ctx.Value(allowedModelsKey{}).([]string)
+	return !ok || slices.Contains(models, model)
+}
+
+func modelNotAllowedError(model string) *OpenAIError {
+	return &OpenAIError{
+		Type:       ErrTypeInvalidRequest,
+		Message:    fmt.Sprintf("the key may not use model %s", model),
+		Code:       ErrCodeModelNotAllowed,
+		StatusCode: http.StatusForbidden,
+	}
+}
@@ -0,0 +1,102 @@
+package openaiclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func proxyWithKey(client *OpenAI, secret, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	if secret != "" {
+		request.Header.Set("Authorization", "Bearer "+secret)
+	}
+	client.ProxyHandler(ProxyOptions{}).ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestVirtualKeys(t *testing.T) {
+	var authorizations []string
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			authorizations = append(authorizations, req.Header.Get("Authorization"))
+			return fakeResponse(http.StatusOK, `{"choices":[{"message":{"role":"assistant","content":"Hi"}}],"usage":{"total_tokens":7}}`), nil
+		},
+	}
+	client.VirtualKeys = NewVirtualKeys()
+	search := client.VirtualKeys.Issue(VirtualKey{Name: "search", APIKey: "sk-search", Models: []string{"gpt-4o-mini"}, RequestsPerMinute: 2})
+	support := client.VirtualKeys.Issue(VirtualKey{Name: "support"})
+
+	hi := `{"model":"gpt-4o-mini","messages":[{"role":"user","content":"Hi"}]}`
+	for i := range 2 {
+		if recorder := proxyWithKey(client, search, hi); recorder.Code != http.StatusOK {
+			t.Fatalf("request %d: expected status 200, got %d: %s", i, recorder.Code, recorder.Body)
+		}
+	}
+	if recorder := proxyWithKey(client, search, hi); recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the rate limit to apply, got %d", recorder.Code)
+	}
+	if recorder := proxyWithKey(client, search, `{"model":"gpt-4o","messages":[{"role":"user","content":"Hi"}]}`); recorder.Code != http.StatusForbidden {
+		t.Errorf("expected the model allowlist to apply, got %d", recorder.Code)
+	}
+	if recorder := proxyWithKey(client, support, hi); recorder.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d: %s", recorder.Code, recorder.Body)
+	}
+
+	if authorizations[0] != "Bearer sk-search" || authorizations[2] != "Bearer "+client.key {
+		t.Errorf("expected the upstream keys of the virtual keys, got %v", authorizations)
+	}
+	if total := client.VirtualKeys.Usage(search).Total().TotalTokens; total != 14 {
+		t.Errorf("expected the usage of the key's two requests, got %d", total)
+	}
+
+	client.VirtualKeys.Revoke(support)
+	for _, secret := range []string{support, "", "sk-unknown"} {
+		if recorder := proxyWithKey(client, secret, hi); recorder.Code != http.StatusUnauthorized {
+			t.Errorf("expected %q to be rejected, got %d", secret, recorder.Code)
+		}
+	}
+	if client.VirtualKeys.Usage(support) != nil {
+		t.Error("expected no usage for a revoked key")
+	}
+}
+
+func TestVirtualKeys_AllowlistAppliesToPickedModels(t *testing.T) {
+	var models []string
+	client := createClient(t)
+	client.client = &FakeClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			var payload CompletionRequestPayload
+			json.NewDecoder(req.Body).Decode(&payload)
+			models = append(models, payload.Model)
+			if payload.Model == "model-a" {
+				return fakeResponse(http.StatusNotFound, `{"error":{"type":"invalid_request_error","code":"model_not_found","message":"no such model"}}`), nil
+			}
+			return fakeResponse(http.StatusOK, completionBody), nil
+		},
+	}
+	client.DefaultModel = "model-b"
+	client.FallbackModels = []string{"model-a", "model-b", "model-c"}
+	client.VirtualKeys = NewVirtualKeys()
+	secret := client.VirtualKeys.Issue(VirtualKey{Name: "search", Models: []string{"model-a", "model-c"}})
+
+	if recorder := proxyWithKey(client, secret, `{"model":"model-a","messages":[{"role":"user","content":"Hi"}]}`); recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body)
+	}
+	if !slices.Equal(models, []string{"model-a", "model-c"}) {
+		t.Errorf("expected the fallback to skip models outside the allowlist, got %v", models)
+	}
+
+	models = nil
+	if recorder := proxyWithKey(client, secret, `{"messages":[{"role":"user","content":"Hi"}]}`); recorder.Code != http.StatusForbidden {
+		t.Errorf("expected the default model to be rejected, got %d", recorder.Code)
+	}
+	if len(models) != 0 {
+		t.Errorf("expected no upstream request, got %v", models)
+	}
+}
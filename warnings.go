@@ -0,0 +1,53 @@
+package openaiclient
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WarningKind identifies a non-fatal condition reported to OpenAI.OnWarning.
+type WarningKind string
+
+const (
+	// WarningUnknownTool is reported when the model calls a tool the payload
+	// does not define. The call is answered with a tool error.
+	WarningUnknownTool WarningKind = "unknown_tool"
+	// WarningDroppedChoices is reported when a response has more than one
+	// choice. Only the first is used.
+	WarningDroppedChoices WarningKind = "dropped_choices"
+	// WarningFingerprintChanged is reported when the system fingerprint of
+	// the responses changes within a run, i.e. the backend configuration
+	// changed between its iterations.
+	WarningFingerprintChanged WarningKind = "fingerprint_changed"
+	// WarningMessagesTrimmed is reported when TrimPolicy shortened the
+	// history after the context length was exceeded.
+	WarningMessagesTrimmed WarningKind = "messages_trimmed"
+	// WarningModelFallback is reported when a request moves down the
+	// FallbackModels chain.
+	WarningModelFallback WarningKind = "model_fallback"
+	// WarningHedgeFailed is reported when the hedged request could not be
+	// created. The first request is still awaited.
+	WarningHedgeFailed WarningKind = "hedge_failed"
+	// WarningShadowFailed is reported when a shadow request fails.
+	WarningShadowFailed WarningKind = "shadow_failed"
+	// WarningAuditFailed is reported when the Audit sink fails to write a
+	// record. The request is not failed.
+	WarningAuditFailed WarningKind = "audit_failed"
+)
+
+// Warning is a non-fatal condition met while serving a request.
+type Warning struct {
+	Kind          WarningKind
+	Message       string
+	CorrelationId string
+	// Attrs are the details of the condition, as they are logged.
+	Attrs []slog.Attr
+}
+
+// warn logs the condition as a warning and reports it to OnWarning.
+func (o *OpenAI) warn(ctx context.Context, kind WarningKind, message string, attrs ...slog.Attr) {
+	slog.LogAttrs(ctx, slog.LevelWarn, message, append(attrs, correlationAttr(ctx))...)
+	if o.OnWarning != nil {
+		o.OnWarning(Warning{Kind: kind, Message: message, CorrelationId: CorrelationId(ctx), Attrs: attrs})
+	}
+}
@@ -0,0 +1,49 @@
+package openaiclient
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestOnWarning(t *testing.T) {
+	client := createClient(t)
+	client.client = &SequentialFakeClient{
+		Responses: []*http.Response{
+			fakeResponse(http.StatusOK, `{"system_fingerprint":"fp_1","choices":[{"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"missing","arguments":"{}"}}]}}]}`),
+			fakeResponse(http.StatusOK, `{"system_fingerprint":"fp_2","choices":[{"message":{"role":"assistant","content":"a"}},{"message":{"role":"assistant","content":"b"}}]}`),
+		},
+	}
+	var warnings []Warning
+	client.OnWarning = func(warning Warning) {
+		warnings = append(warnings, warning)
+	}
+
+	ctx := WithCorrelationId(context.Background(), "run-1")
+	message, err := client.GetCompletionContext(ctx, &CompletionRequestPayload{
+		Model:    "gpt-4o-mini",
+		Messages: []Message{{Role: MessageRoleUser, Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if message.Content != "a" {
+		t.Errorf("expected the first choice, got %q", message.Content)
+	}
+
+	kinds := make([]WarningKind, 0, len(warnings))
+	for _, warning := range warnings {
+		kinds = append(kinds, warning.Kind)
+		if warning.CorrelationId != "run-1" {
+			t.Errorf("expected the correlation id on %s, got %q", warning.Kind, warning.CorrelationId)
+		}
+	}
+	expected := []WarningKind{WarningUnknownTool, WarningDroppedChoices, WarningFingerprintChanged}
+	if !slices.Equal(kinds, expected) {
+		t.Fatalf("expected warnings %v, got %v", expected, kinds)
+	}
+	if attr := warnings[0].Attrs[0]; attr.Key != "toolName" || attr.Value.String() != "missing" {
+		t.Errorf("expected the tool name in the attributes, got %v", warnings[0].Attrs)
+	}
+}
@@ -0,0 +1,196 @@
+// Package webhooks verifies and parses the webhook events OpenAI sends for
+// batches, fine-tuning jobs and background responses.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderId        = "webhook-id"
+	HeaderTimestamp = "webhook-timestamp"
+	HeaderSignature = "webhook-signature"
+
+	secretPrefix     = "whsec_"
+	signatureVersion = "v1"
+	maxBodySize      = 1 << 20
+
+	DefaultTolerance = 5 * time.Minute
+)
+
+var (
+	ErrMissingHeaders   = errors.New("missing webhook headers")
+	ErrInvalidTimestamp = errors.New("invalid webhook timestamp")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+type EventType string
+
+const (
+	EventBatchCompleted         EventType = "batch.completed"
+	EventBatchCancelled         EventType = "batch.cancelled"
+	EventBatchExpired           EventType = "batch.expired"
+	EventBatchFailed            EventType = "batch.failed"
+	EventFineTuningJobSucceeded EventType = "fine_tuning.job.succeeded"
+	EventFineTuningJobFailed    EventType = "fine_tuning.job.failed"
+	EventFineTuningJobCancelled EventType = "fine_tuning.job.cancelled"
+	EventResponseCompleted      EventType = "response.completed"
+	EventResponseCancelled      EventType = "response.cancelled"
+	EventResponseFailed         EventType = "response.failed"
+	EventResponseIncomplete     EventType = "response.incomplete"
+	EventEvalRunSucceeded       EventType = "eval.run.succeeded"
+	EventEvalRunFailed          EventType = "eval.run.failed"
+	EventEvalRunCanceled        EventType = "eval.run.canceled"
+	EventRealtimeCallIncoming   EventType = "realtime.call.incoming"
+)
+
+type (
+	EventData struct {
+		Id string `json:"id"`
+	}
+
+	Event struct {
+		Id        string    `json:"id"`
+		Object    string    `json:"object"`
+		CreatedAt int64     `json:"created_at"`
+		Type      EventType `json:"type"`
+		Data      EventData `json:"data"`
+	}
+)
+
+// IsBatch reports whether the event refers to a batch; Data.Id is the batch id.
+func (e *Event) IsBatch() bool {
+	return strings.HasPrefix(string(e.Type), "batch.")
+}
+
+// IsFineTuningJob reports whether the event refers to a fine-tuning job; Data.Id
+// is the job id.
+func (e *Event) IsFineTuningJob() bool {
+	return strings.HasPrefix(string(e.Type), "fine_tuning.job.")
+}
+
+// IsResponse reports whether the event refers to a background response; Data.Id
+// is the response id.
+func (e *Event) IsResponse() bool {
+	return strings.HasPrefix(string(e.Type), "response.")
+}
+
+type Verifier struct {
+	secret    []byte
+	Tolerance time.Duration
+	now       func() time.Time
+}
+
+// NewVerifier creates a Verifier for the signing secret shown in the OpenAI
+// dashboard, with or without its "whsec_" prefix.
+func NewVerifier(secret string) (*Verifier, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, secretPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding webhook secret: %w", err)
+	}
+	return &Verifier{
+		secret:    key,
+		Tolerance: DefaultTolerance,
+		now:       time.Now,
+	}, nil
+}
+
+// Verify checks the signature and timestamp headers against the raw request
+// body.
+func (v *Verifier) Verify(headers http.Header, body []byte) error {
+	id := headers.Get(HeaderId)
+	timestamp := headers.Get(HeaderTimestamp)
+	signatures := headers.Get(HeaderSignature)
+	if id == "" || timestamp == "" || signatures == "" {
+		return ErrMissingHeaders
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	if v.Tolerance > 0 {
+		delta := v.now().Sub(time.Unix(seconds, 0))
+		if delta > v.Tolerance || delta < -v.Tolerance {
+			return ErrInvalidTimestamp
+		}
+	}
+
+	expected := v.sign(id, timestamp, body)
+	for _, signature := range strings.Fields(signatures) {
+		version, value, found := strings.Cut(signature, ",")
+		if !found || version != signatureVersion {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		if hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// Unwrap verifies the request and parses its body into an Event.
+func (v *Verifier) Unwrap(headers http.Header, body []byte) (*Event, error) {
+	if err := v.Verify(headers, body); err != nil {
+		return nil, err
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("error unmarshaling webhook event: %w", err)
+	}
+	return &event, nil
+}
+
+// Handler adapts fn into an http.Handler that only invokes it for verified
+// events. Unverified requests are answered with 400 and handler errors with
+// 500 so OpenAI retries the delivery.
+func (v *Verifier) Handler(fn func(r *http.Request, event *Event) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+		if err != nil {
+			http.Error(w, "error reading body", http.StatusBadRequest)
+			return
+		}
+
+		event, err := v.Unwrap(r.Header, body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := fn(r, event); err != nil {
+			http.Error(w, "error handling event", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func (v *Verifier) sign(id, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write([]byte(id + "." + timestamp + "."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,128 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testKey    = []byte("super-secret-signing-key")
+	testSecret = secretPrefix + base64.StdEncoding.EncodeToString(testKey)
+	testBody   = `{"id":"evt_123","object":"event","created_at":1719168000,"type":"batch.completed","data":{"id":"batch_abc"}}`
+)
+
+func signedHeaders(t *testing.T, body string, at time.Time) http.Header {
+	t.Helper()
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, testKey)
+	mac.Write([]byte("msg_1." + timestamp + "." + body))
+	headers := http.Header{}
+	headers.Set(HeaderId, "msg_1")
+	headers.Set(HeaderTimestamp, timestamp)
+	headers.Set(HeaderSignature, "v1,invalid v1,"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	return headers
+}
+
+func createVerifier(t *testing.T) *Verifier {
+	t.Helper()
+	verifier, err := NewVerifier(testSecret)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return verifier
+}
+
+func TestUnwrap_Success(t *testing.T) {
+	verifier := createVerifier(t)
+
+	event, err := verifier.Unwrap(signedHeaders(t, testBody, time.Now()), []byte(testBody))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if event.Type != EventBatchCompleted {
+		t.Errorf("expected type %q, got %q", EventBatchCompleted, event.Type)
+	}
+	if !event.IsBatch() {
+		t.Errorf("expected batch event")
+	}
+	if event.Data.Id != "batch_abc" {
+		t.Errorf("expected data id 'batch_abc', got '%s'", event.Data.Id)
+	}
+}
+
+func TestVerify_Errors(t *testing.T) {
+	verifier := createVerifier(t)
+
+	tests := []struct {
+		name    string
+		headers http.Header
+		body    string
+		wantErr error
+	}{
+		{
+			name:    "missing headers",
+			headers: http.Header{},
+			body:    testBody,
+			wantErr: ErrMissingHeaders,
+		},
+		{
+			name:    "tampered body",
+			headers: signedHeaders(t, testBody, time.Now()),
+			body:    strings.Replace(testBody, "batch_abc", "batch_xyz", 1),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "stale timestamp",
+			headers: signedHeaders(t, testBody, time.Now().Add(-time.Hour)),
+			body:    testBody,
+			wantErr: ErrInvalidTimestamp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifier.Verify(tt.headers, []byte(tt.body))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	verifier := createVerifier(t)
+
+	var received *Event
+	handler := verifier.Handler(func(r *http.Request, event *Event) error {
+		received = event
+		return nil
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(testBody))
+	request.Header = signedHeaders(t, testBody, time.Now())
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if received == nil || received.Id != "evt_123" {
+		t.Errorf("expected event 'evt_123' to be handled, got %+v", received)
+	}
+
+	request = httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(testBody))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for unsigned request, got %d", recorder.Code)
+	}
+}